@@ -0,0 +1,529 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/iljarotar/hybrid-scaler/api/v1alpha2"
+)
+
+// conversionDataAnnotation holds the hub spec of scalers whose spec cannot be represented in this version,
+// so that converting them back to the hub does not lose any fields
+const conversionDataAnnotation = "scaling.autoscaling.custom/conversion-data"
+
+// ConvertTo converts this HybridScaler to the hub version
+func (src *HybridScaler) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1alpha2.HybridScaler)
+	if !ok {
+		return fmt.Errorf("unable to convert to %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = convertSpecToHub(src.Spec.DeepCopy())
+	dst.Status = convertStatusToHub(src.Status.DeepCopy())
+
+	data, ok := dst.Annotations[conversionDataAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(dst.Annotations, conversionDataAnnotation)
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+
+	var restored v1alpha2.HybridScalerSpec
+	if err := json.Unmarshal([]byte(data), &restored); err != nil {
+		return fmt.Errorf("cannot decode conversion data, %w", err)
+	}
+
+	// the stored spec is outdated if the spec has been changed through this version since
+	if equality.Semantic.DeepEqual(convertSpecFromHub(restored.DeepCopy()), src.Spec) {
+		dst.Spec = restored
+	}
+
+	return nil
+}
+
+// ConvertFrom converts the hub version to this HybridScaler
+func (dst *HybridScaler) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1alpha2.HybridScaler)
+	if !ok {
+		return fmt.Errorf("unable to convert from %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = convertSpecFromHub(src.Spec.DeepCopy())
+	dst.Status = convertStatusFromHub(src.Status.DeepCopy())
+
+	if equality.Semantic.DeepEqual(convertSpecToHub(dst.Spec.DeepCopy()), src.Spec) {
+		return nil
+	}
+
+	data, err := json.Marshal(src.Spec)
+	if err != nil {
+		return fmt.Errorf("cannot encode conversion data, %w", err)
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = make(map[string]string)
+	}
+	dst.Annotations[conversionDataAnnotation] = string(data)
+
+	return nil
+}
+
+func convertSpecToHub(in *HybridScalerSpec) v1alpha2.HybridScalerSpec {
+	out := v1alpha2.HybridScalerSpec{
+		ScaleTargetRef: in.ScaleTargetRef,
+		MinReplicas:    in.MinReplicas,
+		MaxReplicas:    in.MaxReplicas,
+		ResourcePolicy: convertResourcePolicyToHub(in.ResourcePolicy),
+		Strategy:       convertStrategyToHub(in.LearningType, in.QLearningParams, in.RuleBasedParams),
+		Interval:       secondsToDuration(in.Interval),
+		WarmupPeriod:   secondsToDuration(in.WarmupPeriod),
+		UpdateMode:     v1alpha2.UpdateMode(in.UpdateMode),
+		Coexistence:    v1alpha2.CoexistenceMode(in.Coexistence),
+		PressurePolicy: v1alpha2.PressurePolicy(in.PressurePolicy),
+		PendingTimeout: secondsToDuration(in.PendingTimeout),
+		PodFilter: v1alpha2.PodFilter{
+			CurrentRevisionOnly: in.PodFilter.CurrentRevisionOnly,
+			MinReadyDuration:    metav1.Duration{Duration: time.Duration(in.PodFilter.MinReadySeconds) * time.Second},
+			IncludeTerminating:  in.PodFilter.IncludeTerminating,
+		},
+		LearningGroup: in.LearningGroup,
+		Policy:        in.Policy,
+	}
+
+	if in.ScaleToZero != nil {
+		out.ScaleToZero = &v1alpha2.ScaleToZero{
+			ActivityQuery:      in.ScaleToZero.ActivityQuery,
+			IdlePeriod:         metav1.Duration{Duration: time.Duration(in.ScaleToZero.IdlePeriod) * time.Second},
+			ActivationReplicas: in.ScaleToZero.ActivationReplicas,
+		}
+	}
+
+	if in.Profiles != nil {
+		out.Profiles = make([]v1alpha2.ScalingProfile, len(in.Profiles))
+		for i, p := range in.Profiles {
+			out.Profiles[i] = v1alpha2.ScalingProfile{
+				Name:        p.Name,
+				Schedule:    p.Schedule,
+				Duration:    metav1.Duration{Duration: time.Duration(p.Duration) * time.Second},
+				TimeZone:    p.TimeZone,
+				MinReplicas: p.MinReplicas,
+				MaxReplicas: p.MaxReplicas,
+				MinAllowed:  p.MinAllowed,
+				MaxAllowed:  p.MaxAllowed,
+			}
+		}
+	}
+
+	if in.Forecast != nil {
+		out.Forecast = &v1alpha2.Forecast{
+			Season:  metav1.Duration{Duration: time.Duration(in.Forecast.Season) * time.Second},
+			Seasons: in.Forecast.Seasons,
+			Step:    secondsToDuration(in.Forecast.Step),
+			Horizon: in.Forecast.Horizon,
+			Alpha:   in.Forecast.Alpha,
+			Beta:    in.Forecast.Beta,
+			Gamma:   in.Forecast.Gamma,
+		}
+	}
+
+	if in.Recommender != nil {
+		out.Recommender = &v1alpha2.RecommenderPolicy{
+			CPUPercentile:    in.Recommender.CPUPercentile,
+			MemoryPercentile: in.Recommender.MemoryPercentile,
+			HalfLife:         secondsToDuration(in.Recommender.HalfLife),
+		}
+	}
+
+	if in.Fallback != nil {
+		out.Fallback = &v1alpha2.FallbackPolicy{
+			MaxConsecutiveErrors:     in.Fallback.MaxConsecutiveErrors,
+			MaxUtilization:           in.Fallback.MaxUtilization,
+			MaxOverutilizedIntervals: in.Fallback.MaxOverutilizedIntervals,
+			RecoveryIntervals:        in.Fallback.RecoveryIntervals,
+		}
+		if in.Fallback.LearningType != "" || !equality.Semantic.DeepEqual(in.Fallback.RuleBasedParams, RuleBasedParams{}) {
+			strategy := convertStrategyToHub(in.Fallback.LearningType, QLearningParams{}, in.Fallback.RuleBasedParams)
+			out.Fallback.Strategy = &strategy
+		}
+	}
+
+	if in.ShadowStrategies != nil {
+		out.ShadowStrategies = make([]v1alpha2.ShadowStrategy, len(in.ShadowStrategies))
+		for i, shadow := range in.ShadowStrategies {
+			out.ShadowStrategies[i] = v1alpha2.ShadowStrategy{
+				Name:     shadow.Name,
+				Strategy: convertStrategyToHub(shadow.LearningType, shadow.QLearningParams, shadow.RuleBasedParams),
+			}
+		}
+	}
+
+	return out
+}
+
+func convertSpecFromHub(in *v1alpha2.HybridScalerSpec) HybridScalerSpec {
+	learningType, qParams, ruleParams := convertStrategyFromHub(in.Strategy)
+
+	out := HybridScalerSpec{
+		ScaleTargetRef:  in.ScaleTargetRef,
+		MinReplicas:     in.MinReplicas,
+		MaxReplicas:     in.MaxReplicas,
+		ResourcePolicy:  convertResourcePolicyFromHub(in.ResourcePolicy),
+		LearningType:    learningType,
+		QLearningParams: qParams,
+		RuleBasedParams: ruleParams,
+		Interval:        durationToSeconds(in.Interval),
+		WarmupPeriod:    durationToSeconds(in.WarmupPeriod),
+		UpdateMode:      UpdateMode(in.UpdateMode),
+		Coexistence:     CoexistenceMode(in.Coexistence),
+		PressurePolicy:  PressurePolicy(in.PressurePolicy),
+		PendingTimeout:  durationToSeconds(in.PendingTimeout),
+		PodFilter: PodFilter{
+			CurrentRevisionOnly: in.PodFilter.CurrentRevisionOnly,
+			MinReadySeconds:     seconds(in.PodFilter.MinReadyDuration),
+			IncludeTerminating:  in.PodFilter.IncludeTerminating,
+		},
+		LearningGroup: in.LearningGroup,
+		Policy:        in.Policy,
+	}
+
+	if in.ScaleToZero != nil {
+		out.ScaleToZero = &ScaleToZero{
+			ActivityQuery:      in.ScaleToZero.ActivityQuery,
+			IdlePeriod:         seconds(in.ScaleToZero.IdlePeriod),
+			ActivationReplicas: in.ScaleToZero.ActivationReplicas,
+		}
+	}
+
+	if in.Profiles != nil {
+		out.Profiles = make([]ScalingProfile, len(in.Profiles))
+		for i, p := range in.Profiles {
+			out.Profiles[i] = ScalingProfile{
+				Name:        p.Name,
+				Schedule:    p.Schedule,
+				Duration:    seconds(p.Duration),
+				TimeZone:    p.TimeZone,
+				MinReplicas: p.MinReplicas,
+				MaxReplicas: p.MaxReplicas,
+				MinAllowed:  p.MinAllowed,
+				MaxAllowed:  p.MaxAllowed,
+			}
+		}
+	}
+
+	if in.Forecast != nil {
+		out.Forecast = &Forecast{
+			Season:  seconds(in.Forecast.Season),
+			Seasons: in.Forecast.Seasons,
+			Step:    durationToSeconds(in.Forecast.Step),
+			Horizon: in.Forecast.Horizon,
+			Alpha:   in.Forecast.Alpha,
+			Beta:    in.Forecast.Beta,
+			Gamma:   in.Forecast.Gamma,
+		}
+	}
+
+	if in.Recommender != nil {
+		out.Recommender = &RecommenderPolicy{
+			CPUPercentile:    in.Recommender.CPUPercentile,
+			MemoryPercentile: in.Recommender.MemoryPercentile,
+			HalfLife:         durationToSeconds(in.Recommender.HalfLife),
+		}
+	}
+
+	if in.Fallback != nil {
+		out.Fallback = &FallbackPolicy{
+			MaxConsecutiveErrors:     in.Fallback.MaxConsecutiveErrors,
+			MaxUtilization:           in.Fallback.MaxUtilization,
+			MaxOverutilizedIntervals: in.Fallback.MaxOverutilizedIntervals,
+			RecoveryIntervals:        in.Fallback.RecoveryIntervals,
+		}
+		if in.Fallback.Strategy != nil {
+			out.Fallback.LearningType, _, out.Fallback.RuleBasedParams = convertStrategyFromHub(*in.Fallback.Strategy)
+		}
+	}
+
+	if in.ShadowStrategies != nil {
+		out.ShadowStrategies = make([]ShadowStrategy, len(in.ShadowStrategies))
+		for i, shadow := range in.ShadowStrategies {
+			learningType, qParams, ruleParams := convertStrategyFromHub(shadow.Strategy)
+			out.ShadowStrategies[i] = ShadowStrategy{
+				Name:            shadow.Name,
+				LearningType:    learningType,
+				QLearningParams: qParams,
+				RuleBasedParams: ruleParams,
+			}
+		}
+	}
+
+	return out
+}
+
+// convertStrategyToHub keeps the parameters of other learning types unless they are empty
+func convertStrategyToHub(learningType LearningType, qParams QLearningParams, ruleParams RuleBasedParams) v1alpha2.Strategy {
+	strategy := v1alpha2.Strategy{Type: v1alpha2.StrategyType(learningType)}
+
+	if learningType == LearningTypeQLearning || !equality.Semantic.DeepEqual(qParams, QLearningParams{}) {
+		qLearning := v1alpha2.QLearningStrategy(qParams)
+		strategy.QLearning = &qLearning
+	}
+
+	if learningType == LearningTypeRuleBased || !equality.Semantic.DeepEqual(ruleParams, RuleBasedParams{}) {
+		ruleBased := v1alpha2.RuleBasedStrategy(ruleParams)
+		strategy.RuleBased = &ruleBased
+	}
+
+	return strategy
+}
+
+func convertStrategyFromHub(strategy v1alpha2.Strategy) (LearningType, QLearningParams, RuleBasedParams) {
+	var qParams QLearningParams
+	if strategy.QLearning != nil {
+		qParams = QLearningParams(*strategy.QLearning)
+	}
+
+	var ruleParams RuleBasedParams
+	if strategy.RuleBased != nil {
+		ruleParams = RuleBasedParams(*strategy.RuleBased)
+	}
+
+	return LearningType(strategy.Type), qParams, ruleParams
+}
+
+// convertResourcePolicyToHub drops the target utilization of resources other than cpu and memory, which is never used
+func convertResourcePolicyToHub(in ResourcePolicy) v1alpha2.ResourcePolicy {
+	out := v1alpha2.ResourcePolicy{
+		MinAllowed:                  in.MinAllowed,
+		MaxAllowed:                  in.MaxAllowed,
+		LimitsToRequestsRatioCPU:    in.LimitsToRequestsRatioCPU,
+		LimitsToRequestsRatioMemory: in.LimitsToRequestsRatioMemory,
+		Headroom:                    in.Headroom,
+	}
+
+	if cpu, ok := in.TargetUtilization[corev1.ResourceCPU]; ok {
+		out.TargetUtilization.CPU = &cpu
+	}
+	if memory, ok := in.TargetUtilization[corev1.ResourceMemory]; ok {
+		out.TargetUtilization.Memory = &memory
+	}
+
+	if in.Rounding != nil {
+		out.Rounding = make(map[corev1.ResourceName]v1alpha2.RoundingPolicy, len(in.Rounding))
+		for name, rounding := range in.Rounding {
+			out.Rounding[name] = v1alpha2.RoundingPolicy{
+				Granularity: rounding.Granularity,
+				Direction:   v1alpha2.RoundingDirection(rounding.Direction),
+			}
+		}
+	}
+
+	return out
+}
+
+func convertResourcePolicyFromHub(in v1alpha2.ResourcePolicy) ResourcePolicy {
+	out := ResourcePolicy{
+		MinAllowed:                  in.MinAllowed,
+		MaxAllowed:                  in.MaxAllowed,
+		LimitsToRequestsRatioCPU:    in.LimitsToRequestsRatioCPU,
+		LimitsToRequestsRatioMemory: in.LimitsToRequestsRatioMemory,
+		Headroom:                    in.Headroom,
+	}
+
+	if in.TargetUtilization.CPU != nil || in.TargetUtilization.Memory != nil {
+		out.TargetUtilization = make(map[corev1.ResourceName]int32)
+	}
+	if in.TargetUtilization.CPU != nil {
+		out.TargetUtilization[corev1.ResourceCPU] = *in.TargetUtilization.CPU
+	}
+	if in.TargetUtilization.Memory != nil {
+		out.TargetUtilization[corev1.ResourceMemory] = *in.TargetUtilization.Memory
+	}
+
+	if in.Rounding != nil {
+		out.Rounding = make(map[corev1.ResourceName]RoundingPolicy, len(in.Rounding))
+		for name, rounding := range in.Rounding {
+			out.Rounding[name] = RoundingPolicy{
+				Granularity: rounding.Granularity,
+				Direction:   RoundingDirection(rounding.Direction),
+			}
+		}
+	}
+
+	return out
+}
+
+func convertStatusToHub(in *HybridScalerStatus) v1alpha2.HybridScalerStatus {
+	out := v1alpha2.HybridScalerStatus{
+		Replicas:              in.Replicas,
+		ContainerResources:    convertContainerResourcesToHub(in.ContainerResources),
+		PodMetrics:            v1alpha2.PodMetrics(in.PodMetrics),
+		LearningState:         in.LearningState,
+		RecommenderState:      in.RecommenderState,
+		RolloutPending:        in.RolloutPending,
+		LastRolloutTime:       in.LastRolloutTime,
+		LastScaleTime:         in.LastScaleTime,
+		LastActiveTime:        in.LastActiveTime,
+		ActiveProfile:         in.ActiveProfile,
+		SampledPods:           in.SampledPods,
+		UnschedulablePods:     in.UnschedulablePods,
+		EstimatedCost:         in.EstimatedCost,
+		ConsecutiveErrors:     in.ConsecutiveErrors,
+		OverutilizedIntervals: in.OverutilizedIntervals,
+		RecoveredIntervals:    in.RecoveredIntervals,
+		Conditions:            in.Conditions,
+	}
+
+	if in.ContainerPressure != nil {
+		out.ContainerPressure = make(map[string]v1alpha2.ContainerPressure, len(in.ContainerPressure))
+		for name, pressure := range in.ContainerPressure {
+			out.ContainerPressure[name] = v1alpha2.ContainerPressure(pressure)
+		}
+	}
+
+	if in.LastScaleUp != nil {
+		out.LastScaleUp = &v1alpha2.ScaleUp{
+			Replicas:           in.LastScaleUp.Replicas,
+			ContainerResources: convertContainerResourcesToHub(in.LastScaleUp.ContainerResources),
+		}
+	}
+
+	if in.ShadowDecisions != nil {
+		out.ShadowDecisions = make([]v1alpha2.ShadowDecision, len(in.ShadowDecisions))
+		for i, decision := range in.ShadowDecisions {
+			out.ShadowDecisions[i] = v1alpha2.ShadowDecision{
+				Name:               decision.Name,
+				Description:        decision.Description,
+				Replicas:           decision.Replicas,
+				ContainerResources: convertContainerResourcesToHub(decision.ContainerResources),
+				EstimatedCost:      decision.EstimatedCost,
+				LearningState:      decision.LearningState,
+				Error:              decision.Error,
+			}
+		}
+	}
+
+	return out
+}
+
+func convertStatusFromHub(in *v1alpha2.HybridScalerStatus) HybridScalerStatus {
+	out := HybridScalerStatus{
+		Replicas:              in.Replicas,
+		ContainerResources:    convertContainerResourcesFromHub(in.ContainerResources),
+		PodMetrics:            PodMetrics(in.PodMetrics),
+		LearningState:         in.LearningState,
+		RecommenderState:      in.RecommenderState,
+		RolloutPending:        in.RolloutPending,
+		LastRolloutTime:       in.LastRolloutTime,
+		LastScaleTime:         in.LastScaleTime,
+		LastActiveTime:        in.LastActiveTime,
+		ActiveProfile:         in.ActiveProfile,
+		SampledPods:           in.SampledPods,
+		UnschedulablePods:     in.UnschedulablePods,
+		EstimatedCost:         in.EstimatedCost,
+		ConsecutiveErrors:     in.ConsecutiveErrors,
+		OverutilizedIntervals: in.OverutilizedIntervals,
+		RecoveredIntervals:    in.RecoveredIntervals,
+		Conditions:            in.Conditions,
+	}
+
+	if in.ContainerPressure != nil {
+		out.ContainerPressure = make(map[string]ContainerPressure, len(in.ContainerPressure))
+		for name, pressure := range in.ContainerPressure {
+			out.ContainerPressure[name] = ContainerPressure(pressure)
+		}
+	}
+
+	if in.LastScaleUp != nil {
+		out.LastScaleUp = &ScaleUp{
+			Replicas:           in.LastScaleUp.Replicas,
+			ContainerResources: convertContainerResourcesFromHub(in.LastScaleUp.ContainerResources),
+		}
+	}
+
+	if in.ShadowDecisions != nil {
+		out.ShadowDecisions = make([]ShadowDecision, len(in.ShadowDecisions))
+		for i, decision := range in.ShadowDecisions {
+			out.ShadowDecisions[i] = ShadowDecision{
+				Name:               decision.Name,
+				Description:        decision.Description,
+				Replicas:           decision.Replicas,
+				ContainerResources: convertContainerResourcesFromHub(decision.ContainerResources),
+				EstimatedCost:      decision.EstimatedCost,
+				LearningState:      decision.LearningState,
+				Error:              decision.Error,
+			}
+		}
+	}
+
+	return out
+}
+
+func convertContainerResourcesToHub(in map[string]ContainerResources) map[string]v1alpha2.ContainerResources {
+	if in == nil {
+		return nil
+	}
+
+	out := make(map[string]v1alpha2.ContainerResources, len(in))
+	for name, resources := range in {
+		out[name] = v1alpha2.ContainerResources(resources)
+	}
+
+	return out
+}
+
+func convertContainerResourcesFromHub(in map[string]v1alpha2.ContainerResources) map[string]ContainerResources {
+	if in == nil {
+		return nil
+	}
+
+	out := make(map[string]ContainerResources, len(in))
+	for name, resources := range in {
+		out[name] = ContainerResources(resources)
+	}
+
+	return out
+}
+
+func secondsToDuration(s *int32) *metav1.Duration {
+	if s == nil {
+		return nil
+	}
+	return &metav1.Duration{Duration: time.Duration(*s) * time.Second}
+}
+
+func durationToSeconds(d *metav1.Duration) *int32 {
+	if d == nil {
+		return nil
+	}
+	s := seconds(*d)
+	return &s
+}
+
+// seconds truncates the duration to whole seconds
+func seconds(d metav1.Duration) int32 {
+	return int32(d.Duration / time.Second)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	fuzz "github.com/google/gofuzz"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/iljarotar/hybrid-scaler/api/v1alpha2"
+)
+
+const fuzzIterations = 1000
+
+func newFuzzer() *fuzz.Fuzzer {
+	return fuzz.NewWithSeed(1).NilChance(0.3).Funcs(
+		// the type meta is set by the conversion webhook
+		func(tm *metav1.TypeMeta, c fuzz.Continue) {},
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = *resource.NewScaledQuantity(c.Int63n(100000), resource.Scale(-c.Intn(4)))
+		},
+		func(d *metav1.Duration, c fuzz.Continue) {
+			d.Duration = time.Duration(c.Int63n(int64(48 * time.Hour)))
+		},
+		// only cpu and memory have a target utilization
+		func(m *map[corev1.ResourceName]int32, c fuzz.Continue) {
+			*m = nil
+			for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				if c.RandBool() {
+					if *m == nil {
+						*m = make(map[corev1.ResourceName]int32)
+					}
+					(*m)[name] = c.Int31()
+				}
+			}
+		},
+	)
+}
+
+func TestHybridScaler_roundTripFromSpoke(t *testing.T) {
+	f := newFuzzer()
+
+	for i := 0; i < fuzzIterations; i++ {
+		spoke := &HybridScaler{}
+		f.Fuzz(spoke)
+
+		hub := &v1alpha2.HybridScaler{}
+		if err := spoke.ConvertTo(hub); err != nil {
+			t.Fatalf("ConvertTo() error = %v", err)
+		}
+
+		got := &HybridScaler{}
+		if err := got.ConvertFrom(hub); err != nil {
+			t.Fatalf("ConvertFrom() error = %v", err)
+		}
+
+		if diff := cmp.Diff(spoke, got); diff != "" {
+			t.Fatalf("round trip %v", diff)
+		}
+	}
+}
+
+func TestHybridScaler_roundTripFromHub(t *testing.T) {
+	f := newFuzzer()
+
+	for i := 0; i < fuzzIterations; i++ {
+		hub := &v1alpha2.HybridScaler{}
+		f.Fuzz(hub)
+
+		spoke := &HybridScaler{}
+		if err := spoke.ConvertFrom(hub); err != nil {
+			t.Fatalf("ConvertFrom() error = %v", err)
+		}
+
+		got := &v1alpha2.HybridScaler{}
+		if err := spoke.ConvertTo(got); err != nil {
+			t.Fatalf("ConvertTo() error = %v", err)
+		}
+
+		if diff := cmp.Diff(hub, got); diff != "" {
+			t.Fatalf("round trip %v", diff)
+		}
+	}
+}
+
+func TestHybridScaler_ConvertTo(t *testing.T) {
+	ruleBased := &v1alpha2.HybridScaler{
+		Spec: v1alpha2.HybridScalerSpec{
+			Strategy: v1alpha2.Strategy{
+				Type:      v1alpha2.StrategyTypeRuleBased,
+				RuleBased: &v1alpha2.RuleBasedStrategy{},
+			},
+			Interval: &metav1.Duration{Duration: 1500 * time.Millisecond},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		hub    *v1alpha2.HybridScaler
+		modify func(spoke *HybridScaler)
+		want   v1alpha2.HybridScalerSpec
+	}{
+		{
+			name:   "restore fields the spoke cannot represent",
+			hub:    ruleBased,
+			modify: func(spoke *HybridScaler) {},
+			want:   ruleBased.Spec,
+		},
+		{
+			name: "drop restored fields if the spoke has been changed",
+			hub:  ruleBased,
+			modify: func(spoke *HybridScaler) {
+				interval := int32(30)
+				spoke.Spec.Interval = &interval
+			},
+			want: v1alpha2.HybridScalerSpec{
+				Strategy: v1alpha2.Strategy{
+					Type:      v1alpha2.StrategyTypeRuleBased,
+					RuleBased: &v1alpha2.RuleBasedStrategy{},
+				},
+				Interval: &metav1.Duration{Duration: 30 * time.Second},
+				PodFilter: v1alpha2.PodFilter{
+					MinReadyDuration: metav1.Duration{},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spoke := &HybridScaler{}
+			if err := spoke.ConvertFrom(tt.hub); err != nil {
+				t.Fatalf("ConvertFrom() error = %v", err)
+			}
+			tt.modify(spoke)
+
+			got := &v1alpha2.HybridScaler{}
+			if err := spoke.ConvertTo(got); err != nil {
+				t.Fatalf("ConvertTo() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got.Spec); diff != "" {
+				t.Errorf("ConvertTo() %v", diff)
+			}
+			if _, ok := got.Annotations[conversionDataAnnotation]; ok {
+				t.Errorf("ConvertTo() kept the conversion data annotation")
+			}
+		})
+	}
+}
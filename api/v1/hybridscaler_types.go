@@ -28,55 +28,478 @@ import (
 
 // HybridScalerSpec defines the desired state of HybridScaler
 type HybridScalerSpec struct {
-	ScaleTargetRef  v2.CrossVersionObjectReference `json:"scaleTargetRef"`
-	MinReplicas     *int32                         `json:"minReplicas"`
-	MaxReplicas     *int32                         `json:"maxReplicas"`
-	ResourcePolicy  ResourcePolicy                 `json:"resourcePolicy"`
-	LearningType    LearningType                   `json:"learningType"`
-	QLearningParams QLearningParams                `json:"qLearningParams"`
-	Interval        *int32                         `json:"interval"`
+	ScaleTargetRef v2.CrossVersionObjectReference `json:"scaleTargetRef"`
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas must be set on the scaler or its policy, the scaler does not act without it
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// +optional
+	ResourcePolicy ResourcePolicy `json:"resourcePolicy,omitempty"`
+	// +optional
+	LearningType LearningType `json:"learningType,omitempty"`
+	// +optional
+	QLearningParams QLearningParams `json:"qLearningParams,omitempty"`
+	// +optional
+	Interval *int32 `json:"interval,omitempty"`
+	// RuleBasedParams configures the thresholds of the ruleBased learning type
+	// +optional
+	RuleBasedParams RuleBasedParams `json:"ruleBasedParams,omitempty"`
+	// WarmupPeriod is the number of seconds to wait after a rollout has finished before sampling metrics again
+	// +optional
+	WarmupPeriod *int32 `json:"warmupPeriod,omitempty"`
+	// UpdateMode defines how resource changes are applied to the target, defaults to Recreate
+	// +optional
+	UpdateMode UpdateMode `json:"updateMode,omitempty"`
+	// Coexistence allows sharing the target with a HorizontalPodAutoscaler or VerticalPodAutoscaler,
+	// without it the scaler refuses to act if any other autoscaler targets the same workload
+	// +optional
+	Coexistence CoexistenceMode `json:"coexistence,omitempty"`
+	// ScaleToZero lets the scaler scale the target to zero replicas while it is idle, which requires `minReplicas: 0`
+	// +optional
+	ScaleToZero *ScaleToZero `json:"scaleToZero,omitempty"`
+	// Profiles override the scaling constraints during their scheduled windows, the first active profile wins
+	// +optional
+	Profiles []ScalingProfile `json:"profiles,omitempty"`
+	// Forecast enables predictive scaling based on the seasonal usage history of the target
+	// +optional
+	Forecast *Forecast `json:"forecast,omitempty"`
+	// Recommender sizes container resources from percentiles of decaying usage histograms instead of the current average usage
+	// +optional
+	Recommender *RecommenderPolicy `json:"recommender,omitempty"`
+	// PressurePolicy defines how vertical scaling reacts to out-of-memory kills and cpu throttling
+	// +optional
+	PressurePolicy PressurePolicy `json:"pressurePolicy,omitempty"`
+	// PendingTimeout is the number of seconds pods may stay unschedulable before the last scale-up is rolled back, defaults to 300
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PendingTimeout *int32 `json:"pendingTimeout,omitempty"`
+	// PodFilter selects the pods whose usage is sampled
+	// +optional
+	PodFilter PodFilter `json:"podFilter,omitempty"`
+	// ShadowStrategies decide on the same state as the active strategy, their decisions are recorded but never applied
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ShadowStrategies []ShadowStrategy `json:"shadowStrategies,omitempty"`
+	// Fallback replaces the active strategy with a deterministic one while its decisions fail or let the utilization run too high
+	// +optional
+	Fallback *FallbackPolicy `json:"fallback,omitempty"`
+	// LearningGroup is the name of the LearningGroup whose shared learning state and parameters the q-learning strategy uses
+	// instead of its own, the group's parameters take precedence over the scaler's q-learning parameters and the group is
+	// only used with the qLearning learning type, the LearningGroupConflict condition reports settings the group ignores or overrides
+	// +optional
+	LearningGroup string `json:"learningGroup,omitempty"`
+	// Policy is the name of the HybridScalerPolicy providing the defaults of the replica bounds, learning type,
+	// q-learning parameters, resource policy and interval, fields set on the scaler take precedence, even if set to zero
+	// +optional
+	Policy string `json:"policy,omitempty"`
 }
 
+// ShadowStrategy is a strategy which is only evaluated, which allows comparing it with the active strategy without risk
+type ShadowStrategy struct {
+	Name         string       `json:"name"`
+	LearningType LearningType `json:"learningType"`
+	// +optional
+	QLearningParams QLearningParams `json:"qLearningParams,omitempty"`
+	// +optional
+	RuleBasedParams RuleBasedParams `json:"ruleBasedParams,omitempty"`
+}
+
+// ShadowDecision is the decision a shadow strategy would have made
+type ShadowDecision struct {
+	Name string `json:"name"`
+	// Description names the scaling action of the decision
+	// +optional
+	Description string `json:"description,omitempty"`
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// +optional
+	ContainerResources map[string]ContainerResources `json:"containerResources,omitempty"`
+	// EstimatedCost is the cost of the decision estimated like the cost of the active decision
+	// +optional
+	EstimatedCost *resource.Quantity `json:"estimatedCost,omitempty"`
+	// LearningState holds the shadow strategy's own encoded learning state
+	// +optional
+	LearningState []byte `json:"learningState,omitempty"`
+	// Error is set if the shadow strategy could not make a decision
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// FallbackPolicy defines when the active strategy is degraded and which strategy replaces it meanwhile
+type FallbackPolicy struct {
+	// LearningType is the strategy which makes the decisions while the active strategy is degraded, defaults to horizontal
+	// +optional
+	LearningType LearningType `json:"learningType,omitempty"`
+	// +optional
+	RuleBasedParams RuleBasedParams `json:"ruleBasedParams,omitempty"`
+	// MaxConsecutiveErrors is the number of consecutive failed decisions after which the active strategy is degraded, defaults to 3
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConsecutiveErrors *int32 `json:"maxConsecutiveErrors,omitempty"`
+	// MaxUtilization is the utilization of the requests in percent above which an interval counts as overutilized, defaults to 150
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxUtilization *int32 `json:"maxUtilization,omitempty"`
+	// MaxOverutilizedIntervals is the number of consecutive overutilized intervals after which the active strategy is degraded, defaults to 3
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxOverutilizedIntervals *int32 `json:"maxOverutilizedIntervals,omitempty"`
+	// RecoveryIntervals is the number of consecutive intervals at which the degraded strategy must make a decision without
+	// overutilization before it makes the decisions again, defaults to 3
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RecoveryIntervals *int32 `json:"recoveryIntervals,omitempty"`
+}
+
+// PodFilter defines which running pods represent the target's load, pods which are not ready are never sampled
+type PodFilter struct {
+	// CurrentRevisionOnly excludes pods of previous pod templates, such as those of old replica sets, defaults to true
+	// +optional
+	CurrentRevisionOnly *bool `json:"currentRevisionOnly,omitempty"`
+	// MinReadySeconds is the number of seconds a pod must have been ready before it is sampled,
+	// which excludes the inflated usage of starting pods
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+	// IncludeTerminating samples pods which are being deleted as well
+	// +optional
+	IncludeTerminating bool `json:"includeTerminating,omitempty"`
+}
+
+// ScaleUp holds the replicas and resources from before a decision which scaled the target up
+type ScaleUp struct {
+	Replicas int32 `json:"replicas"`
+	// ContainerResources are only set if the decision increased the container resources
+	// +optional
+	ContainerResources map[string]ContainerResources `json:"containerResources,omitempty"`
+}
+
+// PressurePolicy defines by how much resources are raised at least when containers run short of them
+type PressurePolicy struct {
+	// OOMBumpFactor is the factor by which the memory of containers killed for running out of memory is raised, defaults to 1.2
+	// +optional
+	OOMBumpFactor *resource.Quantity `json:"oomBumpFactor,omitempty"`
+	// ThrottlingThreshold is the share of throttled cpu periods above which a container's cpu is raised, defaults to 0.1
+	// +optional
+	ThrottlingThreshold *resource.Quantity `json:"throttlingThreshold,omitempty"`
+	// ThrottlingBumpFactor is the factor by which the cpu of throttled containers is raised, defaults to 1.2
+	// +optional
+	ThrottlingBumpFactor *resource.Quantity `json:"throttlingBumpFactor,omitempty"`
+}
+
+// RecommenderPolicy configures the usage histograms used for vertical scaling
+type RecommenderPolicy struct {
+	// CPUPercentile is the percentile of the cpu usage histogram the requests are sized for, defaults to 90
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	CPUPercentile *int32 `json:"cpuPercentile,omitempty"`
+	// MemoryPercentile is the percentile of the peak memory usage histogram the requests are sized for, defaults to 99
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MemoryPercentile *int32 `json:"memoryPercentile,omitempty"`
+	// HalfLife is the number of seconds after which a sample counts half as much as a new one, defaults to 86400
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	HalfLife *int32 `json:"halfLife,omitempty"`
+}
+
+// Forecast configures the Holt-Winters forecast of the target's usage
+type Forecast struct {
+	// Season is the length of the recurring usage pattern in seconds, e.g. 86400 for daily patterns
+	// +kubebuilder:validation:Minimum=60
+	Season int32 `json:"season"`
+	// Seasons is the number of past seasons the model is fitted to, defaults to 2
+	// +kubebuilder:validation:Minimum=2
+	// +optional
+	Seasons *int32 `json:"seasons,omitempty"`
+	// Step is the resolution of the usage history in seconds, defaults to 300
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Step *int32 `json:"step,omitempty"`
+	// Horizon is the number of intervals the forecast looks ahead, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Horizon *int32 `json:"horizon,omitempty"`
+	// Alpha is the smoothing factor of the level, defaults to 0.5
+	// +optional
+	Alpha *resource.Quantity `json:"alpha,omitempty"`
+	// Beta is the smoothing factor of the trend, defaults to 0.1
+	// +optional
+	Beta *resource.Quantity `json:"beta,omitempty"`
+	// Gamma is the smoothing factor of the seasonality, defaults to 0.3
+	// +optional
+	Gamma *resource.Quantity `json:"gamma,omitempty"`
+}
+
+// ScalingProfile overrides the replica and resource constraints while its schedule is active
+type ScalingProfile struct {
+	Name string `json:"name"`
+	// Schedule is a cron expression of the form `<minute> <hour> <day-of-month> <month> <day-of-week>` marking the start of each window
+	Schedule string `json:"schedule"`
+	// Duration is the number of seconds each window lasts
+	// +kubebuilder:validation:Minimum=60
+	Duration int32 `json:"duration"`
+	// TimeZone is the IANA time zone the schedule is evaluated in, defaults to UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// MinAllowed overrides the minimum resources of the resource policy, resources missing here keep their value
+	// +optional
+	MinAllowed corev1.ResourceList `json:"minAllowed,omitempty"`
+	// MaxAllowed overrides the maximum resources of the resource policy, resources missing here keep their value
+	// +optional
+	MaxAllowed corev1.ResourceList `json:"maxAllowed,omitempty"`
+}
+
+// ScaleToZero defines when the target is considered idle and how it is woken up again
+type ScaleToZero struct {
+	// ActivityQuery is a PromQL query, the target is considered active while any of its samples is nonzero
+	ActivityQuery string `json:"activityQuery"`
+	// IdlePeriod is the number of seconds the target has to be inactive before it is scaled to zero
+	// +kubebuilder:validation:Minimum=0
+	IdlePeriod int32 `json:"idlePeriod"`
+	// ActivationReplicas is the number of replicas the target is scaled to when it becomes active again, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActivationReplicas *int32 `json:"activationReplicas,omitempty"`
+}
+
+// UpdateMode defines how new container resources are applied
+// +kubebuilder:validation:Enum=InPlace;Recreate;InPlaceOrRecreate
+type UpdateMode string
+
+var (
+	// UpdateModeRecreate rewrites the pod template, which rolls out new pods
+	UpdateModeRecreate UpdateMode = "Recreate"
+	// UpdateModeInPlace resizes running pods without restarting them, the pod template is left unchanged since changing it
+	// would replace the resized pods, pods created from the outdated template are resized on the next reconciliation,
+	// only the template of stateful sets with the OnDelete update strategy is updated as well
+	UpdateModeInPlace UpdateMode = "InPlace"
+	// UpdateModeInPlaceOrRecreate resizes running pods like UpdateModeInPlace and falls back to a rollout if the resize is infeasible
+	UpdateModeInPlaceOrRecreate UpdateMode = "InPlaceOrRecreate"
+)
+
+// LearningType selects the strategy which makes the scaling decisions
+// +kubebuilder:validation:Enum=qLearning;horizontal;vertical;hybrid;ruleBased;none
 type LearningType string
 
 var (
+	// LearningTypeQLearning learns which scaling action is the cheapest in each state
 	LearningTypeQLearning LearningType = "qLearning"
+	// LearningTypeHorizontal always scales the replicas like a HorizontalPodAutoscaler
+	LearningTypeHorizontal LearningType = "horizontal"
+	// LearningTypeVertical always scales the container resources like a VerticalPodAutoscaler
+	LearningTypeVertical LearningType = "vertical"
+	// LearningTypeHybrid always scales both the replicas and the container resources
+	LearningTypeHybrid LearningType = "hybrid"
+	// LearningTypeRuleBased adds or removes replicas when the utilization leaves the tolerance around the target,
+	// and resizes the containers once the replicas have reached their limit
+	LearningTypeRuleBased LearningType = "ruleBased"
+	// LearningTypeNone never changes the target
+	LearningTypeNone LearningType = "none"
+)
+
+// RuleBasedParams defines the thresholds and steps of the rule-based strategy
+type RuleBasedParams struct {
+	// Tolerance is the relative deviation from the target utilization within which the target is left unchanged, defaults to 0.1
+	// +optional
+	Tolerance *resource.Quantity `json:"tolerance,omitempty"`
+	// ScaleUpStep is the number of replicas added when the utilization is above the tolerance, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ScaleUpStep *int32 `json:"scaleUpStep,omitempty"`
+	// ScaleDownStep is the number of replicas removed when the utilization is below the tolerance, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ScaleDownStep *int32 `json:"scaleDownStep,omitempty"`
+}
+
+// CoexistenceMode defines which part of the target's scaling the scaler owns when sharing it with another autoscaler
+// +kubebuilder:validation:Enum=None;ResourcesOnly;ReplicasOnly
+type CoexistenceMode string
+
+var (
+	// CoexistenceModeNone does not allow any other autoscaler on the same target
+	CoexistenceModeNone CoexistenceMode = "None"
+	// CoexistenceModeResourcesOnly lets the scaler only decide on resources, while a HorizontalPodAutoscaler owns the replicas
+	CoexistenceModeResourcesOnly CoexistenceMode = "ResourcesOnly"
+	// CoexistenceModeReplicasOnly lets the scaler only decide on replicas, while a VerticalPodAutoscaler owns the resources
+	CoexistenceModeReplicasOnly CoexistenceMode = "ReplicasOnly"
 )
 
 type ResourcePolicy struct {
-	MinAllowed                  corev1.ResourceList           `json:"minAllowed"`
-	MaxAllowed                  corev1.ResourceList           `json:"maxAllowed"`
-	TargetUtilization           map[corev1.ResourceName]int32 `json:"targetUtilization"`
-	LimitsToRequestsRatioCPU    resource.Quantity             `json:"limitsToRequestsRatioCPU"`
-	LimitsToRequestsRatioMemory resource.Quantity             `json:"limitsToRequestsRatioMemory"`
+	// +optional
+	MinAllowed corev1.ResourceList `json:"minAllowed,omitempty"`
+	// +optional
+	MaxAllowed corev1.ResourceList `json:"maxAllowed,omitempty"`
+	// +optional
+	TargetUtilization map[corev1.ResourceName]int32 `json:"targetUtilization,omitempty"`
+	// +optional
+	LimitsToRequestsRatioCPU *resource.Quantity `json:"limitsToRequestsRatioCPU,omitempty"`
+	// +optional
+	LimitsToRequestsRatioMemory *resource.Quantity `json:"limitsToRequestsRatioMemory,omitempty"`
+	// Headroom maps resources to the factor by which requests sized from the average usage are raised, defaults to 1.1
+	// +optional
+	Headroom map[corev1.ResourceName]resource.Quantity `json:"headroom,omitempty"`
+	// Rounding maps resources to the policy by which recommended requests and limits are rounded,
+	// defaults to rounding cpu to the nearest millicore and memory to the nearest byte
+	// +optional
+	Rounding map[corev1.ResourceName]RoundingPolicy `json:"rounding,omitempty"`
 }
 
+// RoundingPolicy defines the steps to which a resource is rounded
+type RoundingPolicy struct {
+	// Granularity is the step to which the resource is rounded, e.g. 10m of cpu or 16Mi of memory
+	// +optional
+	Granularity *resource.Quantity `json:"granularity,omitempty"`
+	// Direction defines whether the resource is rounded up, down or to the nearest step, defaults to Nearest
+	// +optional
+	Direction RoundingDirection `json:"direction,omitempty"`
+}
+
+// RoundingDirection defines in which direction resources are rounded
+// +kubebuilder:validation:Enum=Up;Down;Nearest
+type RoundingDirection string
+
+var (
+	RoundingDirectionUp      RoundingDirection = "Up"
+	RoundingDirectionDown    RoundingDirection = "Down"
+	RoundingDirectionNearest RoundingDirection = "Nearest"
+)
+
 type ContainerResources struct {
 	Requests corev1.ResourceList `json:"requests"`
 	Limits   corev1.ResourceList `json:"limits"`
 }
 
 type QLearningParams struct {
-	LearningRate             resource.Quantity `json:"learningRate"`
-	DiscountFactor           resource.Quantity `json:"discountFactor"`
-	Epsilon                  resource.Quantity `json:"epsilon"`
-	CpuCost                  resource.Quantity `json:"cpuCost"`
-	MemoryCost               resource.Quantity `json:"memoryCost"`
-	UnderprovisioningPenalty resource.Quantity `json:"underprovisioningPenalty"`
+	// +optional
+	LearningRate *resource.Quantity `json:"learningRate,omitempty"`
+	// +optional
+	DiscountFactor *resource.Quantity `json:"discountFactor,omitempty"`
+	// +optional
+	Epsilon *resource.Quantity `json:"epsilon,omitempty"`
+	// +optional
+	CpuCost *resource.Quantity `json:"cpuCost,omitempty"`
+	// +optional
+	MemoryCost *resource.Quantity `json:"memoryCost,omitempty"`
+	// +optional
+	UnderprovisioningPenalty *resource.Quantity `json:"underprovisioningPenalty,omitempty"`
+	// OOMPenalty is added to the cost for each out-of-memory kill
+	// +optional
+	OOMPenalty *resource.Quantity `json:"oomPenalty,omitempty"`
+	// ThrottlingPenalty is added to the cost weighted by the throttling ratio of each replica
+	// +optional
+	ThrottlingPenalty *resource.Quantity `json:"throttlingPenalty,omitempty"`
+	// UnschedulablePenalty is added to the cost for each pod which could not be scheduled
+	// +optional
+	UnschedulablePenalty *resource.Quantity `json:"unschedulablePenalty,omitempty"`
+	// TimeOfDayBuckets splits the day in the time zone into the given number of buckets and adds the current bucket to the learned state,
+	// which allows learning daily patterns, the time of day is not part of the state if unset
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1440
+	// +optional
+	TimeOfDayBuckets *int32 `json:"timeOfDayBuckets,omitempty"`
+	// TimeZone is the IANA name of the time zone the day is split in, e.g. "Europe/Berlin", defaults to UTC
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+	// TraceDecay (lambda) is the factor by which the eligibility of earlier state-action pairs decays each interval,
+	// which credits delayed consequences to the actions that caused them, only the last action is updated if unset
+	// +optional
+	TraceDecay *resource.Quantity `json:"traceDecay,omitempty"`
+	// TraceLength (n) is the maximum number of recent state-action pairs which are credited with an observed cost, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	TraceLength *int32 `json:"traceLength,omitempty"`
+}
+
+// ContainerPressure describes signs of a container running short of resources
+type ContainerPressure struct {
+	// OOMKills is the number of the container's pods whose last termination was caused by running out of memory
+	OOMKills int32 `json:"oomKills,omitempty"`
+	// ThrottlingRatio is the share of cpu periods in which the container was throttled
+	ThrottlingRatio *resource.Quantity `json:"throttlingRatio,omitempty"`
 }
 
 type PodMetrics struct {
-	ResourceUsage corev1.ResourceList `json:"resourceUsage"`
+	// ResourceUsage is the average usage per pod, it is not set before the usage has been sampled
+	// +optional
+	ResourceUsage corev1.ResourceList `json:"resourceUsage,omitempty"`
+	// ForecastResourceUsage is the average usage per pod predicted for the forecast horizon at the current number of replicas
+	ForecastResourceUsage corev1.ResourceList `json:"forecastResourceUsage,omitempty"`
 }
 
 // HybridScalerStatus defines the observed state of HybridScaler
 type HybridScalerStatus struct {
-	Replicas           int32                         `json:"replicas"`
-	ContainerResources map[string]ContainerResources `json:"containerResources"`
-	PodMetrics         PodMetrics                    `json:"podMetrics"`
-	LearningState      []byte                        `json:"learningState,omitempty"`
+	Replicas int32 `json:"replicas"`
+	// ContainerResources and PodMetrics are not set before the first sampling of the target,
+	// the status may be written earlier to report conditions
+	// +optional
+	ContainerResources map[string]ContainerResources `json:"containerResources,omitempty"`
+	// +optional
+	PodMetrics    PodMetrics `json:"podMetrics,omitempty"`
+	LearningState []byte     `json:"learningState,omitempty"`
+	// RecommenderState holds the encoded usage histograms of the containers
+	RecommenderState []byte `json:"recommenderState,omitempty"`
+	// RolloutPending is set while a rollout of the target is in progress or has been triggered by the scaler
+	RolloutPending bool `json:"rolloutPending,omitempty"`
+	// LastRolloutTime is the time at which the last observed rollout of the target has finished
+	LastRolloutTime *metav1.Time `json:"lastRolloutTime,omitempty"`
+	// LastScaleTime is the time at which the last scaling decision was made
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+	// LastActiveTime is the last time the activity query of a scaler with scale-to-zero returned a nonzero value
+	LastActiveTime *metav1.Time `json:"lastActiveTime,omitempty"`
+	// ContainerPressure maps container names to the signs of resource shortage observed since the last decision
+	ContainerPressure map[string]ContainerPressure `json:"containerPressure,omitempty"`
+	// ActiveProfile is the name of the scaling profile whose constraints are currently applied
+	ActiveProfile string `json:"activeProfile,omitempty"`
+	// SampledPods is the number of pods whose usage was sampled at the last reconciliation
+	SampledPods int32 `json:"sampledPods,omitempty"`
+	// UnschedulablePods is the highest number of pods which could not be scheduled observed since the last decision
+	UnschedulablePods int32 `json:"unschedulablePods,omitempty"`
+	// LastScaleUp is set if the last decision scaled the target up and is rolled back if its pods stay unschedulable
+	LastScaleUp *ScaleUp `json:"lastScaleUp,omitempty"`
+	// EstimatedCost is the estimated cost of the last decision, based on the cpu cost, memory cost and underprovisioning penalty
+	// of the q-learning parameters
+	EstimatedCost *resource.Quantity `json:"estimatedCost,omitempty"`
+	// ShadowDecisions are the decisions the shadow strategies would have made instead of the last decision
+	// +listType=map
+	// +listMapKey=name
+	ShadowDecisions []ShadowDecision `json:"shadowDecisions,omitempty"`
+	// ConsecutiveErrors is the number of consecutive decisions the active strategy failed to make
+	ConsecutiveErrors int32 `json:"consecutiveErrors,omitempty"`
+	// OverutilizedIntervals is the number of consecutive decisions at which the utilization exceeded the fallback's maximum
+	OverutilizedIntervals int32 `json:"overutilizedIntervals,omitempty"`
+	// RecoveredIntervals is the number of consecutive healthy decisions of the active strategy while it is degraded
+	RecoveredIntervals int32 `json:"recoveredIntervals,omitempty"`
+	// Conditions represent the latest observations of the scaler's state
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+const (
+	// ConditionTargetConflict is true if other autoscalers target the same workload, in which case the scaler does not act
+	ConditionTargetConflict = "TargetConflict"
+	// ConditionQuotaLimited is true if the last decision was reduced to fit the namespace's resource quotas and limit ranges
+	ConditionQuotaLimited = "QuotaLimited"
+	// ConditionDegraded is true while the fallback strategy makes the decisions instead of the active strategy
+	ConditionDegraded = "Degraded"
+	// ConditionLearningGroupConflict is true if the scaler's learning type does not use its learning group or the group overrides its q-learning parameters
+	ConditionLearningGroupConflict = "LearningGroupConflict"
+	// ConditionIncompleteSpec is true if required fields are set neither on the scaler nor on its policy, in which case the scaler does not act
+	ConditionIncompleteSpec = "IncompleteSpec"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
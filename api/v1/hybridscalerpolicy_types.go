@@ -0,0 +1,77 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HybridScalerPolicySpec defines the defaults of the HybridScalers referencing the policy
+type HybridScalerPolicySpec struct {
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// +optional
+	LearningType LearningType `json:"learningType,omitempty"`
+	// +optional
+	QLearningParams QLearningParams `json:"qLearningParams,omitempty"`
+	// +optional
+	ResourcePolicy ResourcePolicy `json:"resourcePolicy,omitempty"`
+	// Interval is the number of seconds between two scaling decisions
+	// +optional
+	Interval *int32 `json:"interval,omitempty"`
+}
+
+// ScalerReference names a HybridScaler
+type ScalerReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// HybridScalerPolicyStatus defines the observed state of HybridScalerPolicy
+type HybridScalerPolicyStatus struct {
+	// Scalers are the HybridScalers referencing the policy
+	// +optional
+	Scalers []ScalerReference `json:"scalers,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// HybridScalerPolicy provides defaults for the HybridScalers referencing it, which saves repeating them in every namespace
+type HybridScalerPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HybridScalerPolicySpec   `json:"spec,omitempty"`
+	Status HybridScalerPolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HybridScalerPolicyList contains a list of HybridScalerPolicy
+type HybridScalerPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HybridScalerPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HybridScalerPolicy{}, &HybridScalerPolicyList{})
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LearningGroupSpec defines the parameters shared by the HybridScalers of a learning group
+type LearningGroupSpec struct {
+	// QLearningParams replace the q-learning parameters of the members, which keeps the costs in the shared table comparable
+	QLearningParams QLearningParams `json:"qLearningParams"`
+}
+
+// LearningGroupStatus defines the observed state of LearningGroup
+type LearningGroupStatus struct {
+	// LearningState holds the encoded table learned by all members together, the members' pending decisions are kept in their own status
+	LearningState []byte `json:"learningState,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// LearningGroup lets the HybridScalers of similar workloads learn from each other's experience by sharing one learning state
+type LearningGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LearningGroupSpec   `json:"spec,omitempty"`
+	Status LearningGroupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// LearningGroupList contains a list of LearningGroup
+type LearningGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LearningGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LearningGroup{}, &LearningGroupList{})
+}
@@ -22,9 +22,31 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerPressure) DeepCopyInto(out *ContainerPressure) {
+	*out = *in
+	if in.ThrottlingRatio != nil {
+		in, out := &in.ThrottlingRatio, &out.ThrottlingRatio
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerPressure.
+func (in *ContainerPressure) DeepCopy() *ContainerPressure {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerPressure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerResources) DeepCopyInto(out *ContainerResources) {
 	*out = *in
@@ -54,6 +76,87 @@ func (in *ContainerResources) DeepCopy() *ContainerResources {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FallbackPolicy) DeepCopyInto(out *FallbackPolicy) {
+	*out = *in
+	in.RuleBasedParams.DeepCopyInto(&out.RuleBasedParams)
+	if in.MaxConsecutiveErrors != nil {
+		in, out := &in.MaxConsecutiveErrors, &out.MaxConsecutiveErrors
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxUtilization != nil {
+		in, out := &in.MaxUtilization, &out.MaxUtilization
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxOverutilizedIntervals != nil {
+		in, out := &in.MaxOverutilizedIntervals, &out.MaxOverutilizedIntervals
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RecoveryIntervals != nil {
+		in, out := &in.RecoveryIntervals, &out.RecoveryIntervals
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FallbackPolicy.
+func (in *FallbackPolicy) DeepCopy() *FallbackPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FallbackPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Forecast) DeepCopyInto(out *Forecast) {
+	*out = *in
+	if in.Seasons != nil {
+		in, out := &in.Seasons, &out.Seasons
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Step != nil {
+		in, out := &in.Step, &out.Step
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Horizon != nil {
+		in, out := &in.Horizon, &out.Horizon
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Alpha != nil {
+		in, out := &in.Alpha, &out.Alpha
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Beta != nil {
+		in, out := &in.Beta, &out.Beta
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Gamma != nil {
+		in, out := &in.Gamma, &out.Gamma
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Forecast.
+func (in *Forecast) DeepCopy() *Forecast {
+	if in == nil {
+		return nil
+	}
+	out := new(Forecast)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HybridScaler) DeepCopyInto(out *HybridScaler) {
 	*out = *in
@@ -113,6 +216,117 @@ func (in *HybridScalerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HybridScalerPolicy) DeepCopyInto(out *HybridScalerPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HybridScalerPolicy.
+func (in *HybridScalerPolicy) DeepCopy() *HybridScalerPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HybridScalerPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HybridScalerPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HybridScalerPolicyList) DeepCopyInto(out *HybridScalerPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HybridScalerPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HybridScalerPolicyList.
+func (in *HybridScalerPolicyList) DeepCopy() *HybridScalerPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(HybridScalerPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HybridScalerPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HybridScalerPolicySpec) DeepCopyInto(out *HybridScalerPolicySpec) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.QLearningParams.DeepCopyInto(&out.QLearningParams)
+	in.ResourcePolicy.DeepCopyInto(&out.ResourcePolicy)
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HybridScalerPolicySpec.
+func (in *HybridScalerPolicySpec) DeepCopy() *HybridScalerPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HybridScalerPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HybridScalerPolicyStatus) DeepCopyInto(out *HybridScalerPolicyStatus) {
+	*out = *in
+	if in.Scalers != nil {
+		in, out := &in.Scalers, &out.Scalers
+		*out = make([]ScalerReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HybridScalerPolicyStatus.
+func (in *HybridScalerPolicyStatus) DeepCopy() *HybridScalerPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HybridScalerPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HybridScalerSpec) DeepCopyInto(out *HybridScalerSpec) {
 	*out = *in
@@ -134,6 +348,53 @@ func (in *HybridScalerSpec) DeepCopyInto(out *HybridScalerSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	in.RuleBasedParams.DeepCopyInto(&out.RuleBasedParams)
+	if in.WarmupPeriod != nil {
+		in, out := &in.WarmupPeriod, &out.WarmupPeriod
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ScaleToZero != nil {
+		in, out := &in.ScaleToZero, &out.ScaleToZero
+		*out = new(ScaleToZero)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]ScalingProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Forecast != nil {
+		in, out := &in.Forecast, &out.Forecast
+		*out = new(Forecast)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Recommender != nil {
+		in, out := &in.Recommender, &out.Recommender
+		*out = new(RecommenderPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	in.PressurePolicy.DeepCopyInto(&out.PressurePolicy)
+	if in.PendingTimeout != nil {
+		in, out := &in.PendingTimeout, &out.PendingTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	in.PodFilter.DeepCopyInto(&out.PodFilter)
+	if in.ShadowStrategies != nil {
+		in, out := &in.ShadowStrategies, &out.ShadowStrategies
+		*out = make([]ShadowStrategy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(FallbackPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HybridScalerSpec.
@@ -162,6 +423,54 @@ func (in *HybridScalerStatus) DeepCopyInto(out *HybridScalerStatus) {
 		*out = make([]byte, len(*in))
 		copy(*out, *in)
 	}
+	if in.RecommenderState != nil {
+		in, out := &in.RecommenderState, &out.RecommenderState
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastRolloutTime != nil {
+		in, out := &in.LastRolloutTime, &out.LastRolloutTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastActiveTime != nil {
+		in, out := &in.LastActiveTime, &out.LastActiveTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ContainerPressure != nil {
+		in, out := &in.ContainerPressure, &out.ContainerPressure
+		*out = make(map[string]ContainerPressure, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.LastScaleUp != nil {
+		in, out := &in.LastScaleUp, &out.LastScaleUp
+		*out = new(ScaleUp)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EstimatedCost != nil {
+		in, out := &in.EstimatedCost, &out.EstimatedCost
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ShadowDecisions != nil {
+		in, out := &in.ShadowDecisions, &out.ShadowDecisions
+		*out = make([]ShadowDecision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HybridScalerStatus.
@@ -174,6 +483,121 @@ func (in *HybridScalerStatus) DeepCopy() *HybridScalerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LearningGroup) DeepCopyInto(out *LearningGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LearningGroup.
+func (in *LearningGroup) DeepCopy() *LearningGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(LearningGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LearningGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LearningGroupList) DeepCopyInto(out *LearningGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LearningGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LearningGroupList.
+func (in *LearningGroupList) DeepCopy() *LearningGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(LearningGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LearningGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LearningGroupSpec) DeepCopyInto(out *LearningGroupSpec) {
+	*out = *in
+	in.QLearningParams.DeepCopyInto(&out.QLearningParams)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LearningGroupSpec.
+func (in *LearningGroupSpec) DeepCopy() *LearningGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LearningGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LearningGroupStatus) DeepCopyInto(out *LearningGroupStatus) {
+	*out = *in
+	if in.LearningState != nil {
+		in, out := &in.LearningState, &out.LearningState
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LearningGroupStatus.
+func (in *LearningGroupStatus) DeepCopy() *LearningGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LearningGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodFilter) DeepCopyInto(out *PodFilter) {
+	*out = *in
+	if in.CurrentRevisionOnly != nil {
+		in, out := &in.CurrentRevisionOnly, &out.CurrentRevisionOnly
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodFilter.
+func (in *PodFilter) DeepCopy() *PodFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(PodFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodMetrics) DeepCopyInto(out *PodMetrics) {
 	*out = *in
@@ -184,6 +608,13 @@ func (in *PodMetrics) DeepCopyInto(out *PodMetrics) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.ForecastResourceUsage != nil {
+		in, out := &in.ForecastResourceUsage, &out.ForecastResourceUsage
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodMetrics.
@@ -196,15 +627,104 @@ func (in *PodMetrics) DeepCopy() *PodMetrics {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PressurePolicy) DeepCopyInto(out *PressurePolicy) {
+	*out = *in
+	if in.OOMBumpFactor != nil {
+		in, out := &in.OOMBumpFactor, &out.OOMBumpFactor
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ThrottlingThreshold != nil {
+		in, out := &in.ThrottlingThreshold, &out.ThrottlingThreshold
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ThrottlingBumpFactor != nil {
+		in, out := &in.ThrottlingBumpFactor, &out.ThrottlingBumpFactor
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PressurePolicy.
+func (in *PressurePolicy) DeepCopy() *PressurePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PressurePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QLearningParams) DeepCopyInto(out *QLearningParams) {
 	*out = *in
-	out.LearningRate = in.LearningRate.DeepCopy()
-	out.DiscountFactor = in.DiscountFactor.DeepCopy()
-	out.Epsilon = in.Epsilon.DeepCopy()
-	out.CpuCost = in.CpuCost.DeepCopy()
-	out.MemoryCost = in.MemoryCost.DeepCopy()
-	out.UnderprovisioningPenalty = in.UnderprovisioningPenalty.DeepCopy()
+	if in.LearningRate != nil {
+		in, out := &in.LearningRate, &out.LearningRate
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DiscountFactor != nil {
+		in, out := &in.DiscountFactor, &out.DiscountFactor
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Epsilon != nil {
+		in, out := &in.Epsilon, &out.Epsilon
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CpuCost != nil {
+		in, out := &in.CpuCost, &out.CpuCost
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MemoryCost != nil {
+		in, out := &in.MemoryCost, &out.MemoryCost
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.UnderprovisioningPenalty != nil {
+		in, out := &in.UnderprovisioningPenalty, &out.UnderprovisioningPenalty
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.OOMPenalty != nil {
+		in, out := &in.OOMPenalty, &out.OOMPenalty
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ThrottlingPenalty != nil {
+		in, out := &in.ThrottlingPenalty, &out.ThrottlingPenalty
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.UnschedulablePenalty != nil {
+		in, out := &in.UnschedulablePenalty, &out.UnschedulablePenalty
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.TimeOfDayBuckets != nil {
+		in, out := &in.TimeOfDayBuckets, &out.TimeOfDayBuckets
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+	if in.TraceDecay != nil {
+		in, out := &in.TraceDecay, &out.TraceDecay
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.TraceLength != nil {
+		in, out := &in.TraceLength, &out.TraceLength
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QLearningParams.
@@ -217,6 +737,36 @@ func (in *QLearningParams) DeepCopy() *QLearningParams {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecommenderPolicy) DeepCopyInto(out *RecommenderPolicy) {
+	*out = *in
+	if in.CPUPercentile != nil {
+		in, out := &in.CPUPercentile, &out.CPUPercentile
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MemoryPercentile != nil {
+		in, out := &in.MemoryPercentile, &out.MemoryPercentile
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HalfLife != nil {
+		in, out := &in.HalfLife, &out.HalfLife
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommenderPolicy.
+func (in *RecommenderPolicy) DeepCopy() *RecommenderPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RecommenderPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourcePolicy) DeepCopyInto(out *ResourcePolicy) {
 	*out = *in
@@ -241,8 +791,30 @@ func (in *ResourcePolicy) DeepCopyInto(out *ResourcePolicy) {
 			(*out)[key] = val
 		}
 	}
-	out.LimitsToRequestsRatioCPU = in.LimitsToRequestsRatioCPU.DeepCopy()
-	out.LimitsToRequestsRatioMemory = in.LimitsToRequestsRatioMemory.DeepCopy()
+	if in.LimitsToRequestsRatioCPU != nil {
+		in, out := &in.LimitsToRequestsRatioCPU, &out.LimitsToRequestsRatioCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.LimitsToRequestsRatioMemory != nil {
+		in, out := &in.LimitsToRequestsRatioMemory, &out.LimitsToRequestsRatioMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Headroom != nil {
+		in, out := &in.Headroom, &out.Headroom
+		*out = make(map[corev1.ResourceName]resource.Quantity, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Rounding != nil {
+		in, out := &in.Rounding, &out.Rounding
+		*out = make(map[corev1.ResourceName]RoundingPolicy, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourcePolicy.
@@ -254,3 +826,198 @@ func (in *ResourcePolicy) DeepCopy() *ResourcePolicy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoundingPolicy) DeepCopyInto(out *RoundingPolicy) {
+	*out = *in
+	if in.Granularity != nil {
+		in, out := &in.Granularity, &out.Granularity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoundingPolicy.
+func (in *RoundingPolicy) DeepCopy() *RoundingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RoundingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleBasedParams) DeepCopyInto(out *RuleBasedParams) {
+	*out = *in
+	if in.Tolerance != nil {
+		in, out := &in.Tolerance, &out.Tolerance
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ScaleUpStep != nil {
+		in, out := &in.ScaleUpStep, &out.ScaleUpStep
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ScaleDownStep != nil {
+		in, out := &in.ScaleDownStep, &out.ScaleDownStep
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleBasedParams.
+func (in *RuleBasedParams) DeepCopy() *RuleBasedParams {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleBasedParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleToZero) DeepCopyInto(out *ScaleToZero) {
+	*out = *in
+	if in.ActivationReplicas != nil {
+		in, out := &in.ActivationReplicas, &out.ActivationReplicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleToZero.
+func (in *ScaleToZero) DeepCopy() *ScaleToZero {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleToZero)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleUp) DeepCopyInto(out *ScaleUp) {
+	*out = *in
+	if in.ContainerResources != nil {
+		in, out := &in.ContainerResources, &out.ContainerResources
+		*out = make(map[string]ContainerResources, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleUp.
+func (in *ScaleUp) DeepCopy() *ScaleUp {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleUp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalerReference) DeepCopyInto(out *ScalerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalerReference.
+func (in *ScalerReference) DeepCopy() *ScalerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingProfile) DeepCopyInto(out *ScalingProfile) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinAllowed != nil {
+		in, out := &in.MinAllowed, &out.MinAllowed
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.MaxAllowed != nil {
+		in, out := &in.MaxAllowed, &out.MaxAllowed
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingProfile.
+func (in *ScalingProfile) DeepCopy() *ScalingProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShadowDecision) DeepCopyInto(out *ShadowDecision) {
+	*out = *in
+	if in.ContainerResources != nil {
+		in, out := &in.ContainerResources, &out.ContainerResources
+		*out = make(map[string]ContainerResources, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.EstimatedCost != nil {
+		in, out := &in.EstimatedCost, &out.EstimatedCost
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.LearningState != nil {
+		in, out := &in.LearningState, &out.LearningState
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShadowDecision.
+func (in *ShadowDecision) DeepCopy() *ShadowDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(ShadowDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShadowStrategy) DeepCopyInto(out *ShadowStrategy) {
+	*out = *in
+	in.QLearningParams.DeepCopyInto(&out.QLearningParams)
+	in.RuleBasedParams.DeepCopyInto(&out.RuleBasedParams)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShadowStrategy.
+func (in *ShadowStrategy) DeepCopy() *ShadowStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(ShadowStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
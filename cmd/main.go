@@ -19,6 +19,8 @@ package main
 import (
 	"flag"
 	"os"
+	// Embed the time zone database, which the distroless base image lacks, for the time zones of scaling profiles.
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -32,8 +34,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+	scalingv1alpha2 "github.com/iljarotar/hybrid-scaler/api/v1alpha2"
 	"github.com/iljarotar/hybrid-scaler/internal/controller"
 	promclient "github.com/prometheus/client_golang/api"
 	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
@@ -49,6 +53,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(scalingv1.AddToScheme(scheme))
 	utilruntime.Must(v1beta1.AddToScheme(scheme))
+	utilruntime.Must(scalingv1alpha2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -75,6 +80,7 @@ func main() {
 	mgr, err := ctrl.NewManager(config, ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
+		WebhookServer:          webhook.NewServer(webhook.Options{Port: 9443}),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "84cc3a6a.autoscaling.custom",
@@ -101,13 +107,37 @@ func main() {
 	promAPI := promv1.NewAPI(c)
 
 	if err = (&controller.HybridScalerReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		PromAPI: promAPI,
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		PromAPI:  promAPI,
+		Recorder: mgr.GetEventRecorderFor("hybrid-scaler"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HybridScaler")
 		os.Exit(1)
 	}
+	if err = (&controller.HybridScalerPolicyReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HybridScalerPolicy")
+		os.Exit(1)
+	}
+	for _, kind := range []string{"Deployment", "StatefulSet"} {
+		if err = (&controller.OnboardingReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+			Kind:   kind,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Onboarding", "kind", kind)
+			os.Exit(1)
+		}
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&scalingv1alpha2.HybridScaler{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HybridScaler")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
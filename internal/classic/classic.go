@@ -0,0 +1,46 @@
+// Package classic contains deterministic scaling strategies, which serve as a baseline for and a fallback from learning
+package classic
+
+import (
+	"github.com/iljarotar/hybrid-scaler/internal/scaling"
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+)
+
+// Horizontal always scales the replicas like a HorizontalPodAutoscaler
+type Horizontal struct{}
+
+func (h *Horizontal) MakeDecision(state *strategy.State, learningState []byte) (*strategy.ScalingDecision, []byte, error) {
+	decision, err := scaling.Horizontal(state)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decision.Description = "horizontal"
+	return decision, learningState, nil
+}
+
+// Vertical always scales the container resources like a VerticalPodAutoscaler
+type Vertical struct{}
+
+func (v *Vertical) MakeDecision(state *strategy.State, learningState []byte) (*strategy.ScalingDecision, []byte, error) {
+	decision, err := scaling.Vertical(state, state.LimitsToRequestsRatioCPU, state.LimitsToRequestsRatioMemory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decision.Description = "vertical"
+	return decision, learningState, nil
+}
+
+// Hybrid always scales both the replicas and the container resources
+type Hybrid struct{}
+
+func (h *Hybrid) MakeDecision(state *strategy.State, learningState []byte) (*strategy.ScalingDecision, []byte, error) {
+	decision, err := scaling.Hybrid(state, state.LimitsToRequestsRatioCPU, state.LimitsToRequestsRatioMemory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decision.Description = "hybrid"
+	return decision, learningState, nil
+}
@@ -0,0 +1,213 @@
+package classic
+
+import (
+	"testing"
+
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+	"gopkg.in/inf.v0"
+)
+
+func ruleBasedState(replicas int32, cpuUsage, memoryUsage int64) *strategy.State {
+	return &strategy.State{
+		Replicas: replicas,
+		ContainerResources: strategy.ContainerResources{
+			"container": {
+				Requests: strategy.ResourcesList{
+					CPU:    inf.NewDec(200, 0),
+					Memory: inf.NewDec(200, 0),
+				},
+				Limits: strategy.ResourcesList{
+					CPU:    inf.NewDec(400, 0),
+					Memory: inf.NewDec(400, 0),
+				},
+			},
+		},
+		Constraints: strategy.Constraints{
+			MinReplicas: 1,
+			MaxReplicas: 10,
+			MinResources: strategy.ResourcesList{
+				CPU:    inf.NewDec(10, 0),
+				Memory: inf.NewDec(10, 0),
+			},
+			MaxResources: strategy.ResourcesList{
+				CPU:    inf.NewDec(1000, 0),
+				Memory: inf.NewDec(1000, 0),
+			},
+			LimitsToRequestsRatioCPU:    inf.NewDec(2, 0),
+			LimitsToRequestsRatioMemory: inf.NewDec(2, 0),
+		},
+		PodMetrics: strategy.PodMetrics{
+			ResourceUsage: strategy.ResourcesList{
+				CPU:    inf.NewDec(cpuUsage, 0),
+				Memory: inf.NewDec(memoryUsage, 0),
+			},
+			Resources: strategy.Resources{
+				Requests: strategy.ResourcesList{
+					CPU:    inf.NewDec(200, 0),
+					Memory: inf.NewDec(200, 0),
+				},
+				Limits: strategy.ResourcesList{
+					CPU:    inf.NewDec(400, 0),
+					Memory: inf.NewDec(400, 0),
+				},
+			},
+		},
+		TargetUtilization: strategy.ResourcesList{
+			CPU:    inf.NewDec(50, 2),
+			Memory: inf.NewDec(50, 2),
+		},
+	}
+}
+
+func TestRuleBased_MakeDecision(t *testing.T) {
+	tests := []struct {
+		name            string
+		state           *strategy.State
+		modify          func(s *strategy.State)
+		wantReplicas    int32
+		wantDescription string
+		wantCPURequests *inf.Dec
+		wantErr         bool
+	}{
+		{
+			name:            "within tolerance",
+			state:           ruleBasedState(3, 105, 100),
+			wantReplicas:    3,
+			wantDescription: "none",
+			wantCPURequests: inf.NewDec(200, 0),
+		},
+		{
+			name:            "scale out by the scale up step",
+			state:           ruleBasedState(3, 150, 100),
+			wantReplicas:    5,
+			wantDescription: "horizontal",
+			wantCPURequests: inf.NewDec(200, 0),
+		},
+		{
+			name:            "scale out up to the maximum",
+			state:           ruleBasedState(9, 150, 100),
+			wantReplicas:    10,
+			wantDescription: "horizontal",
+			wantCPURequests: inf.NewDec(200, 0),
+		},
+		{
+			name:            "scale in if both resources are below the tolerance",
+			state:           ruleBasedState(3, 50, 50),
+			wantReplicas:    2,
+			wantDescription: "horizontal",
+			wantCPURequests: inf.NewDec(200, 0),
+		},
+		{
+			name:            "resize containers at the maximum replicas",
+			state:           ruleBasedState(10, 150, 100),
+			wantReplicas:    10,
+			wantDescription: "vertical",
+			wantCPURequests: inf.NewDec(330, 0),
+		},
+		{
+			name:  "resize containers if the replicas are fixed",
+			state: ruleBasedState(3, 150, 100),
+			modify: func(s *strategy.State) {
+				s.FixedReplicas = true
+			},
+			wantReplicas:    3,
+			wantDescription: "vertical",
+			wantCPURequests: inf.NewDec(330, 0),
+		},
+		{
+			name:  "keep everything at the minimum replicas with fixed resources",
+			state: ruleBasedState(1, 50, 50),
+			modify: func(s *strategy.State) {
+				s.FixedResources = true
+			},
+			wantReplicas:    1,
+			wantDescription: "none",
+			wantCPURequests: inf.NewDec(200, 0),
+		},
+		{
+			name:            "scaled to zero",
+			state:           ruleBasedState(0, 0, 0),
+			wantReplicas:    0,
+			wantDescription: "none",
+			wantCPURequests: inf.NewDec(200, 0),
+		},
+		{
+			name: "zero target utilization",
+			state: func() *strategy.State {
+				s := ruleBasedState(3, 150, 100)
+				s.TargetUtilization.CPU = inf.NewDec(0, 0)
+				return s
+			}(),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.modify != nil {
+				tt.modify(tt.state)
+			}
+
+			r := NewRuleBased(inf.NewDec(1, 1), 2, 1)
+			got, learningState, err := r.MakeDecision(tt.state, []byte("state"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MakeDecision() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if string(learningState) != "state" {
+				t.Errorf("MakeDecision() learning state = %s, want it unchanged", learningState)
+			}
+			if got.Replicas != tt.wantReplicas {
+				t.Errorf("MakeDecision() replicas = %v, want %v", got.Replicas, tt.wantReplicas)
+			}
+			if got.Description != tt.wantDescription {
+				t.Errorf("MakeDecision() description = %v, want %v", got.Description, tt.wantDescription)
+			}
+			if cpu := got.ContainerResources["container"].Requests.CPU; cpu.Cmp(tt.wantCPURequests) != 0 {
+				t.Errorf("MakeDecision() cpu requests = %v, want %v", cpu, tt.wantCPURequests)
+			}
+		})
+	}
+}
+
+func TestHorizontal_MakeDecision(t *testing.T) {
+	tests := []struct {
+		name         string
+		strategy     strategy.ScalingStrategy
+		wantReplicas int32
+	}{
+		{
+			name:         "horizontal scales the replicas",
+			strategy:     &Horizontal{},
+			wantReplicas: 5,
+		},
+		{
+			name:         "vertical keeps the replicas",
+			strategy:     &Vertical{},
+			wantReplicas: 3,
+		},
+		{
+			name:         "hybrid scales half of the way horizontally",
+			strategy:     &Hybrid{},
+			wantReplicas: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, learningState, err := tt.strategy.MakeDecision(ruleBasedState(3, 150, 100), []byte("state"))
+			if err != nil {
+				t.Errorf("MakeDecision() error = %v", err)
+				return
+			}
+			if string(learningState) != "state" {
+				t.Errorf("MakeDecision() learning state = %s, want it unchanged", learningState)
+			}
+			if got.Replicas != tt.wantReplicas {
+				t.Errorf("MakeDecision() replicas = %v, want %v", got.Replicas, tt.wantReplicas)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package classic
+
+import (
+	"github.com/iljarotar/hybrid-scaler/internal/scaling"
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+	"gopkg.in/inf.v0"
+)
+
+// RuleBased adds or removes a fixed number of replicas whenever the utilization leaves the tolerance around the target,
+// once the replicas have reached their limit it resizes the containers instead
+type RuleBased struct {
+	tolerance                  *inf.Dec
+	scaleUpStep, scaleDownStep int32
+}
+
+func NewRuleBased(tolerance *inf.Dec, scaleUpStep, scaleDownStep int32) *RuleBased {
+	return &RuleBased{
+		tolerance:     tolerance,
+		scaleUpStep:   scaleUpStep,
+		scaleDownStep: scaleDownStep,
+	}
+}
+
+func (r *RuleBased) MakeDecision(state *strategy.State, learningState []byte) (*strategy.ScalingDecision, []byte, error) {
+	unchanged := &strategy.ScalingDecision{
+		Description:        "none",
+		Replicas:           state.Replicas,
+		ContainerResources: state.ContainerResources,
+	}
+
+	// there is no usage to apply the rules to
+	if state.Replicas == 0 {
+		return unchanged, learningState, nil
+	}
+
+	ratio, err := scaling.UtilizationRatio(state)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	one := inf.NewDec(1, 0)
+	upperThreshold := new(inf.Dec).Add(one, r.tolerance)
+	lowerThreshold := new(inf.Dec).Sub(one, r.tolerance)
+
+	var decision *strategy.ScalingDecision
+	switch {
+	case ratio.Cmp(upperThreshold) > 0:
+		decision, err = r.scale(state, r.scaleUpStep, state.MaxReplicas)
+	case ratio.Cmp(lowerThreshold) < 0:
+		decision, err = r.scale(state, -r.scaleDownStep, state.MinReplicas)
+	default:
+		decision = unchanged
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return decision, learningState, nil
+}
+
+// scale changes the replicas by the given step up to the limit, and resizes the containers if the replicas are already at the limit
+func (r *RuleBased) scale(s *strategy.State, step, limit int32) (*strategy.ScalingDecision, error) {
+	replicas := s.Replicas + step
+	if (step > 0 && replicas > limit) || (step < 0 && replicas < limit) {
+		replicas = limit
+	}
+
+	if !s.FixedReplicas && replicas != s.Replicas {
+		return &strategy.ScalingDecision{
+			Description:        "horizontal",
+			Replicas:           replicas,
+			ContainerResources: s.ContainerResources,
+		}, nil
+	}
+
+	if s.FixedResources {
+		return &strategy.ScalingDecision{
+			Description:        "none",
+			Replicas:           s.Replicas,
+			ContainerResources: s.ContainerResources,
+		}, nil
+	}
+
+	decision, err := scaling.Vertical(s, s.LimitsToRequestsRatioCPU, s.LimitsToRequestsRatioMemory)
+	if err != nil {
+		return nil, err
+	}
+
+	decision.Description = "vertical"
+	return decision, nil
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+const (
+	// fieldManager is the field manager used for server-side apply, it only ever owns the fields the scaler decides on
+	fieldManager = "hybrid-scaler"
+	// replicasFieldManager applies changes of the replicas only, a separate manager is needed since applying them as
+	// `fieldManager` would remove the container resources it owns from the pod template
+	replicasFieldManager = "hybrid-scaler-replicas"
+)
+
+// applyWorkload sets the workload's replicas and container resources using server-side apply,
+// all other fields remain owned by their current managers, replicas are left to other managers if nil
+// and the pod template is left untouched if resources are nil,
+// ownership of the applied fields is forced, so the apply never fails with a conflict
+func (r *HybridScalerReconciler) applyWorkload(ctx context.Context, w *workload, replicas *int32, resources map[string]scalingv1.ContainerResources) error {
+	if replicas == nil && resources == nil {
+		return nil
+	}
+
+	obj, err := workloadApplyConfiguration(w, replicas, resources)
+	if err != nil {
+		return err
+	}
+
+	return r.Patch(ctx, obj, client.Apply, client.FieldOwner(workloadFieldManager(resources)), client.ForceOwnership)
+}
+
+func workloadFieldManager(resources map[string]scalingv1.ContainerResources) string {
+	if resources == nil {
+		return replicasFieldManager
+	}
+
+	return fieldManager
+}
+
+// applyStatus writes the scaler's status using server-side apply
+func (r *HybridScalerReconciler) applyStatus(ctx context.Context, scaler *scalingv1.HybridScaler) error {
+	obj, err := statusApplyConfiguration(scaler)
+	if err != nil {
+		return err
+	}
+
+	return r.Status().Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+}
+
+// workloadApplyConfiguration creates the partial workload containing only the fields owned by the scaler,
+// containers missing from `resources` keep the resources of the pod template, which is omitted if `resources` is nil
+func workloadApplyConfiguration(w *workload, replicas *int32, resources map[string]scalingv1.ContainerResources) (*unstructured.Unstructured, error) {
+	spec := map[string]any{}
+
+	if replicas != nil {
+		spec["replicas"] = int64(*replicas)
+	}
+
+	if resources != nil {
+		containers := make([]any, 0)
+
+		for _, container := range w.template.Spec.Containers {
+			containerResources := corev1.ResourceRequirements{
+				Requests: container.Resources.Requests,
+				Limits:   container.Resources.Limits,
+			}
+
+			if r, ok := resources[container.Name]; ok {
+				containerResources.Requests = r.Requests
+				containerResources.Limits = r.Limits
+			}
+
+			resourcesUnstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&containerResources)
+			if err != nil {
+				return nil, err
+			}
+
+			containers = append(containers, map[string]any{
+				"name":      container.Name,
+				"resources": resourcesUnstructured,
+			})
+		}
+
+		spec["template"] = map[string]any{
+			"spec": map[string]any{
+				"containers": containers,
+			},
+		}
+	}
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]any{
+			"spec": spec,
+		},
+	}
+	obj.SetGroupVersionKind(w.groupVersionKind())
+	obj.SetNamespace(w.GetNamespace())
+	obj.SetName(w.GetName())
+
+	return obj, nil
+}
+
+func statusApplyConfiguration(scaler *scalingv1.HybridScaler) (*unstructured.Unstructured, error) {
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&scaler.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]any{
+			"status": status,
+		},
+	}
+	obj.SetGroupVersionKind(scalingv1.GroupVersion.WithKind("HybridScaler"))
+	obj.SetNamespace(scaler.Namespace)
+	obj.SetName(scaler.Name)
+
+	return obj, nil
+}
@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setCondition sets the condition and reports whether its status, reason or message changed
+func setCondition(conditions *[]metav1.Condition, condition metav1.Condition) bool {
+	existing := meta.FindStatusCondition(*conditions, condition.Type)
+	changed := existing == nil ||
+		existing.Status != condition.Status ||
+		existing.Reason != condition.Reason ||
+		existing.Message != condition.Message
+
+	meta.SetStatusCondition(conditions, condition)
+
+	return changed
+}
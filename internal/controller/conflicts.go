@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+const (
+	kindHybridScaler              = "HybridScaler"
+	kindHorizontalPodAutoscaler   = "HorizontalPodAutoscaler"
+	kindVerticalPodAutoscaler     = "VerticalPodAutoscaler"
+	kindVerticalPodAutoscalerList = "VerticalPodAutoscalerList"
+)
+
+var vpaListGVK = schema.GroupVersionKind{Group: "autoscaling.k8s.io", Version: "v1", Kind: kindVerticalPodAutoscalerList}
+
+// autoscalerRef references another autoscaler targeting the same workload
+type autoscalerRef struct {
+	kind, name string
+}
+
+func (a autoscalerRef) String() string {
+	return fmt.Sprintf("%s/%s", a.kind, a.name)
+}
+
+// checkConflicts updates the scaler's conflict condition, emits an event if it changed and
+// reports whether the scaler must not act because of conflicting autoscalers
+func (r *HybridScalerReconciler) checkConflicts(ctx context.Context, scaler *scalingv1.HybridScaler) (conflicted, changed bool, err error) {
+	autoscalers, err := r.findAutoscalers(ctx, scaler)
+	if err != nil {
+		return false, false, err
+	}
+
+	conflicts, tolerated := partitionAutoscalers(autoscalers, scaler.Spec.Coexistence)
+	conflicted = len(conflicts) > 0
+
+	condition := metav1.Condition{
+		Type:               scalingv1.ConditionTargetConflict,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoConflict",
+		Message:            "no other autoscaler targets the workload",
+		ObservedGeneration: scaler.Generation,
+	}
+	eventType := corev1.EventTypeNormal
+
+	switch {
+	case conflicted:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "MultipleAutoscalers"
+		condition.Message = fmt.Sprintf("scale target is also managed by %s, set a coexistence mode to share it", strings.Join(conflicts, ", "))
+		eventType = corev1.EventTypeWarning
+	case len(tolerated) > 0:
+		condition.Reason = "Coexisting"
+		condition.Message = fmt.Sprintf("sharing the scale target with %s in %s mode", strings.Join(tolerated, ", "), scaler.Spec.Coexistence)
+	}
+
+	changed = setCondition(&scaler.Status.Conditions, condition)
+	if changed && condition.Reason != "NoConflict" {
+		r.Recorder.Event(scaler, eventType, condition.Reason, condition.Message)
+	}
+
+	return conflicted, changed, nil
+}
+
+// findAutoscalers returns all other autoscalers targeting the same workload as the scaler, if several hybrid scalers
+// target the same workload only the oldest one is allowed to act
+func (r *HybridScalerReconciler) findAutoscalers(ctx context.Context, scaler *scalingv1.HybridScaler) ([]autoscalerRef, error) {
+	target := scaler.Spec.ScaleTargetRef
+	autoscalers := make([]autoscalerRef, 0)
+
+	var scalers scalingv1.HybridScalerList
+	if err := r.List(ctx, &scalers, client.InNamespace(scaler.Namespace), client.MatchingFields{scaleTargetKey: target.Name}); err != nil {
+		return nil, fmt.Errorf("cannot list hybrid scalers, %w", err)
+	}
+
+	for _, other := range scalers.Items {
+		if other.Spec.ScaleTargetRef.Kind == target.Kind && takesPrecedence(&other, scaler) {
+			autoscalers = append(autoscalers, autoscalerRef{kind: kindHybridScaler, name: other.Name})
+		}
+	}
+
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	if err := r.List(ctx, &hpas, client.InNamespace(scaler.Namespace)); err != nil {
+		return nil, fmt.Errorf("cannot list horizontal pod autoscalers, %w", err)
+	}
+
+	for _, hpa := range hpas.Items {
+		if hpa.Spec.ScaleTargetRef.Kind == target.Kind && hpa.Spec.ScaleTargetRef.Name == target.Name {
+			autoscalers = append(autoscalers, autoscalerRef{kind: kindHorizontalPodAutoscaler, name: hpa.Name})
+		}
+	}
+
+	vpas := &unstructured.UnstructuredList{}
+	vpas.SetGroupVersionKind(vpaListGVK)
+	if err := r.List(ctx, vpas, client.InNamespace(scaler.Namespace)); err != nil && !meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("cannot list vertical pod autoscalers, %w", err)
+	}
+
+	for _, vpa := range vpas.Items {
+		kind, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "kind")
+		name, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "name")
+		if kind == target.Kind && name == target.Name {
+			autoscalers = append(autoscalers, autoscalerRef{kind: kindVerticalPodAutoscaler, name: vpa.GetName()})
+		}
+	}
+
+	sort.Slice(autoscalers, func(i, j int) bool {
+		return autoscalers[i].String() < autoscalers[j].String()
+	})
+
+	return autoscalers, nil
+}
+
+// partitionAutoscalers splits the autoscalers into those the scaler conflicts with and those it can coexist with,
+// an HPA may own the replicas while the scaler only owns resources and a VPA may own resources while the scaler only owns replicas
+func partitionAutoscalers(autoscalers []autoscalerRef, mode scalingv1.CoexistenceMode) (conflicts, tolerated []string) {
+	conflicts = make([]string, 0)
+	tolerated = make([]string, 0)
+
+	for _, autoscaler := range autoscalers {
+		switch {
+		case autoscaler.kind == kindHorizontalPodAutoscaler && mode == scalingv1.CoexistenceModeResourcesOnly,
+			autoscaler.kind == kindVerticalPodAutoscaler && mode == scalingv1.CoexistenceModeReplicasOnly:
+			tolerated = append(tolerated, autoscaler.String())
+		default:
+			conflicts = append(conflicts, autoscaler.String())
+		}
+	}
+
+	return conflicts, tolerated
+}
+
+// takesPrecedence reports whether scaler `a` was created before `b`, using the name to break ties
+func takesPrecedence(a, b *scalingv1.HybridScaler) bool {
+	if a.Namespace == b.Namespace && a.Name == b.Name {
+		return false
+	}
+
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+
+	return a.Name < b.Name
+}
@@ -1,15 +1,46 @@
 package controller
 
 import (
+	"context"
+	"fmt"
 	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+	"github.com/iljarotar/hybrid-scaler/internal/classic"
+	"github.com/iljarotar/hybrid-scaler/internal/recommender"
+	"github.com/iljarotar/hybrid-scaler/internal/reinforcement"
 	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+	"github.com/prometheus/common/model"
 	"gopkg.in/inf.v0"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/managedfields"
+	"k8s.io/apimachinery/pkg/util/managedfields/managedfieldstest"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/yaml"
 )
 
 func Test_getContainerResources(t *testing.T) {
@@ -84,6 +115,7 @@ func Test_getContainerResources(t *testing.T) {
 func Test_prepareState(t *testing.T) {
 	minReplicas := int32(1)
 	maxReplicas := int32(5)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name    string
@@ -141,8 +173,8 @@ func Test_prepareState(t *testing.T) {
 						corev1.ResourceCPU:    50,
 						corev1.ResourceMemory: 80,
 					},
-					LimitsToRequestsRatioCPU:    resource.MustParse("2"),
-					LimitsToRequestsRatioMemory: resource.MustParse("2"),
+					LimitsToRequestsRatioCPU:    ptr.To(resource.MustParse("2")),
+					LimitsToRequestsRatioMemory: ptr.To(resource.MustParse("2")),
 				},
 			},
 			want: &strategy.State{
@@ -203,13 +235,19 @@ func Test_prepareState(t *testing.T) {
 					CPU:    inf.NewDec(50, 2),
 					Memory: inf.NewDec(80, 2),
 				},
+				Time: now,
+				VerticalPolicy: strategy.VerticalPolicy{
+					OOMBumpFactor:        inf.NewDec(12, 1),
+					ThrottlingThreshold:  inf.NewDec(1, 1),
+					ThrottlingBumpFactor: inf.NewDec(12, 1),
+				},
 			},
 			wantErr: false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := prepareState(tt.status, tt.spec)
+			got, err := prepareState(tt.status, tt.spec, now)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("prepareState() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -221,10 +259,65 @@ func Test_prepareState(t *testing.T) {
 	}
 }
 
+func Test_prepareState_timeZone(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		timeZone     *string
+		wantLocation string
+		wantHour     int
+		wantErr      bool
+	}{
+		{
+			name:         "defaults to utc",
+			wantLocation: "UTC",
+			wantHour:     12,
+		},
+		{
+			name:         "time zone of the q-learning params",
+			timeZone:     ptr.To("Europe/Berlin"),
+			wantLocation: "Europe/Berlin",
+			wantHour:     13,
+		},
+		{
+			name:     "unknown time zone",
+			timeZone: ptr.To("Mars/Olympus_Mons"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := scalingv1.HybridScalerSpec{
+				ResourcePolicy: scalingv1.ResourcePolicy{
+					TargetUtilization: map[corev1.ResourceName]int32{
+						corev1.ResourceCPU:    50,
+						corev1.ResourceMemory: 80,
+					},
+				},
+				QLearningParams: scalingv1.QLearningParams{TimeZone: tt.timeZone},
+			}
+
+			got, err := prepareState(scalingv1.HybridScalerStatus{}, spec, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("prepareState() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if location := got.Time.Location().String(); location != tt.wantLocation || got.Time.Hour() != tt.wantHour {
+				t.Errorf("prepareState() time = %v in %s, want hour %d in %s", got.Time, location, tt.wantHour, tt.wantLocation)
+			}
+		})
+	}
+}
+
 func Test_interpretResourceScaling(t *testing.T) {
 	tests := []struct {
 		name     string
 		decision *strategy.ScalingDecision
+		policy   scalingv1.ResourcePolicy
 		want     map[string]scalingv1.ContainerResources
 	}{
 		{
@@ -276,10 +369,45 @@ func Test_interpretResourceScaling(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "round to granularities",
+			decision: &strategy.ScalingDecision{
+				ContainerResources: strategy.ContainerResources{
+					"container1": {
+						Requests: strategy.ResourcesList{
+							CPU:    inf.NewDec(101, 3),
+							Memory: inf.NewDec(104857601, 0),
+						},
+						Limits: strategy.ResourcesList{
+							CPU:    inf.NewDec(209, 3),
+							Memory: inf.NewDec(209715201, 0),
+						},
+					},
+				},
+			},
+			policy: scalingv1.ResourcePolicy{
+				Rounding: map[corev1.ResourceName]scalingv1.RoundingPolicy{
+					corev1.ResourceCPU:    {Granularity: ptr.To(resource.MustParse("10m")), Direction: scalingv1.RoundingDirectionDown},
+					corev1.ResourceMemory: {Granularity: ptr.To(resource.MustParse("16Mi")), Direction: scalingv1.RoundingDirectionUp},
+				},
+			},
+			want: map[string]scalingv1.ContainerResources{
+				"container1": {
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("112Mi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("200m"),
+						corev1.ResourceMemory: resource.MustParse("208Mi"),
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := interpretResourceScaling(tt.decision)
+			got := interpretResourceScaling(tt.decision, tt.policy)
 			if diff := cmp.Diff(tt.want, got); diff != "" {
 				t.Errorf("interpretResourceScaling() %v", diff)
 			}
@@ -329,18 +457,3014 @@ func Test_float64ToDec(t *testing.T) {
 	}
 }
 
-func decComparer(a, b *inf.Dec) bool {
-	if a == nil && b != nil {
-		return false
+func Test_rolloutInProgress(t *testing.T) {
+	replicas := int32(3)
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		want       bool
+	}{
+		{
+			name: "rollout finished",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           3,
+					UpdatedReplicas:    3,
+					AvailableReplicas:  3,
+				},
+			},
+			want: false,
+		},
+		{
+			name: "new generation not yet observed",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           3,
+					UpdatedReplicas:    3,
+					AvailableReplicas:  3,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "not all replicas updated",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           4,
+					UpdatedReplicas:    2,
+					AvailableReplicas:  3,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "old pods still terminating",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           4,
+					UpdatedReplicas:    3,
+					AvailableReplicas:  3,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "updated pods not yet available",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           3,
+					UpdatedReplicas:    3,
+					AvailableReplicas:  1,
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rolloutInProgress(tt.deployment); got != tt.want {
+				t.Errorf("rolloutInProgress() = %v, want %v", got, tt.want)
+			}
+		})
 	}
+}
 
-	if b == nil && a != nil {
-		return false
+func Test_remainingWarmup(t *testing.T) {
+	now := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	warmup := int32(60)
+
+	tests := []struct {
+		name   string
+		status scalingv1.HybridScalerStatus
+		spec   scalingv1.HybridScalerSpec
+		want   time.Duration
+	}{
+		{
+			name:   "no rollout observed yet",
+			status: scalingv1.HybridScalerStatus{},
+			spec:   scalingv1.HybridScalerSpec{WarmupPeriod: &warmup},
+			want:   0,
+		},
+		{
+			name:   "no warm-up configured",
+			status: scalingv1.HybridScalerStatus{LastRolloutTime: &metav1.Time{Time: now}},
+			spec:   scalingv1.HybridScalerSpec{},
+			want:   0,
+		},
+		{
+			name:   "warm-up in progress",
+			status: scalingv1.HybridScalerStatus{LastRolloutTime: &metav1.Time{Time: now.Add(-20 * time.Second)}},
+			spec:   scalingv1.HybridScalerSpec{WarmupPeriod: &warmup},
+			want:   40 * time.Second,
+		},
+		{
+			name:   "warm-up over",
+			status: scalingv1.HybridScalerStatus{LastRolloutTime: &metav1.Time{Time: now.Add(-60 * time.Second)}},
+			spec:   scalingv1.HybridScalerSpec{WarmupPeriod: &warmup},
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := remainingWarmup(tt.status, tt.spec, now); got != tt.want {
+				t.Errorf("remainingWarmup() = %v, want %v", got, tt.want)
+			}
+		})
 	}
+}
 
-	if a == nil && b == nil {
-		return true
+func Test_templateResourcesChanged(t *testing.T) {
+	containers := []corev1.Container{
+		{
+			Name: "container1",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("200m"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+		},
 	}
 
-	return a.Cmp(b) == 0
+	tests := []struct {
+		name         string
+		newResources map[string]scalingv1.ContainerResources
+		want         bool
+	}{
+		{
+			name: "equal quantities in different formats",
+			newResources: map[string]scalingv1.ContainerResources{
+				"container1": {
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("0.1"),
+						corev1.ResourceMemory: resource.MustParse("1073741824"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("0.2"),
+						corev1.ResourceMemory: resource.MustParse("2Gi"),
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "requests changed",
+			newResources: map[string]scalingv1.ContainerResources{
+				"container1": {
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("150m"),
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("200m"),
+						corev1.ResourceMemory: resource.MustParse("2Gi"),
+					},
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := templateResourcesChanged(containers, tt.newResources); got != tt.want {
+				t.Errorf("templateResourcesChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resizePatch(t *testing.T) {
+	containers := []corev1.Container{
+		{
+			Name: "container1",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("200m"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+		},
+		{
+			Name: "container2",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("200m"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		newResources map[string]scalingv1.ContainerResources
+		want         string
+		wantOk       bool
+	}{
+		{
+			name: "nothing to resize",
+			newResources: map[string]scalingv1.ContainerResources{
+				"container1": {
+					Requests: containers[0].Resources.Requests,
+					Limits:   containers[0].Resources.Limits,
+				},
+			},
+			wantOk: false,
+		},
+		{
+			name: "only changed containers are patched",
+			newResources: map[string]scalingv1.ContainerResources{
+				"container1": {
+					Requests: containers[0].Resources.Requests,
+					Limits:   containers[0].Resources.Limits,
+				},
+				"container2": {
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("150m"),
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("300m"),
+						corev1.ResourceMemory: resource.MustParse("2Gi"),
+					},
+				},
+			},
+			want:   `{"spec":{"containers":[{"name":"container2","resources":{"limits":{"cpu":"300m","memory":"2Gi"},"requests":{"cpu":"150m","memory":"1Gi"}}}]}}`,
+			wantOk: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := resizePatch(containers, tt.newResources)
+			if err != nil {
+				t.Errorf("resizePatch() error = %v", err)
+				return
+			}
+			if ok != tt.wantOk {
+				t.Errorf("resizePatch() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if diff := cmp.Diff(tt.want, string(got)); diff != "" {
+				t.Errorf("resizePatch() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_resizeInProgress(t *testing.T) {
+	tests := []struct {
+		name string
+		pods []corev1.Pod
+		want bool
+	}{
+		{
+			name: "no resize",
+			pods: []corev1.Pod{{}},
+			want: false,
+		},
+		{
+			name: "resize in progress",
+			pods: []corev1.Pod{{}, {Status: corev1.PodStatus{Resize: corev1.PodResizeStatusInProgress}}},
+			want: true,
+		},
+		{
+			name: "resize in progress condition",
+			pods: []corev1.Pod{{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: podResizeInProgress, Status: corev1.ConditionTrue}}}}},
+			want: true,
+		},
+		{
+			name: "infeasible resize is not in progress",
+			pods: []corev1.Pod{{Status: corev1.PodStatus{Resize: corev1.PodResizeStatusInfeasible}}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resizeInProgress(tt.pods); got != tt.want {
+				t.Errorf("resizeInProgress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_currentContainerResources(t *testing.T) {
+	containers := []corev1.Container{
+		{
+			Name: "container1",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")},
+			},
+		},
+	}
+	templateResources := map[string]scalingv1.ContainerResources{
+		"container1": {
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")},
+		},
+	}
+	appliedResources := map[string]scalingv1.ContainerResources{
+		"container1": {
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")},
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("600m")},
+		},
+	}
+
+	deployment := &workload{kind: kindDeployment, template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}, replacesPods: true}
+	onDeleteStatefulSet := &workload{kind: kindStatefulSet, template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}}
+
+	tests := []struct {
+		name   string
+		scaler scalingv1.HybridScaler
+		target *workload
+		want   map[string]scalingv1.ContainerResources
+	}{
+		{
+			name: "recreate uses the template",
+			scaler: scalingv1.HybridScaler{
+				Spec:   scalingv1.HybridScalerSpec{UpdateMode: scalingv1.UpdateModeRecreate},
+				Status: scalingv1.HybridScalerStatus{ContainerResources: appliedResources},
+			},
+			target: deployment,
+			want:   templateResources,
+		},
+		{
+			name: "in place keeps applied resources",
+			scaler: scalingv1.HybridScaler{
+				Spec:   scalingv1.HybridScalerSpec{UpdateMode: scalingv1.UpdateModeInPlace},
+				Status: scalingv1.HybridScalerStatus{ContainerResources: appliedResources},
+			},
+			target: deployment,
+			want:   appliedResources,
+		},
+		{
+			name: "in place with a template which is updated as well uses the template",
+			scaler: scalingv1.HybridScaler{
+				Spec:   scalingv1.HybridScalerSpec{UpdateMode: scalingv1.UpdateModeInPlace},
+				Status: scalingv1.HybridScalerStatus{ContainerResources: appliedResources},
+			},
+			target: onDeleteStatefulSet,
+			want:   templateResources,
+		},
+		{
+			name: "in place without applied resources uses the template",
+			scaler: scalingv1.HybridScaler{
+				Spec: scalingv1.HybridScalerSpec{UpdateMode: scalingv1.UpdateModeInPlaceOrRecreate},
+			},
+			target: deployment,
+			want:   templateResources,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := currentContainerResources(tt.scaler, tt.target)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("currentContainerResources() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_workloadApplyConfiguration(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "namespace"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "container1",
+							Image: "image",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+								Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	target := &workload{
+		Object:   deployment,
+		kind:     kindDeployment,
+		template: deployment.Spec.Template,
+	}
+
+	tests := []struct {
+		name      string
+		replicas  *int32
+		resources map[string]scalingv1.ContainerResources
+		want      map[string]any
+	}{
+		{
+			name:     "only replicas and resources are applied",
+			replicas: ptr.To(int32(3)),
+			resources: map[string]scalingv1.ContainerResources{
+				"container1": {
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("150m")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")},
+				},
+			},
+			want: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]any{
+					"name":      "deployment",
+					"namespace": "namespace",
+				},
+				"spec": map[string]any{
+					"replicas": int64(3),
+					"template": map[string]any{
+						"spec": map[string]any{
+							"containers": []any{
+								map[string]any{
+									"name": "container1",
+									"resources": map[string]any{
+										"requests": map[string]any{"cpu": "150m"},
+										"limits":   map[string]any{"cpu": "300m"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "replicas only leave out the pod template",
+			replicas: ptr.To(int32(2)),
+			want: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]any{
+					"name":      "deployment",
+					"namespace": "namespace",
+				},
+				"spec": map[string]any{
+					"replicas": int64(2),
+				},
+			},
+		},
+		{
+			name:      "resources of the template are kept and replicas left out",
+			resources: map[string]scalingv1.ContainerResources{},
+			want: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]any{
+					"name":      "deployment",
+					"namespace": "namespace",
+				},
+				"spec": map[string]any{
+					"template": map[string]any{
+						"spec": map[string]any{
+							"containers": []any{
+								map[string]any{
+									"name": "container1",
+									"resources": map[string]any{
+										"requests": map[string]any{"cpu": "100m"},
+										"limits":   map[string]any{"cpu": "200m"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := workloadApplyConfiguration(target, tt.replicas, tt.resources)
+			if err != nil {
+				t.Errorf("workloadApplyConfiguration() error = %v", err)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got.Object); diff != "" {
+				t.Errorf("workloadApplyConfiguration() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_workloadApplyConfiguration_managedFields(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: kindDeployment},
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "namespace"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(1)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "container1",
+							Image: "image",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	target := &workload{Object: deployment, kind: kindDeployment, template: deployment.Spec.Template}
+	resources := map[string]scalingv1.ContainerResources{
+		"container1": {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("150m")}},
+	}
+
+	tests := []struct {
+		name string
+		// resources are applied before the replicas if set
+		resources  map[string]scalingv1.ContainerResources
+		wantOwners []string
+		wantCPU    string
+	}{
+		{
+			name:       "replicas only leave the containers to their manager",
+			wantOwners: []string{"gitops"},
+			wantCPU:    "100m",
+		},
+		{
+			name:       "replicas only keep the applied resources",
+			resources:  resources,
+			wantOwners: []string{fieldManager},
+			wantCPU:    "150m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := managedfieldstest.NewTestFieldManager(managedfields.NewDeducedTypeConverter(), appsv1.SchemeGroupVersion.WithKind(kindDeployment))
+
+			initial, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+			if err != nil {
+				t.Errorf("cannot convert deployment, %v", err)
+				return
+			}
+			if err := manager.Apply(&unstructured.Unstructured{Object: initial}, "gitops", false); err != nil {
+				t.Errorf("cannot apply deployment, %v", err)
+				return
+			}
+
+			if tt.resources != nil {
+				obj, err := workloadApplyConfiguration(target, ptr.To(int32(2)), tt.resources)
+				if err != nil {
+					t.Errorf("workloadApplyConfiguration() error = %v", err)
+					return
+				}
+				if err := manager.Apply(obj, workloadFieldManager(tt.resources), true); err != nil {
+					t.Errorf("cannot apply resources, %v", err)
+					return
+				}
+			}
+
+			obj, err := workloadApplyConfiguration(target, ptr.To(int32(3)), nil)
+			if err != nil {
+				t.Errorf("workloadApplyConfiguration() error = %v", err)
+				return
+			}
+			if err := manager.Apply(obj, workloadFieldManager(nil), true); err != nil {
+				t.Errorf("cannot apply replicas, %v", err)
+				return
+			}
+
+			var owners []string
+			for _, entry := range manager.ManagedFields() {
+				if strings.Contains(string(entry.FieldsV1.Raw), `"f:containers"`) {
+					owners = append(owners, entry.Manager)
+				}
+			}
+			if diff := cmp.Diff(tt.wantOwners, owners); diff != "" {
+				t.Errorf("owners of the containers %v", diff)
+			}
+
+			live, ok := manager.Live().(*unstructured.Unstructured)
+			if !ok {
+				t.Errorf("unexpected live object %T", manager.Live())
+				return
+			}
+			replicas, _, _ := unstructured.NestedInt64(live.Object, "spec", "replicas")
+			containers, _, _ := unstructured.NestedSlice(live.Object, "spec", "template", "spec", "containers")
+			var cpu string
+			if len(containers) == 1 {
+				cpu, _, _ = unstructured.NestedString(containers[0].(map[string]any), "resources", "requests", "cpu")
+			}
+			if diff := cmp.Diff([]any{int64(3), tt.wantCPU}, []any{replicas, cpu}); diff != "" {
+				t.Errorf("replicas and cpu requests %v", diff)
+			}
+		})
+	}
+}
+
+func Test_statusApplyConfiguration(t *testing.T) {
+	scaler := &scalingv1.HybridScaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "namespace", ResourceVersion: "1"},
+		Spec:       scalingv1.HybridScalerSpec{LearningType: scalingv1.LearningTypeQLearning},
+		Status:     scalingv1.HybridScalerStatus{Replicas: 2, RolloutPending: true},
+	}
+
+	got, err := statusApplyConfiguration(scaler)
+	if err != nil {
+		t.Errorf("statusApplyConfiguration() error = %v", err)
+		return
+	}
+
+	if _, ok := got.Object["spec"]; ok {
+		t.Errorf("statusApplyConfiguration() must not contain the spec")
+	}
+
+	if got.GetResourceVersion() != "" {
+		t.Errorf("statusApplyConfiguration() must not contain a resource version")
+	}
+
+	status, ok := got.Object["status"].(map[string]any)
+	if !ok {
+		t.Errorf("statusApplyConfiguration() status missing")
+		return
+	}
+
+	if status["replicas"] != int64(2) || status["rolloutPending"] != true {
+		t.Errorf("statusApplyConfiguration() unexpected status %v", status)
+	}
+}
+
+func Test_statefulSetRolloutInProgress(t *testing.T) {
+	replicas := int32(2)
+
+	tests := []struct {
+		name        string
+		statefulSet *appsv1.StatefulSet
+		want        bool
+	}{
+		{
+			name: "rollout finished",
+			statefulSet: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{
+					Replicas:          2,
+					UpdatedReplicas:   2,
+					AvailableReplicas: 2,
+					CurrentRevision:   "rev-2",
+					UpdateRevision:    "rev-2",
+				},
+			},
+			want: false,
+		},
+		{
+			name: "revisions differ",
+			statefulSet: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{
+					Replicas:          2,
+					UpdatedReplicas:   1,
+					AvailableReplicas: 2,
+					CurrentRevision:   "rev-1",
+					UpdateRevision:    "rev-2",
+				},
+			},
+			want: true,
+		},
+		{
+			name: "revisions of on delete strategy are ignored",
+			statefulSet: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas:       &replicas,
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+				},
+				Status: appsv1.StatefulSetStatus{
+					Replicas:          2,
+					UpdatedReplicas:   0,
+					AvailableReplicas: 2,
+					CurrentRevision:   "rev-1",
+					UpdateRevision:    "rev-2",
+				},
+			},
+			want: false,
+		},
+		{
+			name: "scaling up",
+			statefulSet: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{
+					Replicas:          1,
+					UpdatedReplicas:   1,
+					AvailableReplicas: 1,
+					CurrentRevision:   "rev-1",
+					UpdateRevision:    "rev-1",
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statefulSetRolloutInProgress(tt.statefulSet); got != tt.want {
+				t.Errorf("statefulSetRolloutInProgress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_untilNextDecision(t *testing.T) {
+	now := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	interval := 30 * time.Second
+
+	tests := []struct {
+		name   string
+		status scalingv1.HybridScalerStatus
+		want   time.Duration
+	}{
+		{
+			name:   "no decision made yet",
+			status: scalingv1.HybridScalerStatus{},
+			want:   0,
+		},
+		{
+			name:   "decision due",
+			status: scalingv1.HybridScalerStatus{LastScaleTime: &metav1.Time{Time: now.Add(-31 * time.Second)}},
+			want:   0,
+		},
+		{
+			name:   "decision not yet due",
+			status: scalingv1.HybridScalerStatus{LastScaleTime: &metav1.Time{Time: now.Add(-10 * time.Second)}},
+			want:   20 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := untilNextDecision(tt.status, interval, now); got != tt.want {
+				t.Errorf("untilNextDecision() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_setCondition(t *testing.T) {
+	conditions := []metav1.Condition{}
+	condition := metav1.Condition{
+		Type:    scalingv1.ConditionTargetConflict,
+		Status:  metav1.ConditionFalse,
+		Reason:  "NoConflict",
+		Message: "no other autoscaler targets the workload",
+	}
+
+	if !setCondition(&conditions, condition) {
+		t.Errorf("setCondition() adding a condition must report a change")
+	}
+
+	if setCondition(&conditions, condition) {
+		t.Errorf("setCondition() setting an equal condition must not report a change")
+	}
+
+	condition.Status = metav1.ConditionTrue
+	if !setCondition(&conditions, condition) {
+		t.Errorf("setCondition() changing the status must report a change")
+	}
+
+	if len(conditions) != 1 {
+		t.Errorf("setCondition() expected one condition, got %d", len(conditions))
+	}
+}
+
+func TestHybridScalerReconciler_findAutoscalers(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+	_ = scalingv1.AddToScheme(testScheme)
+
+	created := metav1.NewTime(time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC))
+	target := autoscalingv2.CrossVersionObjectReference{Kind: kindDeployment, Name: "app"}
+
+	scaler := &scalingv1.HybridScaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "default", CreationTimestamp: created},
+		Spec:       scalingv1.HybridScalerSpec{ScaleTargetRef: target},
+	}
+	olderScaler := &scalingv1.HybridScaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "older", Namespace: "default", CreationTimestamp: metav1.NewTime(created.Add(-time.Hour))},
+		Spec:       scalingv1.HybridScalerSpec{ScaleTargetRef: target},
+	}
+	newerScaler := &scalingv1.HybridScaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "newer", Namespace: "default", CreationTimestamp: metav1.NewTime(created.Add(time.Hour))},
+		Spec:       scalingv1.HybridScalerSpec{ScaleTargetRef: target},
+	}
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "hpa", Namespace: "default"},
+		Spec:       autoscalingv2.HorizontalPodAutoscalerSpec{ScaleTargetRef: target},
+	}
+	otherHpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Spec:       autoscalingv2.HorizontalPodAutoscalerSpec{ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: kindDeployment, Name: "other"}},
+	}
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		want    []autoscalerRef
+	}{
+		{
+			name:    "no other autoscalers",
+			objects: []client.Object{scaler, newerScaler, otherHpa},
+			want:    []autoscalerRef{},
+		},
+		{
+			name:    "older scaler and hpa",
+			objects: []client.Object{scaler, olderScaler, hpa},
+			want: []autoscalerRef{
+				{kind: kindHorizontalPodAutoscaler, name: "hpa"},
+				{kind: kindHybridScaler, name: "older"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &HybridScalerReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(tt.objects...).
+					WithIndex(&scalingv1.HybridScaler{}, scaleTargetKey, func(obj client.Object) []string {
+						return []string{obj.(*scalingv1.HybridScaler).Spec.ScaleTargetRef.Name}
+					}).
+					Build(),
+			}
+
+			got, err := r.findAutoscalers(context.Background(), scaler)
+			if err != nil {
+				t.Errorf("HybridScalerReconciler.findAutoscalers() error = %v", err)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(autoscalerRef{})); diff != "" {
+				t.Errorf("HybridScalerReconciler.findAutoscalers() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_partitionAutoscalers(t *testing.T) {
+	autoscalers := []autoscalerRef{
+		{kind: kindHorizontalPodAutoscaler, name: "hpa"},
+		{kind: kindHybridScaler, name: "scaler"},
+		{kind: kindVerticalPodAutoscaler, name: "vpa"},
+	}
+
+	tests := []struct {
+		name          string
+		mode          scalingv1.CoexistenceMode
+		wantConflicts []string
+		wantTolerated []string
+	}{
+		{
+			name:          "no coexistence",
+			mode:          scalingv1.CoexistenceModeNone,
+			wantConflicts: []string{"HorizontalPodAutoscaler/hpa", "HybridScaler/scaler", "VerticalPodAutoscaler/vpa"},
+			wantTolerated: []string{},
+		},
+		{
+			name:          "hpa owns replicas",
+			mode:          scalingv1.CoexistenceModeResourcesOnly,
+			wantConflicts: []string{"HybridScaler/scaler", "VerticalPodAutoscaler/vpa"},
+			wantTolerated: []string{"HorizontalPodAutoscaler/hpa"},
+		},
+		{
+			name:          "vpa owns resources",
+			mode:          scalingv1.CoexistenceModeReplicasOnly,
+			wantConflicts: []string{"HorizontalPodAutoscaler/hpa", "HybridScaler/scaler"},
+			wantTolerated: []string{"VerticalPodAutoscaler/vpa"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts, tolerated := partitionAutoscalers(autoscalers, tt.mode)
+			if diff := cmp.Diff(tt.wantConflicts, conflicts); diff != "" {
+				t.Errorf("partitionAutoscalers() conflicts %v", diff)
+			}
+			if diff := cmp.Diff(tt.wantTolerated, tolerated); diff != "" {
+				t.Errorf("partitionAutoscalers() tolerated %v", diff)
+			}
+		})
+	}
+}
+
+func Test_enforceFixedConstraints(t *testing.T) {
+	currentResources := strategy.ContainerResources{
+		"container1": {
+			Requests: strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(1, 0)},
+			Limits:   strategy.ResourcesList{CPU: inf.NewDec(2, 0), Memory: inf.NewDec(2, 0)},
+		},
+	}
+	newResources := strategy.ContainerResources{
+		"container1": {
+			Requests: strategy.ResourcesList{CPU: inf.NewDec(3, 0), Memory: inf.NewDec(3, 0)},
+			Limits:   strategy.ResourcesList{CPU: inf.NewDec(6, 0), Memory: inf.NewDec(6, 0)},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		constraints strategy.Constraints
+		want        *strategy.ScalingDecision
+	}{
+		{
+			name: "nothing fixed",
+			want: &strategy.ScalingDecision{Replicas: 5, ContainerResources: newResources},
+		},
+		{
+			name:        "replicas fixed",
+			constraints: strategy.Constraints{FixedReplicas: true},
+			want:        &strategy.ScalingDecision{Replicas: 2, ContainerResources: newResources},
+		},
+		{
+			name:        "resources fixed",
+			constraints: strategy.Constraints{FixedResources: true},
+			want:        &strategy.ScalingDecision{Replicas: 5, ContainerResources: currentResources},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &strategy.State{Replicas: 2, ContainerResources: currentResources, Constraints: tt.constraints}
+			decision := &strategy.ScalingDecision{Replicas: 5, ContainerResources: newResources}
+
+			enforceFixedConstraints(decision, state)
+			if diff := cmp.Diff(tt.want, decision, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("enforceFixedConstraints() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_scaleToZeroReplicas(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	scaleToZero := &scalingv1.ScaleToZero{IdlePeriod: 600, ActivationReplicas: ptr.To(int32(2))}
+
+	tests := []struct {
+		name         string
+		spec         scalingv1.HybridScalerSpec
+		status       scalingv1.HybridScalerStatus
+		active       bool
+		wantReplicas int32
+		wantOk       bool
+	}{
+		{
+			name:   "scale to zero disabled",
+			spec:   scalingv1.HybridScalerSpec{MinReplicas: ptr.To(int32(0))},
+			status: scalingv1.HybridScalerStatus{Replicas: 0},
+			wantOk: false,
+		},
+		{
+			name:   "stay at zero while idle",
+			spec:   scalingv1.HybridScalerSpec{MinReplicas: ptr.To(int32(0)), ScaleToZero: scaleToZero},
+			status: scalingv1.HybridScalerStatus{Replicas: 0},
+			wantOk: true,
+		},
+		{
+			name:         "wake up from zero",
+			spec:         scalingv1.HybridScalerSpec{MinReplicas: ptr.To(int32(0)), ScaleToZero: scaleToZero},
+			status:       scalingv1.HybridScalerStatus{Replicas: 0},
+			active:       true,
+			wantReplicas: 2,
+			wantOk:       true,
+		},
+		{
+			name:   "active target is scaled by the strategy",
+			spec:   scalingv1.HybridScalerSpec{MinReplicas: ptr.To(int32(0)), ScaleToZero: scaleToZero},
+			status: scalingv1.HybridScalerStatus{Replicas: 3, LastActiveTime: &metav1.Time{Time: now}},
+			active: true,
+			wantOk: false,
+		},
+		{
+			name:   "idle period not over",
+			spec:   scalingv1.HybridScalerSpec{MinReplicas: ptr.To(int32(0)), ScaleToZero: scaleToZero},
+			status: scalingv1.HybridScalerStatus{Replicas: 3, LastActiveTime: &metav1.Time{Time: now.Add(-5 * time.Minute)}},
+			wantOk: false,
+		},
+		{
+			name:   "idle period over",
+			spec:   scalingv1.HybridScalerSpec{MinReplicas: ptr.To(int32(0)), ScaleToZero: scaleToZero},
+			status: scalingv1.HybridScalerStatus{Replicas: 3, LastActiveTime: &metav1.Time{Time: now.Add(-10 * time.Minute)}},
+			wantOk: true,
+		},
+		{
+			name:   "min replicas prevent scaling to zero",
+			spec:   scalingv1.HybridScalerSpec{MinReplicas: ptr.To(int32(1)), ScaleToZero: scaleToZero},
+			status: scalingv1.HybridScalerStatus{Replicas: 3, LastActiveTime: &metav1.Time{Time: now.Add(-10 * time.Minute)}},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotReplicas, gotOk := scaleToZeroReplicas(tt.spec, tt.status, tt.active, now)
+			if gotReplicas != tt.wantReplicas || gotOk != tt.wantOk {
+				t.Errorf("scaleToZeroReplicas() = %v, %v, want %v, %v", gotReplicas, gotOk, tt.wantReplicas, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_activationReplicas(t *testing.T) {
+	tests := []struct {
+		name string
+		spec scalingv1.HybridScalerSpec
+		want int32
+	}{
+		{
+			name: "defaults to one",
+			spec: scalingv1.HybridScalerSpec{ScaleToZero: &scalingv1.ScaleToZero{}},
+			want: 1,
+		},
+		{
+			name: "configured replicas",
+			spec: scalingv1.HybridScalerSpec{MaxReplicas: ptr.To(int32(10)), ScaleToZero: &scalingv1.ScaleToZero{ActivationReplicas: ptr.To(int32(3))}},
+			want: 3,
+		},
+		{
+			name: "limited to max replicas",
+			spec: scalingv1.HybridScalerSpec{MaxReplicas: ptr.To(int32(2)), ScaleToZero: &scalingv1.ScaleToZero{ActivationReplicas: ptr.To(int32(3))}},
+			want: 2,
+		},
+		{
+			name: "at least min replicas",
+			spec: scalingv1.HybridScalerSpec{MinReplicas: ptr.To(int32(4)), ScaleToZero: &scalingv1.ScaleToZero{ActivationReplicas: ptr.To(int32(3))}},
+			want: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := activationReplicas(tt.spec); got != tt.want {
+				t.Errorf("activationReplicas() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_activeProfile(t *testing.T) {
+	// 2024-01-01 is a Monday
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	business := scalingv1.ScalingProfile{Name: "business", Schedule: "0 8 * * 1-5", Duration: 12 * 3600, TimeZone: "Europe/Berlin"}
+	night := scalingv1.ScalingProfile{Name: "night", Schedule: "0 22 * * *", Duration: 8 * 3600}
+	always := scalingv1.ScalingProfile{Name: "always", Schedule: "* * * * *", Duration: 60}
+
+	tests := []struct {
+		name     string
+		profiles []scalingv1.ScalingProfile
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "no profiles",
+			want: "",
+		},
+		{
+			name:     "no active profile",
+			profiles: []scalingv1.ScalingProfile{night},
+			want:     "",
+		},
+		{
+			name:     "active profile",
+			profiles: []scalingv1.ScalingProfile{night, business},
+			want:     "business",
+		},
+		{
+			name:     "first active profile wins",
+			profiles: []scalingv1.ScalingProfile{business, always},
+			want:     "business",
+		},
+		{
+			name:     "invalid schedule",
+			profiles: []scalingv1.ScalingProfile{{Name: "invalid", Schedule: "0 8 * *", Duration: 60}},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid time zone",
+			profiles: []scalingv1.ScalingProfile{{Name: "invalid", Schedule: "* * * * *", Duration: 60, TimeZone: "Nowhere/Somewhere"}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := activeProfile(tt.profiles, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("activeProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			name := ""
+			if got != nil {
+				name = got.Name
+			}
+
+			if name != tt.want {
+				t.Errorf("activeProfile() = %v, want %v", name, tt.want)
+			}
+		})
+	}
+}
+
+func Test_withProfile(t *testing.T) {
+	spec := scalingv1.HybridScalerSpec{
+		MinReplicas: ptr.To(int32(1)),
+		MaxReplicas: ptr.To(int32(5)),
+		ResourcePolicy: scalingv1.ResourcePolicy{
+			MinAllowed: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("100M"),
+			},
+			MaxAllowed: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1G"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		profile *scalingv1.ScalingProfile
+		want    scalingv1.HybridScalerSpec
+	}{
+		{
+			name: "no profile",
+			want: spec,
+		},
+		{
+			name: "override replicas and some resources",
+			profile: &scalingv1.ScalingProfile{
+				MinReplicas: ptr.To(int32(5)),
+				MaxReplicas: ptr.To(int32(10)),
+				MinAllowed: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("500m"),
+				},
+			},
+			want: scalingv1.HybridScalerSpec{
+				MinReplicas: ptr.To(int32(5)),
+				MaxReplicas: ptr.To(int32(10)),
+				ResourcePolicy: scalingv1.ResourcePolicy{
+					MinAllowed: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("100M"),
+					},
+					MaxAllowed: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("1"),
+						corev1.ResourceMemory: resource.MustParse("1G"),
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withProfile(spec, tt.profile)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("withProfile() %v", diff)
+			}
+		})
+	}
+
+	if cpu := spec.ResourcePolicy.MinAllowed[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("100m")) != 0 {
+		t.Errorf("withProfile() modified the original spec")
+	}
+}
+
+func Test_resampleSeries(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	step := 5 * time.Minute
+
+	sample := func(offset time.Duration, value float64) model.SamplePair {
+		return model.SamplePair{Timestamp: model.TimeFromUnixNano(start.Add(offset).UnixNano()), Value: model.SampleValue(value)}
+	}
+
+	tests := []struct {
+		name    string
+		samples []model.SamplePair
+		points  int
+		want    []float64
+	}{
+		{
+			name:    "complete series",
+			samples: []model.SamplePair{sample(0, 1), sample(step, 2), sample(2*step, 3)},
+			points:  3,
+			want:    []float64{1, 2, 3},
+		},
+		{
+			name:    "fill gaps with previous and leading gaps with first value",
+			samples: []model.SamplePair{sample(step, 2), sample(3*step, 4)},
+			points:  5,
+			want:    []float64{2, 2, 2, 4, 4},
+		},
+		{
+			name:    "samples outside the grid are dropped",
+			samples: []model.SamplePair{sample(-step, 9), sample(0, 1), sample(2*step, 9)},
+			points:  2,
+			want:    []float64{1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resampleSeries(tt.samples, start, step, tt.points)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("resampleSeries() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_forecastHorizon(t *testing.T) {
+	tests := []struct {
+		name  string
+		ahead time.Duration
+		step  time.Duration
+		want  int
+	}{
+		{
+			name:  "multiple of step",
+			ahead: 10 * time.Minute,
+			step:  5 * time.Minute,
+			want:  2,
+		},
+		{
+			name:  "round up",
+			ahead: 11 * time.Minute,
+			step:  5 * time.Minute,
+			want:  3,
+		},
+		{
+			name:  "at least one step",
+			ahead: 0,
+			step:  5 * time.Minute,
+			want:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := forecastHorizon(tt.ahead, tt.step); got != tt.want {
+				t.Errorf("forecastHorizon() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_podSelector(t *testing.T) {
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "app-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "app.2"}},
+	}
+
+	want := `namespace="namespace",pod=~"app-1|app\\.2",container!=""`
+	if got := podSelector("namespace", pods); got != want {
+		t.Errorf("podSelector() = %v, want %v", got, want)
+	}
+}
+
+func Test_workloadPodPattern(t *testing.T) {
+	deployment := &workload{Object: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api"}}, kind: kindDeployment}
+	statefulSet := &workload{Object: &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db.primary"}}, kind: kindStatefulSet}
+
+	tests := []struct {
+		name   string
+		target *workload
+		pod    string
+		want   bool
+	}{
+		{
+			name:   "deployment pod",
+			target: deployment,
+			pod:    "api-7d9f6b5c48-x2kqz",
+			want:   true,
+		},
+		{
+			name:   "pod of deployment sharing the prefix",
+			target: deployment,
+			pod:    "api-gateway-7d9f6b5c48-x2kqz",
+			want:   false,
+		},
+		{
+			name:   "pod of stateful set sharing the prefix",
+			target: deployment,
+			pod:    "api-gateway-0",
+			want:   false,
+		},
+		{
+			name:   "stateful set pod",
+			target: statefulSet,
+			pod:    "db.primary-12",
+			want:   true,
+		},
+		{
+			name:   "name is not a pattern",
+			target: statefulSet,
+			pod:    "db-primary-0",
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// prometheus anchors regular expressions on both ends
+			got, err := regexp.MatchString("^(?:"+workloadPodPattern(tt.target)+")$", tt.pod)
+			if err != nil {
+				t.Errorf("workloadPodPattern() error = %v", err)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("workloadPodPattern() matches %s = %v, want %v", tt.pod, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_usageRecommendations(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	rec := recommender.New()
+	rec.AddCPUSample("container1", 0.5, now, time.Hour)
+	rec.AddMemorySample("container1", 1e9, now, time.Hour)
+	rec.AddCPUSample("removed", 0.5, now, time.Hour)
+	rec.AddMemorySample("removed", 1e9, now, time.Hour)
+
+	encoded, err := rec.Encode()
+	if err != nil {
+		t.Errorf("Recommender.Encode() error = %v", err)
+		return
+	}
+
+	status := scalingv1.HybridScalerStatus{
+		RecommenderState: encoded,
+		ContainerResources: map[string]scalingv1.ContainerResources{
+			"container1": {},
+			"container2": {},
+		},
+	}
+
+	got, err := usageRecommendations(status, scalingv1.RecommenderPolicy{}, now)
+	if err != nil {
+		t.Errorf("usageRecommendations() error = %v", err)
+		return
+	}
+
+	if len(got) != 1 {
+		t.Errorf("usageRecommendations() = %v, want a recommendation for container1 only", got)
+		return
+	}
+
+	recommendation, ok := got["container1"]
+	if !ok {
+		t.Errorf("usageRecommendations() = %v, want a recommendation for container1", got)
+		return
+	}
+
+	if recommendation.Target.CPU.Cmp(inf.NewDec(5, 1)) < 0 || recommendation.Target.Memory.Cmp(inf.NewDec(1, -9)) < 0 {
+		t.Errorf("usageRecommendations() target %v is below the recorded usage", recommendation.Target)
+	}
+}
+
+func decComparer(a, b *inf.Dec) bool {
+	if a == nil && b != nil {
+		return false
+	}
+
+	if b == nil && a != nil {
+		return false
+	}
+
+	if a == nil && b == nil {
+		return true
+	}
+
+	return a.Cmp(b) == 0
+}
+
+func Test_oomKills(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	oomKilled := func(finishedAt time.Time) *corev1.ContainerStateTerminated {
+		return &corev1.ContainerStateTerminated{Reason: reasonOOMKilled, FinishedAt: metav1.Time{Time: finishedAt}}
+	}
+	pods := []corev1.Pod{
+		{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "a", LastTerminationState: corev1.ContainerState{Terminated: oomKilled(now.Add(-time.Minute))}},
+			{Name: "b", LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", FinishedAt: metav1.Time{Time: now}}}},
+		}}},
+		{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "a", State: corev1.ContainerState{Terminated: oomKilled(now.Add(-time.Hour))}},
+			{Name: "b"},
+		}}},
+	}
+
+	tests := []struct {
+		name  string
+		since *metav1.Time
+		want  map[string]int32
+	}{
+		{
+			name: "count all kills",
+			want: map[string]int32{"a": 2},
+		},
+		{
+			name:  "count kills since last scaling",
+			since: &metav1.Time{Time: now.Add(-10 * time.Minute)},
+			want:  map[string]int32{"a": 1},
+		},
+		{
+			name:  "no kills since last scaling",
+			since: &metav1.Time{Time: now},
+			want:  map[string]int32{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := oomKills(pods, tt.since)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("oomKills() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_containerPressure(t *testing.T) {
+	got := containerPressure(map[string]int32{"a": 2}, map[string]float64{"a": 0.25, "b": 0.5})
+	want := map[string]scalingv1.ContainerPressure{
+		"a": {OOMKills: 2, ThrottlingRatio: resource.NewDecimalQuantity(*inf.NewDec(25, 2), resource.DecimalExponent)},
+		"b": {ThrottlingRatio: resource.NewDecimalQuantity(*inf.NewDec(5, 1), resource.DecimalExponent)},
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b resource.Quantity) bool { return a.Cmp(b) == 0 })); diff != "" {
+		t.Errorf("containerPressure() %v", diff)
+	}
+}
+
+func TestHybridScalerReconciler_namespaceLimits(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "default"},
+		Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{
+			{
+				Type:                 corev1.LimitTypeContainer,
+				Min:                  corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+				Max:                  corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("1G")},
+				MaxLimitRequestRatio: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			},
+			{
+				Type: corev1.LimitTypePod,
+				Max:  corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2G")},
+			},
+		}},
+	}
+	requestsQuota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "requests", Namespace: "default"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4")},
+			Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("3")},
+		},
+	}
+	podsQuota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "pods", Namespace: "default"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3"), corev1.ResourcePods: resource.MustParse("10")},
+			Used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourcePods: resource.MustParse("5")},
+		},
+	}
+	scopedQuota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "scoped", Namespace: "default"},
+		Spec:       corev1.ResourceQuotaSpec{Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")},
+		},
+	}
+
+	pod := func(phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			}}},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	pods := []corev1.Pod{pod(corev1.PodRunning), pod(corev1.PodPending), pod(corev1.PodFailed)}
+
+	r := &HybridScalerReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(testScheme).
+			WithObjects(limitRange, requestsQuota, podsQuota, scopedQuota).
+			Build(),
+	}
+
+	want := &namespaceLimits{
+		containerMin:         strategy.ResourcesList{CPU: inf.NewDec(50, 3)},
+		containerMax:         strategy.ResourcesList{CPU: inf.NewDec(2, 0), Memory: inf.NewDec(1, -9)},
+		maxLimitRequestRatio: strategy.ResourcesList{CPU: inf.NewDec(4, 0)},
+		podMax:               strategy.ResourcesList{Memory: inf.NewDec(2, -9)},
+		remainingQuota: map[corev1.ResourceName]*inf.Dec{
+			corev1.ResourceRequestsCPU: inf.NewDec(2, 0),
+			corev1.ResourcePods:        inf.NewDec(7, 0),
+		},
+	}
+
+	got, err := r.namespaceLimits(context.Background(), "default", pods)
+	if err != nil {
+		t.Errorf("HybridScalerReconciler.namespaceLimits() error = %v", err)
+		return
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(namespaceLimits{}), cmp.Comparer(decComparer)); diff != "" {
+		t.Errorf("HybridScalerReconciler.namespaceLimits() %v", diff)
+	}
+}
+
+func Test_clampToNamespace(t *testing.T) {
+	decision := func(replicas int32) *strategy.ScalingDecision {
+		return &strategy.ScalingDecision{
+			Replicas: replicas,
+			ContainerResources: strategy.ContainerResources{
+				"app": {
+					Requests: strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(100, 0)},
+					Limits:   strategy.ResourcesList{CPU: inf.NewDec(2, 0), Memory: inf.NewDec(200, 0)},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		decision      *strategy.ScalingDecision
+		limits        *namespaceLimits
+		fixedReplicas bool
+		want          *strategy.ScalingDecision
+		wantLimitedBy []string
+	}{
+		{
+			name:          "within limits",
+			decision:      decision(5),
+			limits:        &namespaceLimits{remainingQuota: map[corev1.ResourceName]*inf.Dec{corev1.ResourceRequestsCPU: inf.NewDec(5, 0)}},
+			want:          decision(5),
+			wantLimitedBy: []string{},
+		},
+		{
+			name:          "reduce replicas to fit the quota",
+			decision:      decision(5),
+			limits:        &namespaceLimits{remainingQuota: map[corev1.ResourceName]*inf.Dec{corev1.ResourceRequestsCPU: inf.NewDec(35, 1)}},
+			want:          decision(3),
+			wantLimitedBy: []string{"requests.cpu quota"},
+		},
+		{
+			name:          "reduce replicas to the pods quota",
+			decision:      decision(5),
+			limits:        &namespaceLimits{remainingQuota: map[corev1.ResourceName]*inf.Dec{corev1.ResourcePods: inf.NewDec(2, 0)}},
+			want:          decision(2),
+			wantLimitedBy: []string{"pods quota"},
+		},
+		{
+			name:          "reduce resources of fixed replicas to fit the quota",
+			decision:      decision(4),
+			limits:        &namespaceLimits{remainingQuota: map[corev1.ResourceName]*inf.Dec{corev1.ResourceLimitsCPU: inf.NewDec(4, 0)}},
+			fixedReplicas: true,
+			want: &strategy.ScalingDecision{
+				Replicas: 4,
+				ContainerResources: strategy.ContainerResources{
+					"app": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(100, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(200, 0)},
+					},
+				},
+			},
+			wantLimitedBy: []string{"limits.cpu quota"},
+		},
+		{
+			name:     "limit container resources to the limit range",
+			decision: decision(2),
+			limits: &namespaceLimits{
+				containerMin:         strategy.ResourcesList{Memory: inf.NewDec(150, 0)},
+				containerMax:         strategy.ResourcesList{CPU: inf.NewDec(15, 1)},
+				maxLimitRequestRatio: strategy.ResourcesList{CPU: inf.NewDec(12, 1)},
+			},
+			want: &strategy.ScalingDecision{
+				Replicas: 2,
+				ContainerResources: strategy.ContainerResources{
+					"app": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(150, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(12, 1), Memory: inf.NewDec(200, 0)},
+					},
+				},
+			},
+			wantLimitedBy: []string{"limit range"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLimitedBy := clampToNamespace(tt.decision, tt.limits, tt.fixedReplicas)
+			if diff := cmp.Diff(tt.wantLimitedBy, gotLimitedBy); diff != "" {
+				t.Errorf("clampToNamespace() %v", diff)
+			}
+			if diff := cmp.Diff(tt.want, tt.decision, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("clampToNamespace() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_podFitsNode(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"pool": "large", "zone": "a"}},
+		Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+			{Key: "dedicated", Value: "batch", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule},
+		}},
+	}
+	toleration := corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "batch", Effect: corev1.TaintEffectNoSchedule}
+	affinity := func(requirement corev1.NodeSelectorRequirement) *corev1.Affinity {
+		return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{requirement}}},
+			},
+		}}
+	}
+
+	tests := []struct {
+		name string
+		spec *corev1.PodSpec
+		want bool
+	}{
+		{
+			name: "taint not tolerated",
+			spec: &corev1.PodSpec{},
+			want: false,
+		},
+		{
+			name: "taint tolerated",
+			spec: &corev1.PodSpec{Tolerations: []corev1.Toleration{toleration}},
+			want: true,
+		},
+		{
+			name: "node selector does not match",
+			spec: &corev1.PodSpec{Tolerations: []corev1.Toleration{toleration}, NodeSelector: map[string]string{"pool": "small"}},
+			want: false,
+		},
+		{
+			name: "required affinity matches",
+			spec: &corev1.PodSpec{
+				Tolerations: []corev1.Toleration{toleration},
+				Affinity:    affinity(corev1.NodeSelectorRequirement{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a", "b"}}),
+			},
+			want: true,
+		},
+		{
+			name: "required affinity does not match",
+			spec: &corev1.PodSpec{
+				Tolerations: []corev1.Toleration{toleration},
+				Affinity:    affinity(corev1.NodeSelectorRequirement{Key: "zone", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"a"}}),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podFitsNode(tt.spec, node); got != tt.want {
+				t.Errorf("podFitsNode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_samplePods(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	revision := podRevision{label: appsv1.DefaultDeploymentUniqueLabelKey, hash: "new"}
+	pod := func(name, hash string, phase corev1.PodPhase, ready corev1.ConditionStatus, readySince time.Time) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: hash}},
+			Status: corev1.PodStatus{
+				Phase:      phase,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready, LastTransitionTime: metav1.Time{Time: readySince}}},
+			},
+		}
+	}
+	terminating := pod("terminating", "new", corev1.PodRunning, corev1.ConditionTrue, now.Add(-time.Hour))
+	terminating.DeletionTimestamp = &metav1.Time{Time: now}
+
+	pods := []corev1.Pod{
+		pod("ready", "new", corev1.PodRunning, corev1.ConditionTrue, now.Add(-time.Hour)),
+		pod("recently-ready", "new", corev1.PodRunning, corev1.ConditionTrue, now.Add(-10*time.Second)),
+		pod("not-ready", "new", corev1.PodRunning, corev1.ConditionFalse, now.Add(-time.Hour)),
+		pod("pending", "new", corev1.PodPending, corev1.ConditionFalse, now.Add(-time.Hour)),
+		pod("old-revision", "old", corev1.PodRunning, corev1.ConditionTrue, now.Add(-time.Hour)),
+		terminating,
+	}
+
+	tests := []struct {
+		name     string
+		filter   scalingv1.PodFilter
+		revision podRevision
+		want     []string
+	}{
+		{
+			name:     "default filter",
+			revision: revision,
+			want:     []string{"ready", "recently-ready"},
+		},
+		{
+			name:     "unknown revision",
+			revision: podRevision{label: appsv1.DefaultDeploymentUniqueLabelKey},
+			want:     []string{"ready", "recently-ready", "old-revision"},
+		},
+		{
+			name:     "all revisions, terminating pods and min ready seconds",
+			filter:   scalingv1.PodFilter{CurrentRevisionOnly: ptr.To(false), IncludeTerminating: true, MinReadySeconds: 30},
+			revision: revision,
+			want:     []string{"ready", "old-revision", "terminating"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, pod := range samplePods(pods, tt.filter, tt.revision, now) {
+				got = append(got, pod.Name)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("samplePods() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_unschedulablePods(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	pod := func(phase corev1.PodPhase, reason string, since time.Time) corev1.Pod {
+		return corev1.Pod{Status: corev1.PodStatus{
+			Phase: phase,
+			Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodScheduled,
+				Status:             corev1.ConditionFalse,
+				Reason:             reason,
+				LastTransitionTime: metav1.Time{Time: since},
+			}},
+		}}
+	}
+	pods := []corev1.Pod{
+		pod(corev1.PodPending, corev1.PodReasonUnschedulable, now.Add(-10*time.Minute)),
+		pod(corev1.PodPending, corev1.PodReasonUnschedulable, now.Add(-time.Minute)),
+		pod(corev1.PodPending, "SchedulingGated", now.Add(-10*time.Minute)),
+		{Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+	}
+
+	gotUnschedulable, gotExpired := unschedulablePods(pods, 5*time.Minute, now)
+	if gotUnschedulable != 2 || gotExpired != 1 {
+		t.Errorf("unschedulablePods() = %v, %v, want 2, 1", gotUnschedulable, gotExpired)
+	}
+}
+
+func Test_scaleUp(t *testing.T) {
+	resources := func(cpu string) map[string]scalingv1.ContainerResources {
+		return map[string]scalingv1.ContainerResources{
+			"app": {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse("1G")}},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		replicas     int32
+		resources    map[string]scalingv1.ContainerResources
+		newReplicas  int32
+		newResources map[string]scalingv1.ContainerResources
+		want         *scalingv1.ScaleUp
+	}{
+		{
+			name:         "scale down",
+			replicas:     3,
+			resources:    resources("1"),
+			newReplicas:  2,
+			newResources: resources("500m"),
+			want:         nil,
+		},
+		{
+			name:         "scale out",
+			replicas:     3,
+			resources:    resources("1"),
+			newReplicas:  5,
+			newResources: resources("500m"),
+			want:         &scalingv1.ScaleUp{Replicas: 3},
+		},
+		{
+			name:         "scale up resources",
+			replicas:     3,
+			resources:    resources("1"),
+			newReplicas:  3,
+			newResources: resources("2"),
+			want:         &scalingv1.ScaleUp{Replicas: 3, ContainerResources: resources("1")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scaleUp(tt.replicas, tt.resources, tt.newReplicas, tt.newResources)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("scaleUp() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_rollbackScaleUp(t *testing.T) {
+	tests := []struct {
+		name         string
+		scaleUp      *scalingv1.ScaleUp
+		replicas     int32
+		expired      int32
+		wantReplicas int32
+	}{
+		{
+			name:         "remove expired pods",
+			scaleUp:      &scalingv1.ScaleUp{Replicas: 2},
+			replicas:     6,
+			expired:      3,
+			wantReplicas: 3,
+		},
+		{
+			name:         "not below the replicas before the scale-up",
+			scaleUp:      &scalingv1.ScaleUp{Replicas: 4},
+			replicas:     6,
+			expired:      3,
+			wantReplicas: 4,
+		},
+		{
+			name:         "keep replicas if only resources were scaled up",
+			scaleUp:      &scalingv1.ScaleUp{Replicas: 6},
+			replicas:     6,
+			expired:      3,
+			wantReplicas: 6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotReplicas, _ := rollbackScaleUp(tt.scaleUp, tt.replicas, tt.expired)
+			if gotReplicas != tt.wantReplicas {
+				t.Errorf("rollbackScaleUp() = %v, want %v", gotReplicas, tt.wantReplicas)
+			}
+		})
+	}
+}
+
+func TestHybridScalerReconciler_currentRevision(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", Annotations: map[string]string{revisionAnnotation: "2"}},
+	}
+	replicaSet := func(name, revision string) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "default",
+				Annotations:     map[string]string{revisionAnnotation: revision},
+				Labels:          map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: name},
+				OwnerReferences: []metav1.OwnerReference{{Kind: kindDeployment, Name: "app", Controller: ptr.To(true)}},
+			},
+		}
+	}
+
+	r := &HybridScalerReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(testScheme).
+			WithObjects(deployment, replicaSet("old", "1"), replicaSet("new", "2")).
+			WithIndex(&appsv1.ReplicaSet{}, ownerKey, func(obj client.Object) []string {
+				return []string{metav1.GetControllerOf(obj).Name}
+			}).
+			Build(),
+	}
+
+	tests := []struct {
+		name      string
+		workload  *workload
+		wantLabel string
+		wantHash  string
+	}{
+		{
+			name:      "deployment",
+			workload:  &workload{Object: deployment, kind: kindDeployment},
+			wantLabel: appsv1.DefaultDeploymentUniqueLabelKey,
+			wantHash:  "new",
+		},
+		{
+			name:      "stateful set",
+			workload:  &workload{Object: &appsv1.StatefulSet{}, kind: kindStatefulSet, updateRevision: "app-123"},
+			wantLabel: appsv1.StatefulSetRevisionLabel,
+			wantHash:  "app-123",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLabel, gotHash, err := r.currentRevision(context.Background(), tt.workload)
+			if err != nil {
+				t.Errorf("HybridScalerReconciler.currentRevision() error = %v", err)
+				return
+			}
+			if gotLabel != tt.wantLabel || gotHash != tt.wantHash {
+				t.Errorf("HybridScalerReconciler.currentRevision() = %v, %v, want %v, %v", gotLabel, gotHash, tt.wantLabel, tt.wantHash)
+			}
+		})
+	}
+}
+
+func Test_getScalingStrategy(t *testing.T) {
+	tests := []struct {
+		name         string
+		learningType scalingv1.LearningType
+		want         strategy.ScalingStrategy
+		wantErr      bool
+	}{
+		{
+			name:         "horizontal",
+			learningType: scalingv1.LearningTypeHorizontal,
+			want:         &classic.Horizontal{},
+		},
+		{
+			name:         "vertical",
+			learningType: scalingv1.LearningTypeVertical,
+			want:         &classic.Vertical{},
+		},
+		{
+			name:         "hybrid",
+			learningType: scalingv1.LearningTypeHybrid,
+			want:         &classic.Hybrid{},
+		},
+		{
+			name:         "rule-based with defaults",
+			learningType: scalingv1.LearningTypeRuleBased,
+			want:         classic.NewRuleBased(inf.NewDec(1, 1), 1, 1),
+		},
+		{
+			name:         "none",
+			learningType: scalingv1.LearningTypeNone,
+			want:         &strategy.NoOp{},
+		},
+		{
+			name:         "unknown learning type",
+			learningType: "unknown",
+			wantErr:      true,
+		},
+		{
+			name:         "empty learning type",
+			learningType: "",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getScalingStrategy(tt.learningType, scalingv1.QLearningParams{}, scalingv1.RuleBasedParams{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getScalingStrategy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(classic.RuleBased{}), cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("getScalingStrategy() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_shadowDecisions(t *testing.T) {
+	state := &strategy.State{
+		Replicas: 2,
+		ContainerResources: strategy.ContainerResources{
+			"container": {
+				Requests: strategy.ResourcesList{CPU: inf.NewDec(200, 3), Memory: inf.NewDec(200, 0)},
+				Limits:   strategy.ResourcesList{CPU: inf.NewDec(400, 3), Memory: inf.NewDec(400, 0)},
+			},
+		},
+		Constraints: strategy.Constraints{MinReplicas: 1, MaxReplicas: 10},
+		PodMetrics: strategy.PodMetrics{
+			ResourceUsage: strategy.ResourcesList{CPU: inf.NewDec(200, 3), Memory: inf.NewDec(100, 0)},
+			Resources: strategy.Resources{
+				Requests: strategy.ResourcesList{CPU: inf.NewDec(200, 3), Memory: inf.NewDec(200, 0)},
+				Limits:   strategy.ResourcesList{CPU: inf.NewDec(400, 3), Memory: inf.NewDec(400, 0)},
+			},
+		},
+		TargetUtilization: strategy.ResourcesList{CPU: inf.NewDec(5, 1), Memory: inf.NewDec(5, 1)},
+	}
+	spec := scalingv1.HybridScalerSpec{
+		QLearningParams: scalingv1.QLearningParams{
+			CpuCost:                  ptr.To(resource.MustParse("1")),
+			MemoryCost:               ptr.To(resource.MustParse("0")),
+			UnderprovisioningPenalty: ptr.To(resource.MustParse("2")),
+		},
+	}
+	shadows := []scalingv1.ShadowStrategy{
+		{Name: "horizontal", LearningType: scalingv1.LearningTypeHorizontal},
+		{Name: "none", LearningType: scalingv1.LearningTypeNone},
+		{Name: "unknown", LearningType: "unknown"},
+	}
+	previous := []scalingv1.ShadowDecision{
+		{Name: "none", LearningState: []byte("none")},
+		{Name: "removed", LearningState: []byte("removed")},
+	}
+	resources := map[string]scalingv1.ContainerResources{
+		"container": {
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("200")},
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("400m"), corev1.ResourceMemory: resource.MustParse("400")},
+		},
+	}
+
+	want := []scalingv1.ShadowDecision{
+		{
+			Name:               "horizontal",
+			Description:        "horizontal",
+			Replicas:           4,
+			ContainerResources: resources,
+			EstimatedCost:      resource.NewMilliQuantity(800, resource.DecimalSI),
+		},
+		{
+			Name:               "none",
+			Replicas:           2,
+			ContainerResources: resources,
+			EstimatedCost:      resource.NewMilliQuantity(1200, resource.DecimalSI),
+			LearningState:      []byte("none"),
+		},
+		{
+			Name:  "unknown",
+			Error: `unknown learning type "unknown"`,
+		},
+	}
+
+	got := shadowDecisions(shadows, previous, state, &namespaceLimits{}, spec)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("shadowDecisions() %v", diff)
+	}
+}
+
+func Test_guardrails(t *testing.T) {
+	metrics := func(usage, requests int64) strategy.PodMetrics {
+		return strategy.PodMetrics{
+			ResourceUsage: strategy.ResourcesList{CPU: inf.NewDec(usage, 0), Memory: inf.NewDec(100, 0)},
+			Resources: strategy.Resources{
+				Requests: strategy.ResourcesList{CPU: inf.NewDec(requests, 0), Memory: inf.NewDec(100, 0)},
+			},
+		}
+	}
+	decisionErr := fmt.Errorf("requests cannot be zero")
+	overutilized := metav1.Condition{Type: scalingv1.ConditionDegraded, Status: metav1.ConditionTrue, Reason: reasonOverutilized}
+
+	tests := []struct {
+		name       string
+		status     scalingv1.HybridScalerStatus
+		policy     scalingv1.FallbackPolicy
+		err        error
+		metrics    strategy.PodMetrics
+		want       string
+		wantStatus scalingv1.HybridScalerStatus
+	}{
+		{
+			name:       "healthy",
+			metrics:    metrics(100, 100),
+			want:       "",
+			wantStatus: scalingv1.HybridScalerStatus{},
+		},
+		{
+			name:       "count errors below the maximum",
+			status:     scalingv1.HybridScalerStatus{ConsecutiveErrors: 1},
+			err:        decisionErr,
+			metrics:    metrics(100, 100),
+			want:       "",
+			wantStatus: scalingv1.HybridScalerStatus{ConsecutiveErrors: 2},
+		},
+		{
+			name:       "degrade at the maximum of consecutive errors",
+			status:     scalingv1.HybridScalerStatus{ConsecutiveErrors: 2},
+			err:        decisionErr,
+			metrics:    metrics(100, 100),
+			want:       reasonDecisionErrors,
+			wantStatus: scalingv1.HybridScalerStatus{ConsecutiveErrors: 3},
+		},
+		{
+			name:       "recover after a successful decision",
+			status:     scalingv1.HybridScalerStatus{ConsecutiveErrors: 5},
+			metrics:    metrics(100, 100),
+			want:       "",
+			wantStatus: scalingv1.HybridScalerStatus{},
+		},
+		{
+			name:       "degrade at the maximum of overutilized intervals",
+			status:     scalingv1.HybridScalerStatus{OverutilizedIntervals: 1},
+			policy:     scalingv1.FallbackPolicy{MaxOverutilizedIntervals: ptr.To[int32](2)},
+			metrics:    metrics(160, 100),
+			want:       reasonOverutilized,
+			wantStatus: scalingv1.HybridScalerStatus{OverutilizedIntervals: 2},
+		},
+		{
+			name:       "utilization below a custom maximum",
+			status:     scalingv1.HybridScalerStatus{OverutilizedIntervals: 2},
+			policy:     scalingv1.FallbackPolicy{MaxUtilization: ptr.To[int32](200)},
+			metrics:    metrics(160, 100),
+			want:       "",
+			wantStatus: scalingv1.HybridScalerStatus{},
+		},
+		{
+			name:       "errors take precedence over overutilization",
+			status:     scalingv1.HybridScalerStatus{ConsecutiveErrors: 2, OverutilizedIntervals: 2},
+			err:        decisionErr,
+			metrics:    metrics(160, 100),
+			want:       reasonDecisionErrors,
+			wantStatus: scalingv1.HybridScalerStatus{ConsecutiveErrors: 3, OverutilizedIntervals: 3},
+		},
+		{
+			name:       "stay degraded while recovering",
+			status:     scalingv1.HybridScalerStatus{RecoveredIntervals: 1, Conditions: []metav1.Condition{overutilized}},
+			metrics:    metrics(100, 100),
+			want:       reasonOverutilized,
+			wantStatus: scalingv1.HybridScalerStatus{RecoveredIntervals: 2, Conditions: []metav1.Condition{overutilized}},
+		},
+		{
+			name:       "restart recovery after an unhealthy interval",
+			status:     scalingv1.HybridScalerStatus{RecoveredIntervals: 2, Conditions: []metav1.Condition{overutilized}},
+			metrics:    metrics(160, 100),
+			want:       reasonOverutilized,
+			wantStatus: scalingv1.HybridScalerStatus{OverutilizedIntervals: 1, Conditions: []metav1.Condition{overutilized}},
+		},
+		{
+			name:       "recover after the recovery intervals",
+			status:     scalingv1.HybridScalerStatus{RecoveredIntervals: 1, Conditions: []metav1.Condition{overutilized}},
+			policy:     scalingv1.FallbackPolicy{RecoveryIntervals: ptr.To[int32](2)},
+			metrics:    metrics(100, 100),
+			want:       "",
+			wantStatus: scalingv1.HybridScalerStatus{Conditions: []metav1.Condition{overutilized}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			got := guardrails(&status, &tt.policy, tt.err, &strategy.State{PodMetrics: tt.metrics})
+			if got != tt.want {
+				t.Errorf("guardrails() = %v, want %v", got, tt.want)
+			}
+			if diff := cmp.Diff(tt.wantStatus, status); diff != "" {
+				t.Errorf("guardrails() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_guardrails_fallbackResolvesOverutilization(t *testing.T) {
+	r := &HybridScalerReconciler{Recorder: record.NewFakeRecorder(100)}
+	scaler := &scalingv1.HybridScaler{Spec: scalingv1.HybridScalerSpec{LearningType: scalingv1.LearningTypeQLearning}}
+	policy := &scalingv1.FallbackPolicy{}
+
+	// the active strategy overutilizes the pods while it makes the decisions, the fallback does not
+	usage := map[bool]int64{false: 160, true: 100}
+
+	got := make([]bool, 0)
+	fallback := false
+	for i := 0; i < 12; i++ {
+		state := &strategy.State{PodMetrics: strategy.PodMetrics{
+			ResourceUsage: strategy.ResourcesList{CPU: inf.NewDec(usage[fallback], 0), Memory: inf.NewDec(100, 0)},
+			Resources:     strategy.Resources{Requests: strategy.ResourcesList{CPU: inf.NewDec(100, 0), Memory: inf.NewDec(100, 0)}},
+		}}
+
+		reason := guardrails(&scaler.Status, policy, nil, state)
+		r.setDegradedCondition(scaler, reason, scalingv1.LearningTypeHorizontal)
+		fallback = reason != ""
+		got = append(got, fallback)
+	}
+
+	want := []bool{false, false, true, true, true, false, false, false, true, true, true, false}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("guardrails() fallback per interval %v", diff)
+	}
+}
+
+func Test_groupDecision(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+	_ = scalingv1.AddToScheme(testScheme)
+
+	group := &scalingv1.LearningGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "group"},
+		Spec: scalingv1.LearningGroupSpec{
+			QLearningParams: scalingv1.QLearningParams{
+				LearningRate:             ptr.To(resource.MustParse("0.5")),
+				DiscountFactor:           ptr.To(resource.MustParse("0.9")),
+				Epsilon:                  ptr.To(resource.MustParse("0.1")),
+				CpuCost:                  ptr.To(resource.MustParse("1")),
+				MemoryCost:               ptr.To(resource.MustParse("1")),
+				UnderprovisioningPenalty: ptr.To(resource.MustParse("10")),
+			},
+		},
+	}
+	state := &strategy.State{
+		Replicas: 2,
+		ContainerResources: strategy.ContainerResources{
+			"app": {
+				Requests: strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(1, 0)},
+				Limits:   strategy.ResourcesList{CPU: inf.NewDec(2, 0), Memory: inf.NewDec(2, 0)},
+			},
+		},
+		Constraints: strategy.Constraints{
+			MinReplicas:                 1,
+			MaxReplicas:                 2,
+			MinResources:                strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(1, 0)},
+			MaxResources:                strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(1, 0)},
+			LimitsToRequestsRatioCPU:    inf.NewDec(2, 0),
+			LimitsToRequestsRatioMemory: inf.NewDec(2, 0),
+		},
+		PodMetrics: strategy.PodMetrics{
+			ResourceUsage: strategy.ResourcesList{CPU: inf.NewDec(5, 1), Memory: inf.NewDec(5, 1)},
+			Resources: strategy.Resources{
+				Requests: strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(1, 0)},
+				Limits:   strategy.ResourcesList{CPU: inf.NewDec(2, 0), Memory: inf.NewDec(2, 0)},
+			},
+		},
+		TargetUtilization: strategy.ResourcesList{CPU: inf.NewDec(8, 1), Memory: inf.NewDec(8, 1)},
+	}
+
+	tests := []struct {
+		name         string
+		objects      []client.Object
+		conflicts    int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{
+			name:    "missing group",
+			wantErr: true,
+		},
+		{
+			name:         "shared table is updated",
+			objects:      []client.Object{group.DeepCopy()},
+			wantAttempts: 2,
+		},
+		{
+			name:         "conflicting update is retried on the latest table",
+			objects:      []client.Object{group.DeepCopy()},
+			conflicts:    1,
+			wantAttempts: 3,
+		},
+		{
+			name:      "persistent conflicts",
+			objects:   []client.Object{group.DeepCopy()},
+			conflicts: 100,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			r := &HybridScalerReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(tt.objects...).
+					WithStatusSubresource(&scalingv1.LearningGroup{}).
+					WithInterceptorFuncs(interceptor.Funcs{
+						SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+							attempts++
+							if attempts <= tt.conflicts {
+								return errors.NewConflict(scalingv1.GroupVersion.WithResource("learninggroups").GroupResource(), obj.GetName(), fmt.Errorf("conflict"))
+							}
+							return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+						},
+					}).
+					Build(),
+			}
+
+			// the first decision is pending, the second one observes its outcome and updates the shared table
+			_, own, err := r.groupDecision(context.Background(), "group", state, nil)
+			if err == nil {
+				_, own, err = r.groupDecision(context.Background(), "group", state, own)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HybridScalerReconciler.groupDecision() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("HybridScalerReconciler.groupDecision() attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+
+			var got scalingv1.LearningGroup
+			if err := r.Get(context.Background(), client.ObjectKey{Name: "group"}, &got); err != nil {
+				t.Errorf("cannot get learning group, %v", err)
+				return
+			}
+
+			table, _, err := reinforcement.SplitLearningState(got.Status.LearningState)
+			if err != nil {
+				t.Errorf("cannot split shared learning state, %v", err)
+				return
+			}
+			emptyTable, _, _ := reinforcement.SplitLearningState(nil)
+			if len(table) <= len(emptyTable) {
+				t.Errorf("HybridScalerReconciler.groupDecision() shared table was not updated")
+			}
+
+			ownTable, _, err := reinforcement.SplitLearningState(own)
+			if err != nil {
+				t.Errorf("cannot split own learning state, %v", err)
+				return
+			}
+			if diff := cmp.Diff(emptyTable, ownTable); diff != "" {
+				t.Errorf("HybridScalerReconciler.groupDecision() own learning state holds a table %v", diff)
+			}
+		})
+	}
+}
+
+func TestHybridScalerReconciler_setLearningGroupCondition(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+	_ = scalingv1.AddToScheme(testScheme)
+
+	group := &scalingv1.LearningGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "group"},
+		Spec: scalingv1.LearningGroupSpec{
+			QLearningParams: scalingv1.QLearningParams{
+				LearningRate: ptr.To(resource.MustParse("0.5")),
+				Epsilon:      ptr.To(resource.MustParse("0.1")),
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		spec       scalingv1.HybridScalerSpec
+		wantReason string
+		wantErr    bool
+	}{
+		{
+			name: "no learning group",
+			spec: scalingv1.HybridScalerSpec{LearningType: scalingv1.LearningTypeQLearning},
+		},
+		{
+			name: "same parameters as the group",
+			spec: scalingv1.HybridScalerSpec{
+				LearningType:    scalingv1.LearningTypeQLearning,
+				LearningGroup:   "group",
+				QLearningParams: scalingv1.QLearningParams{LearningRate: ptr.To(resource.MustParse("0.50"))},
+			},
+			wantReason: "GroupParameters",
+		},
+		{
+			name: "parameters overridden by the group",
+			spec: scalingv1.HybridScalerSpec{
+				LearningType:    scalingv1.LearningTypeQLearning,
+				LearningGroup:   "group",
+				QLearningParams: scalingv1.QLearningParams{Epsilon: ptr.To(resource.MustParse("0.2"))},
+			},
+			wantReason: "ParametersOverridden",
+		},
+		{
+			name: "learning type does not use the group",
+			spec: scalingv1.HybridScalerSpec{
+				LearningType:  scalingv1.LearningTypeHorizontal,
+				LearningGroup: "group",
+			},
+			wantReason: "LearningTypeMismatch",
+		},
+		{
+			name: "missing group",
+			spec: scalingv1.HybridScalerSpec{
+				LearningType:  scalingv1.LearningTypeQLearning,
+				LearningGroup: "missing",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &HybridScalerReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(testScheme).WithObjects(group.DeepCopy()).Build(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+			scaler := &scalingv1.HybridScaler{Spec: tt.spec}
+
+			err := r.setLearningGroupCondition(context.Background(), scaler)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HybridScalerReconciler.setLearningGroupCondition() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var gotReason string
+			if condition := meta.FindStatusCondition(scaler.Status.Conditions, scalingv1.ConditionLearningGroupConflict); condition != nil {
+				gotReason = condition.Reason
+			}
+			if gotReason != tt.wantReason {
+				t.Errorf("HybridScalerReconciler.setLearningGroupCondition() reason = %v, want %v", gotReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func Test_overriddenQLearningParams(t *testing.T) {
+	group := scalingv1.QLearningParams{
+		LearningRate:     ptr.To(resource.MustParse("0.5")),
+		CpuCost:          ptr.To(resource.MustParse("1")),
+		TimeOfDayBuckets: ptr.To(int32(24)),
+	}
+
+	tests := []struct {
+		name   string
+		member scalingv1.QLearningParams
+		want   []string
+	}{
+		{
+			name: "nothing set",
+		},
+		{
+			name: "equal values",
+			member: scalingv1.QLearningParams{
+				LearningRate:     ptr.To(resource.MustParse("500m")),
+				TimeOfDayBuckets: ptr.To(int32(24)),
+			},
+		},
+		{
+			name: "different values",
+			member: scalingv1.QLearningParams{
+				CpuCost:          ptr.To(resource.MustParse("2")),
+				OOMPenalty:       ptr.To(resource.MustParse("100")),
+				TimeOfDayBuckets: ptr.To(int32(12)),
+				TimeZone:         ptr.To("Europe/Berlin"),
+				TraceDecay:       ptr.To(resource.MustParse("0.9")),
+			},
+			want: []string{"cpuCost", "oomPenalty", "traceDecay", "timeOfDayBuckets", "timeZone"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := overriddenQLearningParams(tt.member, group)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("overriddenQLearningParams() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_withPolicy(t *testing.T) {
+	policy := scalingv1.HybridScalerPolicySpec{
+		MinReplicas:  ptr.To(int32(1)),
+		MaxReplicas:  ptr.To(int32(10)),
+		LearningType: scalingv1.LearningTypeQLearning,
+		Interval:     ptr.To(int32(60)),
+		QLearningParams: scalingv1.QLearningParams{
+			LearningRate:     ptr.To(resource.MustParse("0.5")),
+			DiscountFactor:   ptr.To(resource.MustParse("0.9")),
+			Epsilon:          ptr.To(resource.MustParse("0.1")),
+			TimeOfDayBuckets: ptr.To(int32(24)),
+			TimeZone:         ptr.To("Europe/Berlin"),
+		},
+		ResourcePolicy: scalingv1.ResourcePolicy{
+			MinAllowed: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("100M"),
+			},
+			TargetUtilization: map[corev1.ResourceName]int32{
+				corev1.ResourceCPU:    70,
+				corev1.ResourceMemory: 80,
+			},
+			LimitsToRequestsRatioCPU:    ptr.To(resource.MustParse("2")),
+			LimitsToRequestsRatioMemory: ptr.To(resource.MustParse("1.5")),
+		},
+	}
+
+	tests := []struct {
+		name string
+		spec scalingv1.HybridScalerSpec
+		want scalingv1.HybridScalerSpec
+	}{
+		{
+			name: "scaler without own settings",
+			spec: scalingv1.HybridScalerSpec{Policy: "policy"},
+			want: scalingv1.HybridScalerSpec{
+				Policy:          "policy",
+				MinReplicas:     policy.MinReplicas,
+				MaxReplicas:     policy.MaxReplicas,
+				LearningType:    policy.LearningType,
+				Interval:        policy.Interval,
+				QLearningParams: policy.QLearningParams,
+				ResourcePolicy:  policy.ResourcePolicy,
+			},
+		},
+		{
+			name: "fields set on the scaler take precedence",
+			spec: scalingv1.HybridScalerSpec{
+				Policy:       "policy",
+				MaxReplicas:  ptr.To(int32(5)),
+				LearningType: scalingv1.LearningTypeRuleBased,
+				Interval:     ptr.To(int32(30)),
+				QLearningParams: scalingv1.QLearningParams{
+					Epsilon:     ptr.To(resource.MustParse("0.3")),
+					TimeZone:    ptr.To("America/New_York"),
+					TraceLength: ptr.To(int32(3)),
+				},
+				ResourcePolicy: scalingv1.ResourcePolicy{
+					MinAllowed: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("200m"),
+					},
+					MaxAllowed: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+					},
+					LimitsToRequestsRatioMemory: ptr.To(resource.MustParse("1")),
+				},
+			},
+			want: scalingv1.HybridScalerSpec{
+				Policy:       "policy",
+				MinReplicas:  ptr.To(int32(1)),
+				MaxReplicas:  ptr.To(int32(5)),
+				LearningType: scalingv1.LearningTypeRuleBased,
+				Interval:     ptr.To(int32(30)),
+				QLearningParams: scalingv1.QLearningParams{
+					LearningRate:     ptr.To(resource.MustParse("0.5")),
+					DiscountFactor:   ptr.To(resource.MustParse("0.9")),
+					Epsilon:          ptr.To(resource.MustParse("0.3")),
+					TimeOfDayBuckets: ptr.To(int32(24)),
+					TimeZone:         ptr.To("America/New_York"),
+					TraceLength:      ptr.To(int32(3)),
+				},
+				ResourcePolicy: scalingv1.ResourcePolicy{
+					MinAllowed: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("200m"),
+						corev1.ResourceMemory: resource.MustParse("100M"),
+					},
+					MaxAllowed: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+					},
+					TargetUtilization: map[corev1.ResourceName]int32{
+						corev1.ResourceCPU:    70,
+						corev1.ResourceMemory: 80,
+					},
+					LimitsToRequestsRatioCPU:    ptr.To(resource.MustParse("2")),
+					LimitsToRequestsRatioMemory: ptr.To(resource.MustParse("1")),
+				},
+			},
+		},
+		{
+			name: "zero set on the scaler takes precedence",
+			spec: scalingv1.HybridScalerSpec{
+				Policy: "policy",
+				QLearningParams: scalingv1.QLearningParams{
+					Epsilon: ptr.To(resource.MustParse("0")),
+				},
+				ResourcePolicy: scalingv1.ResourcePolicy{
+					LimitsToRequestsRatioCPU: ptr.To(resource.MustParse("0")),
+				},
+			},
+			want: scalingv1.HybridScalerSpec{
+				Policy:       "policy",
+				MinReplicas:  policy.MinReplicas,
+				MaxReplicas:  policy.MaxReplicas,
+				LearningType: policy.LearningType,
+				Interval:     policy.Interval,
+				QLearningParams: scalingv1.QLearningParams{
+					LearningRate:     ptr.To(resource.MustParse("0.5")),
+					DiscountFactor:   ptr.To(resource.MustParse("0.9")),
+					Epsilon:          ptr.To(resource.MustParse("0")),
+					TimeOfDayBuckets: ptr.To(int32(24)),
+					TimeZone:         ptr.To("Europe/Berlin"),
+				},
+				ResourcePolicy: scalingv1.ResourcePolicy{
+					MinAllowed:                  policy.ResourcePolicy.MinAllowed,
+					TargetUtilization:           policy.ResourcePolicy.TargetUtilization,
+					LimitsToRequestsRatioCPU:    ptr.To(resource.MustParse("0")),
+					LimitsToRequestsRatioMemory: ptr.To(resource.MustParse("1.5")),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withPolicy(tt.spec, policy)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("withPolicy() %v", diff)
+			}
+		})
+	}
+}
+
+func TestHybridScalerPolicyReconciler_Reconcile(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+	_ = scalingv1.AddToScheme(testScheme)
+
+	scaler := func(namespace, name, policy string) *scalingv1.HybridScaler {
+		return &scalingv1.HybridScaler{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       scalingv1.HybridScalerSpec{Policy: policy},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		want    []scalingv1.ScalerReference
+	}{
+		{
+			name: "no scalers",
+			objects: []client.Object{
+				&scalingv1.HybridScalerPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy"}},
+			},
+		},
+		{
+			name: "scalers referencing the policy",
+			objects: []client.Object{
+				&scalingv1.HybridScalerPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy"}},
+				scaler("b", "scaler", "policy"),
+				scaler("a", "scaler2", "policy"),
+				scaler("a", "scaler1", "policy"),
+				scaler("a", "other", "other"),
+				scaler("a", "none", ""),
+			},
+			want: []scalingv1.ScalerReference{
+				{Namespace: "a", Name: "scaler1"},
+				{Namespace: "a", Name: "scaler2"},
+				{Namespace: "b", Name: "scaler"},
+			},
+		},
+		{
+			name: "scalers no longer referencing the policy are removed",
+			objects: []client.Object{
+				&scalingv1.HybridScalerPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+					Status: scalingv1.HybridScalerPolicyStatus{
+						Scalers: []scalingv1.ScalerReference{
+							{Namespace: "a", Name: "scaler1"},
+							{Namespace: "a", Name: "scaler2"},
+						},
+					},
+				},
+				scaler("a", "scaler1", "policy"),
+			},
+			want: []scalingv1.ScalerReference{
+				{Namespace: "a", Name: "scaler1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &HybridScalerPolicyReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(tt.objects...).
+					WithStatusSubresource(&scalingv1.HybridScalerPolicy{}).
+					WithIndex(&scalingv1.HybridScaler{}, policyKey, scalerPolicy).
+					Build(),
+				Scheme: testScheme,
+			}
+
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKey{Name: "policy"}}); err != nil {
+				t.Errorf("HybridScalerPolicyReconciler.Reconcile() error = %v", err)
+				return
+			}
+
+			var got scalingv1.HybridScalerPolicy
+			if err := r.Get(context.Background(), client.ObjectKey{Name: "policy"}, &got); err != nil {
+				t.Errorf("cannot get policy, %v", err)
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got.Status.Scalers); diff != "" {
+				t.Errorf("HybridScalerPolicyReconciler.Reconcile() %v", diff)
+			}
+		})
+	}
+}
+
+func TestOnboardingReconciler_Reconcile(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+	_ = scalingv1.AddToScheme(testScheme)
+
+	deployment := func(policy string) *appsv1.Deployment {
+		d := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", UID: "uid"},
+		}
+		if policy != "" {
+			d.Annotations = map[string]string{policyAnnotation: policy}
+		}
+		return d
+	}
+	ownerReferences := []metav1.OwnerReference{{
+		APIVersion:         "apps/v1",
+		Kind:               kindDeployment,
+		Name:               "app",
+		UID:                "uid",
+		Controller:         ptr.To(true),
+		BlockOwnerDeletion: ptr.To(true),
+	}}
+	scaler := func(policy string, owners []metav1.OwnerReference, interval *int32) *scalingv1.HybridScaler {
+		return &scalingv1.HybridScaler{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", OwnerReferences: owners},
+			Spec: scalingv1.HybridScalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: kindDeployment, Name: "app"},
+				Policy:         policy,
+				Interval:       interval,
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		kind    string
+		objects []client.Object
+		want    *scalingv1.HybridScaler
+	}{
+		{
+			name:    "annotated deployment is onboarded",
+			kind:    kindDeployment,
+			objects: []client.Object{deployment("policy")},
+			want:    scaler("policy", ownerReferences, nil),
+		},
+		{
+			name: "annotated stateful set is onboarded",
+			kind: kindStatefulSet,
+			objects: []client.Object{&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", UID: "uid", Annotations: map[string]string{policyAnnotation: "policy"}},
+			}},
+			want: &scalingv1.HybridScaler{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", OwnerReferences: []metav1.OwnerReference{{
+					APIVersion:         "apps/v1",
+					Kind:               kindStatefulSet,
+					Name:               "app",
+					UID:                "uid",
+					Controller:         ptr.To(true),
+					BlockOwnerDeletion: ptr.To(true),
+				}}},
+				Spec: scalingv1.HybridScalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: kindStatefulSet, Name: "app"},
+					Policy:         "policy",
+				},
+			},
+		},
+		{
+			name:    "changed policy is updated and overrides are kept",
+			kind:    kindDeployment,
+			objects: []client.Object{deployment("new"), scaler("old", ownerReferences, ptr.To(int32(30)))},
+			want:    scaler("new", ownerReferences, ptr.To(int32(30))),
+		},
+		{
+			name:    "scaler is deleted with the annotation",
+			kind:    kindDeployment,
+			objects: []client.Object{deployment(""), scaler("policy", ownerReferences, nil)},
+		},
+		{
+			name:    "scaler which is not owned by the workload is left alone",
+			kind:    kindDeployment,
+			objects: []client.Object{deployment("new"), scaler("old", nil, nil)},
+			want:    scaler("old", nil, nil),
+		},
+		{
+			name:    "deployment without annotation",
+			kind:    kindDeployment,
+			objects: []client.Object{deployment("")},
+		},
+		{
+			name: "missing workload",
+			kind: kindDeployment,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &OnboardingReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(testScheme).
+					WithObjects(tt.objects...).
+					Build(),
+				Scheme: testScheme,
+				Kind:   tt.kind,
+			}
+
+			key := client.ObjectKey{Namespace: "default", Name: "app"}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+				t.Errorf("OnboardingReconciler.Reconcile() error = %v", err)
+				return
+			}
+
+			var got scalingv1.HybridScaler
+			err := r.Get(context.Background(), key, &got)
+			if err != nil && !errors.IsNotFound(err) {
+				t.Errorf("cannot get scaler, %v", err)
+				return
+			}
+
+			if tt.want == nil {
+				if err == nil {
+					t.Errorf("OnboardingReconciler.Reconcile() scaler = %v, want none", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("OnboardingReconciler.Reconcile() no scaler, want %v", tt.want)
+				return
+			}
+
+			if diff := cmp.Diff(tt.want.ObjectMeta.OwnerReferences, got.OwnerReferences); diff != "" {
+				t.Errorf("OnboardingReconciler.Reconcile() owner references %v", diff)
+			}
+
+			if diff := cmp.Diff(tt.want.Spec, got.Spec); diff != "" {
+				t.Errorf("OnboardingReconciler.Reconcile() spec %v", diff)
+			}
+		})
+	}
+}
+
+func TestHybridScalerReconciler_Reconcile(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+	_ = scalingv1.AddToScheme(testScheme)
+
+	validator := statusSchemaValidator(t)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", UID: "uid", Annotations: map[string]string{policyAnnotation: "defaults"}},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(3))},
+	}
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "hpa", Namespace: "default"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: kindDeployment, Name: "app"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		policy        scalingv1.HybridScalerPolicySpec
+		objects       []client.Object
+		reconciles    int
+		wantCondition string
+		wantReason    string
+		wantMessage   string
+	}{
+		{
+			name:          "annotation points to a policy without replica bounds",
+			policy:        scalingv1.HybridScalerPolicySpec{LearningType: scalingv1.LearningTypeHorizontal},
+			reconciles:    1,
+			wantCondition: scalingv1.ConditionIncompleteSpec,
+			wantReason:    "MissingMaxReplicas",
+			wantMessage:   "maxReplicas is set neither on the scaler nor on its policy defaults",
+		},
+		{
+			name:          "annotation points to a policy with only min replicas",
+			policy:        scalingv1.HybridScalerPolicySpec{MinReplicas: ptr.To(int32(1)), LearningType: scalingv1.LearningTypeHorizontal},
+			reconciles:    1,
+			wantCondition: scalingv1.ConditionIncompleteSpec,
+			wantReason:    "MissingMaxReplicas",
+			wantMessage:   "maxReplicas is set neither on the scaler nor on its policy defaults",
+		},
+		{
+			name:          "stored condition is kept without rewriting the status",
+			policy:        scalingv1.HybridScalerPolicySpec{LearningType: scalingv1.LearningTypeHorizontal},
+			reconciles:    2,
+			wantCondition: scalingv1.ConditionIncompleteSpec,
+			wantReason:    "MissingMaxReplicas",
+			wantMessage:   "maxReplicas is set neither on the scaler nor on its policy defaults",
+		},
+		{
+			name:          "target is also managed by a horizontal pod autoscaler",
+			policy:        scalingv1.HybridScalerPolicySpec{MinReplicas: ptr.To(int32(1)), MaxReplicas: ptr.To(int32(5)), LearningType: scalingv1.LearningTypeHorizontal},
+			objects:       []client.Object{hpa},
+			reconciles:    1,
+			wantCondition: scalingv1.ConditionTargetConflict,
+			wantReason:    "MultipleAutoscalers",
+			wantMessage:   "scale target is also managed by HorizontalPodAutoscaler/hpa, set a coexistence mode to share it",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &scalingv1.HybridScalerPolicy{ObjectMeta: metav1.ObjectMeta{Name: "defaults"}, Spec: tt.policy}
+
+			patched, statusWrites := 0, 0
+			c := fake.NewClientBuilder().
+				WithScheme(testScheme).
+				WithObjects(append([]client.Object{deployment.DeepCopy(), policy}, tt.objects...)...).
+				WithStatusSubresource(&scalingv1.HybridScaler{}).
+				WithIndex(&scalingv1.HybridScaler{}, scaleTargetKey, func(obj client.Object) []string {
+					return []string{obj.(*scalingv1.HybridScaler).Spec.ScaleTargetRef.Name}
+				}).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+						patched++
+						return nil
+					},
+					SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+						statusWrites++
+						return validatingStatusPatch(validator)(ctx, c, subResourceName, obj, patch, opts...)
+					},
+				}).
+				Build()
+
+			onboarding := &OnboardingReconciler{Client: c, Scheme: testScheme, Kind: kindDeployment}
+			r := &HybridScalerReconciler{Client: c, Scheme: testScheme, Recorder: record.NewFakeRecorder(10)}
+
+			key := client.ObjectKey{Namespace: "default", Name: "app"}
+			if _, err := onboarding.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+				t.Errorf("OnboardingReconciler.Reconcile() error = %v", err)
+				return
+			}
+
+			for i := 0; i < tt.reconciles; i++ {
+				if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+					t.Errorf("HybridScalerReconciler.Reconcile() error = %v", err)
+					return
+				}
+			}
+
+			if patched > 0 {
+				t.Errorf("HybridScalerReconciler.Reconcile() patched the workload %d times, want none", patched)
+			}
+
+			if statusWrites != 1 {
+				t.Errorf("HybridScalerReconciler.Reconcile() wrote the status %d times, want once", statusWrites)
+			}
+
+			var scaler scalingv1.HybridScaler
+			if err := c.Get(context.Background(), key, &scaler); err != nil {
+				t.Errorf("cannot fetch scaler, %v", err)
+				return
+			}
+
+			condition := meta.FindStatusCondition(scaler.Status.Conditions, tt.wantCondition)
+			if condition == nil {
+				t.Errorf("HybridScalerReconciler.Reconcile() no %s condition stored", tt.wantCondition)
+				return
+			}
+
+			got := []string{string(condition.Status), condition.Reason, condition.Message}
+			want := []string{string(metav1.ConditionTrue), tt.wantReason, tt.wantMessage}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("HybridScalerReconciler.Reconcile() condition %v", diff)
+			}
+		})
+	}
+}
+
+// statusSchemaValidator validates written scalers against the v1 schema of the generated custom resource definition,
+// the fake client does not validate objects itself
+func statusSchemaValidator(t *testing.T) *validate.SchemaValidator {
+	data, err := os.ReadFile(filepath.Join("..", "..", "config", "crd", "bases", "scaling.autoscaling.custom_hybridscalers.yaml"))
+	if err != nil {
+		t.Fatalf("cannot read custom resource definition, %v", err)
+	}
+
+	var crd struct {
+		Spec struct {
+			Versions []struct {
+				Name   string `json:"name"`
+				Schema struct {
+					OpenAPIV3Schema spec.Schema `json:"openAPIV3Schema"`
+				} `json:"schema"`
+			} `json:"versions"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal(data, &crd); err != nil {
+		t.Fatalf("cannot parse custom resource definition, %v", err)
+	}
+
+	for _, version := range crd.Spec.Versions {
+		if version.Name == scalingv1.GroupVersion.Version {
+			return validate.NewSchemaValidator(&version.Schema.OpenAPIV3Schema, nil, "", strfmt.Default)
+		}
+	}
+
+	t.Fatalf("no schema for version %s", scalingv1.GroupVersion.Version)
+	return nil
+}
+
+// validatingStatusPatch rejects status patches which do not match the schema and passes all others on to the client
+func validatingStatusPatch(validator *validate.SchemaValidator) func(context.Context, client.Client, string, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+	return func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+
+		if result := validator.Validate(u); !result.IsValid() {
+			return fmt.Errorf("invalid status, %v", result.Errors)
+		}
+
+		return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+	}
 }
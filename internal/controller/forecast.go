@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+	"github.com/iljarotar/hybrid-scaler/internal/forecast"
+)
+
+const (
+	defaultForecastSeasons = 2
+	defaultForecastStep    = 300
+	defaultForecastHorizon = 1
+
+	// safeEncodingChars are the characters kubernetes uses for pod template hashes and generated name suffixes
+	safeEncodingChars = `[bcdfghjklmnpqrstvwxz2456789]`
+)
+
+var (
+	defaultForecastAlpha = resource.MustParse("0.5")
+	defaultForecastBeta  = resource.MustParse("0.1")
+	defaultForecastGamma = resource.MustParse("0.3")
+)
+
+// forecastUsage predicts the average cpu and memory usage per pod of the target `horizon` intervals ahead,
+// the history includes pods of earlier revisions, which are matched by the names the workload gives its pods
+func (r *HybridScalerReconciler) forecastUsage(ctx context.Context, w *workload, f scalingv1.Forecast, interval time.Duration, now time.Time) (cpu, memory float64, err error) {
+	step := time.Duration(ptr.Deref(f.Step, defaultForecastStep)) * time.Second
+	seasonLength := int(time.Duration(f.Season) * time.Second / step)
+	if seasonLength < 1 {
+		return 0, 0, fmt.Errorf("season of %ds is shorter than the step of %v", f.Season, step)
+	}
+
+	points := seasonLength * int(ptr.Deref(f.Seasons, defaultForecastSeasons))
+	horizon := forecastHorizon(interval*time.Duration(ptr.Deref(f.Horizon, defaultForecastHorizon)), step)
+	hw := holtWinters(f, seasonLength)
+
+	window := step
+	if window < time.Minute {
+		window = time.Minute
+	}
+
+	historyRange := promv1.Range{
+		Start: now.Add(-step * time.Duration(points-1)),
+		End:   now,
+		Step:  step,
+	}
+
+	selector := workloadPodSelector(w)
+
+	cpuQuery := fmt.Sprintf(`avg(sum by (pod) (rate(container_cpu_usage_seconds_total{%s}[%s])))`, selector, model.Duration(window))
+	cpuSeries, err := r.queryUsageHistory(ctx, cpuQuery, historyRange, points)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot query cpu usage history, %w", err)
+	}
+
+	cpu, err = hw.Forecast(cpuSeries, horizon)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot forecast cpu usage, %w", err)
+	}
+
+	memoryQuery := fmt.Sprintf(`avg(sum by (pod) (container_memory_working_set_bytes{%s}))`, selector)
+	memorySeries, err := r.queryUsageHistory(ctx, memoryQuery, historyRange, points)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot query memory usage history, %w", err)
+	}
+
+	memory, err = hw.Forecast(memorySeries, horizon)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot forecast memory usage, %w", err)
+	}
+
+	return cpu, memory, nil
+}
+
+// workloadPodSelector selects the containers of all pods the workload has ever created
+func workloadPodSelector(w *workload) string {
+	pattern := strings.ReplaceAll(workloadPodPattern(w), `\`, `\\`)
+	return fmt.Sprintf(`namespace="%s",pod=~"%s",container!=""`, w.GetNamespace(), pattern)
+}
+
+// workloadPodPattern matches the names of the workload's pods, which are `<name>-<pod-template-hash>-<suffix>`
+// for deployments and `<name>-<ordinal>` for stateful sets, so that workloads sharing a name prefix are not matched
+func workloadPodPattern(w *workload) string {
+	name := regexp.QuoteMeta(w.GetName())
+
+	if w.kind == kindStatefulSet {
+		return name + `-[0-9]+`
+	}
+
+	return name + `-` + safeEncodingChars + `+-` + safeEncodingChars + `{5}`
+}
+
+func (r *HybridScalerReconciler) queryUsageHistory(ctx context.Context, query string, historyRange promv1.Range, points int) ([]float64, error) {
+	res, _, err := r.PromAPI.QueryRange(ctx, query, historyRange)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix, ok := res.(model.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %s received from prometheus", res.Type())
+	}
+
+	if len(matrix) < 1 || len(matrix[0].Values) < 1 {
+		return nil, fmt.Errorf("no usage history found")
+	}
+
+	return resampleSeries(matrix[0].Values, historyRange.Start, historyRange.Step, points), nil
+}
+
+// resampleSeries places the samples on a regular grid of `points` steps starting at `start`,
+// gaps are filled with the previous value or the first known value at the beginning of the series
+func resampleSeries(samples []model.SamplePair, start time.Time, step time.Duration, points int) []float64 {
+	series := make([]float64, points)
+	known := make([]bool, points)
+
+	for _, sample := range samples {
+		index := int(math.Round(float64(sample.Timestamp.Time().Sub(start)) / float64(step)))
+		if index < 0 || index >= points || math.IsNaN(float64(sample.Value)) {
+			continue
+		}
+
+		series[index] = float64(sample.Value)
+		known[index] = true
+	}
+
+	first := -1
+	for i := range series {
+		if known[i] {
+			first = i
+			break
+		}
+	}
+
+	if first < 0 {
+		return series
+	}
+
+	for i := range series {
+		switch {
+		case known[i]:
+		case i < first:
+			series[i] = series[first]
+		default:
+			series[i] = series[i-1]
+		}
+	}
+
+	return series
+}
+
+// forecastHorizon converts the duration to look ahead into a number of steps, rounding up
+func forecastHorizon(ahead, step time.Duration) int {
+	horizon := int((ahead + step - 1) / step)
+	if horizon < 1 {
+		return 1
+	}
+
+	return horizon
+}
+
+func holtWinters(f scalingv1.Forecast, seasonLength int) forecast.HoltWinters {
+	return forecast.HoltWinters{
+		Alpha:        quantityOrDefault(f.Alpha, defaultForecastAlpha),
+		Beta:         quantityOrDefault(f.Beta, defaultForecastBeta),
+		Gamma:        quantityOrDefault(f.Gamma, defaultForecastGamma),
+		SeasonLength: seasonLength,
+	}
+}
+
+func quantityOrDefault(q *resource.Quantity, def resource.Quantity) float64 {
+	if q == nil {
+		return def.AsApproximateFloat64()
+	}
+
+	return q.AsApproximateFloat64()
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"fmt"
+
+	"gopkg.in/inf.v0"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+)
+
+const (
+	reasonDecisionErrors = "DecisionErrors"
+	reasonOverutilized   = "Overutilized"
+)
+
+var (
+	defaultFallbackLearningType           = scalingv1.LearningTypeHorizontal
+	defaultMaxConsecutiveErrors     int32 = 3
+	defaultMaxUtilization           int32 = 150
+	defaultMaxOverutilizedIntervals int32 = 3
+	defaultRecoveryIntervals        int32 = 3
+)
+
+// guardrails counts the consecutive failed decisions and overutilized intervals of the active strategy
+// and returns the reason why it is degraded, which is empty while it is healthy,
+// a degraded strategy only recovers after the number of healthy intervals of the policy
+func guardrails(status *scalingv1.HybridScalerStatus, policy *scalingv1.FallbackPolicy, decisionErr error, state *strategy.State) string {
+	if decisionErr != nil {
+		status.ConsecutiveErrors++
+	} else {
+		status.ConsecutiveErrors = 0
+	}
+
+	maxUtilization := inf.NewDec(int64(ptr.Deref(policy.MaxUtilization, defaultMaxUtilization)), 2)
+	if utilization := podUtilization(state.PodMetrics); utilization != nil && utilization.Cmp(maxUtilization) > 0 {
+		status.OverutilizedIntervals++
+	} else {
+		status.OverutilizedIntervals = 0
+	}
+
+	switch {
+	case status.ConsecutiveErrors >= ptr.Deref(policy.MaxConsecutiveErrors, defaultMaxConsecutiveErrors):
+		status.RecoveredIntervals = 0
+		return reasonDecisionErrors
+	case status.OverutilizedIntervals >= ptr.Deref(policy.MaxOverutilizedIntervals, defaultMaxOverutilizedIntervals):
+		status.RecoveredIntervals = 0
+		return reasonOverutilized
+	}
+
+	degraded := meta.FindStatusCondition(status.Conditions, scalingv1.ConditionDegraded)
+	if degraded == nil || degraded.Status != metav1.ConditionTrue {
+		status.RecoveredIntervals = 0
+		return ""
+	}
+
+	// the fallback resolves the overutilization which degraded the strategy, so the strategy stays degraded until
+	// it has been healthy for several intervals, otherwise the decisions would alternate between both strategies
+	if status.ConsecutiveErrors > 0 || status.OverutilizedIntervals > 0 {
+		status.RecoveredIntervals = 0
+	} else {
+		status.RecoveredIntervals++
+	}
+
+	if status.RecoveredIntervals >= ptr.Deref(policy.RecoveryIntervals, defaultRecoveryIntervals) {
+		status.RecoveredIntervals = 0
+		return ""
+	}
+
+	return degraded.Reason
+}
+
+// podUtilization returns the higher share of the cpu and memory requests which is used, nil if there are no requests
+func podUtilization(m strategy.PodMetrics) *inf.Dec {
+	var utilization *inf.Dec
+
+	for _, r := range []struct{ usage, requests *inf.Dec }{
+		{m.ResourceUsage.CPU, m.Requests.CPU},
+		{m.ResourceUsage.Memory, m.Requests.Memory},
+	} {
+		if r.usage == nil || r.requests == nil || r.requests.Sign() == 0 {
+			continue
+		}
+
+		ratio := new(inf.Dec).QuoRound(r.usage, r.requests, 8, inf.RoundHalfUp)
+		if utilization == nil || ratio.Cmp(utilization) > 0 {
+			utilization = ratio
+		}
+	}
+
+	return utilization
+}
+
+// fallbackStrategy returns the strategy which replaces the active strategy while it is degraded
+func fallbackStrategy(spec scalingv1.HybridScalerSpec) (scalingv1.LearningType, strategy.ScalingStrategy, error) {
+	learningType := spec.Fallback.LearningType
+	if learningType == "" {
+		learningType = defaultFallbackLearningType
+	}
+
+	scalingStrategy, err := getScalingStrategy(learningType, spec.QLearningParams, spec.Fallback.RuleBasedParams)
+	return learningType, scalingStrategy, err
+}
+
+func (r *HybridScalerReconciler) setDegradedCondition(scaler *scalingv1.HybridScaler, reason string, fallback scalingv1.LearningType) bool {
+	condition := metav1.Condition{
+		Type:               scalingv1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Healthy",
+		Message:            fmt.Sprintf("the %s strategy makes the decisions", scaler.Spec.LearningType),
+		ObservedGeneration: scaler.Generation,
+	}
+
+	switch reason {
+	case reasonDecisionErrors:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reason
+		condition.Message = fmt.Sprintf("the %s strategy makes the decisions, since the %s strategy failed %d consecutive decisions",
+			fallback, scaler.Spec.LearningType, scaler.Status.ConsecutiveErrors)
+	case reasonOverutilized:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = reason
+		condition.Message = fmt.Sprintf("the %s strategy makes the decisions, since the utilization of the %s strategy exceeded the maximum for %d intervals",
+			fallback, scaler.Spec.LearningType, scaler.Status.OverutilizedIntervals)
+	}
+
+	// the condition is kept while the strategy stays degraded for the same reason, so its counts are those at which it degraded
+	existing := meta.FindStatusCondition(scaler.Status.Conditions, condition.Type)
+	if condition.Status == metav1.ConditionTrue && existing != nil && existing.Status == metav1.ConditionTrue && existing.Reason == reason {
+		return false
+	}
+
+	changed := setCondition(&scaler.Status.Conditions, condition)
+	if changed && condition.Status == metav1.ConditionTrue {
+		r.Recorder.Event(scaler, corev1.EventTypeWarning, condition.Reason, condition.Message)
+	}
+
+	return changed
+}
@@ -29,14 +29,21 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+	"github.com/iljarotar/hybrid-scaler/internal/classic"
 	"github.com/iljarotar/hybrid-scaler/internal/reinforcement"
+	"github.com/iljarotar/hybrid-scaler/internal/scaling"
 	"github.com/iljarotar/hybrid-scaler/internal/strategy"
 
 	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
@@ -44,22 +51,41 @@ import (
 )
 
 var (
-	ownerKey = ".metadata.controller"
+	ownerKey       = ".metadata.controller"
+	scaleTargetKey = ".spec.scaleTargetRef.name"
+	policyKey      = ".spec.policy"
+)
+
+var (
+	defaultRuleTolerance       = resource.MustParse("0.1")
+	defaultRuleStep      int32 = 1
+	defaultTraceDecay          = resource.MustParse("0")
+	defaultTraceLength   int32 = 1
 )
 
 // HybridScalerReconciler reconciles a HybridScaler object
 type HybridScalerReconciler struct {
 	client.Client
-	PromAPI promv1.API
-	Scheme  *runtime.Scheme
+	PromAPI  promv1.API
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=scaling.autoscaling.custom,resources=hybridscalers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=scaling.autoscaling.custom,resources=hybridscalers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=scaling.autoscaling.custom,resources=hybridscalers/finalizers,verbs=update
+//+kubebuilder:rbac:groups=scaling.autoscaling.custom,resources=learninggroups,verbs=get;list;watch
+//+kubebuilder:rbac:groups=scaling.autoscaling.custom,resources=learninggroups/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods/resize,verbs=patch
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 //+kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
-//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=autoscaling.k8s.io,resources=verticalpodautoscalers,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=resourcequotas;limitranges,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -81,51 +107,203 @@ func (r *HybridScalerReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	if err := r.Get(ctx, req.NamespacedName, &scaler); err != nil {
 		if errors.IsNotFound(err) {
 			logger.Error(err, "no scaler found", "namespaced name", req.NamespacedName)
+			forgetDecisions(req.Namespace, req.Name)
 			return ctrl.Result{}, client.IgnoreNotFound(err)
 		}
 
 		logger.Error(err, "cannot fetch scaler", "namespaced name", req.NamespacedName)
 		return ctrl.Result{}, nil
 	}
+
+	if scaler.Spec.Policy != "" {
+		var policy scalingv1.HybridScalerPolicy
+		if err := r.Get(ctx, client.ObjectKey{Name: scaler.Spec.Policy}, &policy); err != nil {
+			logger.Error(err, "cannot fetch scaler policy", "policy", scaler.Spec.Policy)
+			return result, nil
+		}
+
+		scaler.Spec = withPolicy(scaler.Spec, policy.Spec)
+	}
+
 	if scaler.Spec.Interval != nil {
 		result.RequeueAfter = time.Duration(*scaler.Spec.Interval) * time.Second
 	}
 
-	var deployment appsv1.Deployment
-	if err := r.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: scaler.Spec.ScaleTargetRef.Name}, &deployment); err != nil {
+	// without an upper bound every strategy would scale the target to zero replicas
+	if incomplete, changed := r.setIncompleteSpecCondition(&scaler); incomplete {
+		logger.Info("skipping scaler without max replicas", "policy", scaler.Spec.Policy)
+
+		if changed {
+			if err := r.applyStatus(ctx, &scaler); err != nil {
+				logger.Error(err, "unable to update scaler status", "status", scaler.Status)
+			}
+		}
+
+		return result, nil
+	}
+
+	target, err := r.getWorkload(ctx, &scaler)
+	if err != nil {
 		if errors.IsNotFound(err) {
-			logger.Error(err, "no deployment found for scaler", "scaler", scaler)
+			logger.Error(err, "no scale target found for scaler", "scaler", scaler)
 			return result, nil
 		}
 
-		logger.Error(err, "cannot fetch deployment for scaler", "scaler", scaler)
+		logger.Error(err, "cannot fetch scale target for scaler", "scaler", scaler)
+		return result, nil
+	}
+
+	conflicted, statusChanged, err := r.checkConflicts(ctx, &scaler)
+	if err != nil {
+		logger.Error(err, "cannot check for conflicting autoscalers", "scaler", scaler)
+		return result, nil
+	}
+
+	if conflicted {
+		logger.Info("skipping due to conflicting autoscalers", "target", target.GetName())
+
+		if statusChanged {
+			if err := r.applyStatus(ctx, &scaler); err != nil {
+				logger.Error(err, "unable to update scaler status", "status", scaler.Status)
+			}
+		}
+
 		return result, nil
 	}
 
-	scaler.Status.Replicas = deployment.Status.Replicas
-	scaler.Status.ContainerResources = getContainerResources(deployment.Spec.Template.Spec.Containers)
+	scaler.Status.Replicas = target.replicas
+	scaler.Status.ContainerResources = currentContainerResources(scaler, target)
+
+	pods, err := r.listPods(ctx, target)
+	if err != nil {
+		logger.Error(err, "cannot list pods of scale target", "target", target.GetName())
+		return result, nil
+	}
+
+	now := time.Now()
+
+	unschedulable, expired := unschedulablePods(pods, pendingTimeout(scaler.Spec), now)
+	if unschedulable > scaler.Status.UnschedulablePods {
+		scaler.Status.UnschedulablePods = unschedulable
+		statusChanged = true
+	}
+
+	if expired > 0 && scaler.Status.LastScaleUp != nil {
+		logger.Info("rolling back last scale-up due to unschedulable pods", "target", target.GetName(), "unschedulable", expired)
+
+		if err := r.rollBackScaleUp(ctx, &scaler, target, expired, now); err != nil {
+			logger.Error(err, "unable to roll back last scale-up", "target", target.GetName())
+		}
+
+		return result, nil
+	}
+
+	if target.rolloutInProgress || resizeInProgress(pods) {
+		logger.Info("skipping while rollout is in progress", "target", target.GetName())
+
+		if statusChanged || !scaler.Status.RolloutPending {
+			scaler.Status.RolloutPending = true
+			if err := r.applyStatus(ctx, &scaler); err != nil {
+				logger.Error(err, "unable to update scaler status", "status", scaler.Status)
+			}
+		}
+
+		return result, nil
+	}
+
+	if scaler.Status.RolloutPending {
+		scaler.Status.RolloutPending = false
+		scaler.Status.LastRolloutTime = &metav1.Time{Time: now}
+		statusChanged = true
+	}
+
+	requeueAfter := remainingWarmup(scaler.Status, scaler.Spec, now)
+	if requeueAfter > 0 {
+		logger.Info("skipping during warm-up after rollout", "target", target.GetName(), "remaining", requeueAfter)
+	} else {
+		requeueAfter = untilNextDecision(scaler.Status, result.RequeueAfter, now)
+
+		if requeueAfter > 0 && len(oomKills(pods, scaler.Status.LastScaleTime)) > 0 {
+			logger.Info("deciding early after out-of-memory kills", "target", target.GetName())
+			requeueAfter = 0
+		}
+	}
+
+	if requeueAfter > 0 {
+		if statusChanged {
+			if err := r.applyStatus(ctx, &scaler); err != nil {
+				logger.Error(err, "unable to update scaler status", "status", scaler.Status)
+			}
+		}
+
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
 
-	var replicaSets appsv1.ReplicaSetList
-	if err := r.List(ctx, &replicaSets, client.InNamespace(req.Namespace), client.MatchingFields{ownerKey: deployment.Name}); err != nil {
-		logger.Error(err, "cannot list replica sets for deployment", "deployment", deployment)
+	profile, err := activeProfile(scaler.Spec.Profiles, now)
+	if err != nil {
+		logger.Error(err, "cannot determine active scaling profile", "profiles", scaler.Spec.Profiles)
 		return result, nil
 	}
 
-	pods := make([]corev1.Pod, 0)
+	profileName := ""
+	if profile != nil {
+		profileName = profile.Name
+	}
+
+	if scaler.Status.ActiveProfile != profileName {
+		logger.Info("switching scaling profile", "from", scaler.Status.ActiveProfile, "to", profileName)
+		scaler.Status.ActiveProfile = profileName
+		statusChanged = true
+	}
+
+	spec := withProfile(scaler.Spec, profile)
 
-	for _, rs := range replicaSets.Items {
-		var podList corev1.PodList
-		if err := r.List(ctx, &podList, client.InNamespace(req.Namespace), client.MatchingFields{ownerKey: rs.Name}); err != nil {
-			logger.Error(err, "cannot list pods for deployment", "deployment", deployment)
+	// the replicas are owned by a HorizontalPodAutoscaler when coexisting with resources only, so scaling to zero is left to it
+	if spec.ScaleToZero != nil && spec.Coexistence != scalingv1.CoexistenceModeResourcesOnly {
+		active, err := r.queryActivity(ctx, spec.ScaleToZero.ActivityQuery, now)
+		if err != nil {
+			logger.Error(err, "unable to determine activity of scale target", "target", target.GetName())
 			return result, nil
 		}
 
-		pods = append(pods, podList.Items...)
+		if active || scaler.Status.LastActiveTime == nil {
+			scaler.Status.LastActiveTime = &metav1.Time{Time: now}
+		}
+
+		if replicas, ok := scaleToZeroReplicas(spec, scaler.Status, active, now); ok {
+			logger.Info("scaling idle target", "target", target.GetName(), "replicas", replicas, "active", active)
+			scaler.Status.LastScaleTime = &metav1.Time{Time: now}
+
+			if err := r.applyStatus(ctx, &scaler); err != nil {
+				logger.Error(err, "unable to update scaler status", "status", scaler.Status)
+				return result, nil
+			}
+
+			if replicas != target.replicas {
+				if err := r.applyWorkload(ctx, target, &replicas, nil); err != nil {
+					logger.Error(err, "unable to apply scale target spec", "target", target.GetName())
+				}
+			}
+
+			return result, nil
+		}
+	}
+
+	revisionLabel, revisionHash, err := r.currentRevision(ctx, target)
+	if err != nil {
+		logger.Error(err, "cannot determine current revision of scale target", "target", target.GetName())
+		return result, nil
+	}
+
+	sampledPods := samplePods(pods, spec.PodFilter, podRevision{label: revisionLabel, hash: revisionHash}, now)
+	if scaler.Status.SampledPods != int32(len(sampledPods)) {
+		scaler.Status.SampledPods = int32(len(sampledPods))
+		statusChanged = true
 	}
 
 	var averageCpuUsage float64
 	var averageMemoryUsage float64
-	for _, pod := range pods {
+	for _, pod := range sampledPods {
 		query := fmt.Sprintf(`rate(container_cpu_usage_seconds_total{pod="%s"}[1m])`, pod.Name)
 		res, _, err := r.PromAPI.Query(ctx, query, time.Now())
 		if err != nil {
@@ -169,16 +347,23 @@ func (r *HybridScalerReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	if averageCpuUsage == 0 || averageMemoryUsage == 0 {
 		podNames := make([]string, 0)
-		for _, pod := range pods {
+		for _, pod := range sampledPods {
 			podNames = append(podNames, pod.Name)
 		}
 
 		logger.Info("skipping due to missing metrics", "pods", podNames)
+
+		if statusChanged {
+			if err := r.applyStatus(ctx, &scaler); err != nil {
+				logger.Error(err, "unable to update scaler status", "status", scaler.Status)
+			}
+		}
+
 		return result, nil
 	}
 
-	averageCpuUsage /= float64(len(pods))
-	averageMemoryUsage /= float64(len(pods))
+	averageCpuUsage /= float64(len(sampledPods))
+	averageMemoryUsage /= float64(len(sampledPods))
 
 	podMetrics := scalingv1.PodMetrics{
 		ResourceUsage: corev1.ResourceList{
@@ -186,51 +371,173 @@ func (r *HybridScalerReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			corev1.ResourceMemory: *resource.NewDecimalQuantity(*float64ToDec(averageMemoryUsage), resource.DecimalExponent),
 		},
 	}
+
+	if spec.Forecast != nil {
+		forecastCpuUsage, forecastMemoryUsage, err := r.forecastUsage(ctx, target, *spec.Forecast, result.RequeueAfter, now)
+		if err != nil {
+			logger.Error(err, "unable to forecast usage, scaling on current usage only", "target", target.GetName())
+		} else {
+			podMetrics.ForecastResourceUsage = corev1.ResourceList{
+				corev1.ResourceCPU:    *resource.NewDecimalQuantity(*float64ToDec(forecastCpuUsage), resource.DecimalExponent),
+				corev1.ResourceMemory: *resource.NewDecimalQuantity(*float64ToDec(forecastMemoryUsage), resource.DecimalExponent),
+			}
+		}
+	}
+
 	scaler.Status.PodMetrics = podMetrics
 
-	state, err := prepareState(scaler.Status, scaler.Spec)
+	if spec.Recommender != nil {
+		if err := r.recordUsage(ctx, &scaler, target, sampledPods, result.RequeueAfter, now); err != nil {
+			logger.Error(err, "unable to record container usage", "target", target.GetName())
+			return result, nil
+		}
+	}
+
+	throttlingRatios, err := r.throttlingRatios(ctx, target.GetNamespace(), pods, result.RequeueAfter, now)
+	if err != nil {
+		logger.Error(err, "unable to query cpu throttling, continuing without", "target", target.GetName())
+	}
+	scaler.Status.ContainerPressure = containerPressure(oomKills(pods, scaler.Status.LastScaleTime), throttlingRatios)
+
+	state, err := prepareState(scaler.Status, spec, now)
 	if err != nil {
-		logger.Error(err, "cannot prepare scaling strategy state", "status", scaler.Status, "spec", scaler.Spec)
+		logger.Error(err, "cannot prepare scaling strategy state", "status", scaler.Status, "spec", spec)
 		return result, nil
 	}
 	logger.Info("prepared state for scaling strategy", "state", state)
 
-	scalingStrategy := getScalingStrategy(scaler.Spec.LearningType, scaler.Spec.QLearningParams)
+	capacities, err := r.nodeCapacities(ctx, target.template)
+	if err != nil {
+		logger.Error(err, "unable to read node capacities, continuing without", "target", target.GetName())
+	}
+	state.NodeCapacities = capacities
 
-	decision, learningState, err := scalingStrategy.MakeDecision(state, scaler.Status.LearningState)
+	limits, err := r.namespaceLimits(ctx, target.GetNamespace(), pods)
 	if err != nil {
-		logger.Error(err, "cannot make a scaling decision", "state", state)
+		logger.Error(err, "unable to read resource quotas and limit ranges", "namespace", target.GetNamespace())
 		return result, nil
 	}
-	scaler.Status.LearningState = learningState
 
-	if err := r.Status().Update(ctx, &scaler); err != nil {
-		logger.Error(err, "unable to update scaler status", "status", scaler.Status)
+	scalingStrategy, err := getScalingStrategy(scaler.Spec.LearningType, scaler.Spec.QLearningParams, scaler.Spec.RuleBasedParams)
+	if err != nil {
+		logger.Error(err, "unable to select scaling strategy")
 		return result, nil
 	}
 
-	newResources := interpretResourceScaling(decision)
-	newContainers := make([]corev1.Container, 0)
+	if err := r.setLearningGroupCondition(ctx, &scaler); err != nil {
+		logger.Error(err, "unable to compare the scaler with its learning group", "group", scaler.Spec.LearningGroup)
+	}
+
+	activeStrategy := scaler.Spec.LearningType
+	var decision *strategy.ScalingDecision
+	var learningState []byte
+	if scaler.Spec.LearningGroup != "" && scaler.Spec.LearningType == scalingv1.LearningTypeQLearning {
+		decision, learningState, err = r.groupDecision(ctx, scaler.Spec.LearningGroup, state, scaler.Status.LearningState)
+	} else {
+		decision, learningState, err = scalingStrategy.MakeDecision(state, scaler.Status.LearningState)
+	}
+	if err != nil {
+		logger.Error(err, "cannot make a scaling decision", "state", state)
+	} else {
+		scaler.Status.LearningState = learningState
+	}
+
+	if spec.Fallback != nil {
+		reason := guardrails(&scaler.Status, spec.Fallback, err, state)
+		fallbackType, fallback, fallbackErr := fallbackStrategy(spec)
+		r.setDegradedCondition(&scaler, reason, fallbackType)
 
-	deployment.Spec.Replicas = &decision.Replicas
+		if reason != "" {
+			if fallbackErr != nil {
+				logger.Error(fallbackErr, "unable to select fallback strategy")
+				return result, nil
+			}
 
-	for _, container := range deployment.Spec.Template.Spec.Containers {
-		resources, ok := newResources[container.Name]
-		if !ok {
-			logger.Error(err, "unable to find new resources for container", "container", container)
-			return result, nil
+			logger.Info("active strategy is degraded, falling back", "reason", reason, "fallback", fallbackType)
+			activeStrategy = fallbackType
+			decision, _, err = fallback.MakeDecision(state, nil)
+			if err != nil {
+				logger.Error(err, "cannot make a fallback scaling decision", "state", state)
+			}
 		}
+	}
 
-		container.Resources.Requests = resources.Requests
-		container.Resources.Limits = resources.Limits
+	if err != nil {
+		if err := r.applyStatus(ctx, &scaler); err != nil {
+			logger.Error(err, "unable to update scaler status", "status", scaler.Status)
+		}
+		return result, nil
+	}
+	scaler.Status.LastScaleTime = &metav1.Time{Time: now}
+
+	scaler.Status.ShadowDecisions = shadowDecisions(scaler.Spec.ShadowStrategies, scaler.Status.ShadowDecisions, state, limits, spec)
 
-		newContainers = append(newContainers, container)
+	fitted, limitedBy := constrainDecision(decision, state, limits)
+	if fitted {
+		logger.Info("scaled pod resources down to fit on a node", "replicas", decision.Replicas, "resources", decision.ContainerResources)
 	}
+	r.setQuotaLimitedCondition(&scaler, limitedBy)
 
-	deployment.Spec.Template.Spec.Containers = newContainers
+	cost, err := estimatedCost(state, decision, spec.QLearningParams)
+	if err != nil {
+		logger.Error(err, "unable to estimate the cost of the scaling decision", "decision", decision)
+	}
+	scaler.Status.EstimatedCost = cost
+	recordDecisions(&scaler, string(activeStrategy), decision.Replicas)
+
+	newResources := interpretResourceScaling(decision, spec.ResourcePolicy)
+	scaler.Status.LastScaleUp = scaleUp(target.replicas, scaler.Status.ContainerResources, decision.Replicas, newResources)
+	scaler.Status.UnschedulablePods = unschedulable
+	updateTemplate := !state.FixedResources
+
+	if updateTemplate && inPlaceUpdate(scaler.Spec.UpdateMode) {
+		scaler.Status.ContainerResources = newResources
+
+		resized, err := r.resizePods(ctx, pods, newResources)
+		switch {
+		case err == nil:
+			// writing the template would replace the pods which were just resized, pods created from the outdated
+			// template are resized on the next reconciliation instead
+			updateTemplate = !target.replacesPods
+			if resized > 0 {
+				scaler.Status.RolloutPending = true
+			}
+		case scaler.Spec.UpdateMode == scalingv1.UpdateModeInPlace:
+			updateTemplate = false
+			logger.Error(err, "unable to resize pods in place", "target", target.GetName())
+		default:
+			logger.Error(err, "unable to resize pods in place, falling back to rollout", "target", target.GetName())
+		}
+	}
+
+	if updateTemplate && templateResourcesChanged(target.template.Spec.Containers, newResources) {
+		scaler.Status.RolloutPending = true
+	}
 
-	if err := r.Update(ctx, &deployment); err != nil {
-		logger.Error(err, "unable to update deployment spec")
+	if err := r.applyStatus(ctx, &scaler); err != nil {
+		logger.Error(err, "unable to update scaler status", "status", scaler.Status)
+		return result, nil
+	}
+
+	var templateResources map[string]scalingv1.ContainerResources
+	if updateTemplate {
+		for _, container := range target.template.Spec.Containers {
+			if _, ok := newResources[container.Name]; !ok {
+				logger.Error(fmt.Errorf("no resources for container %s in scaling decision", container.Name), "unable to find new resources for container", "container", container)
+				return result, nil
+			}
+		}
+
+		templateResources = newResources
+	}
+
+	var replicas *int32
+	if !state.FixedReplicas {
+		replicas = &decision.Replicas
+	}
+
+	if err := r.applyWorkload(ctx, target, replicas, templateResources); err != nil {
+		logger.Error(err, "unable to apply scale target spec", "target", target.GetName())
 		return result, nil
 	}
 
@@ -244,8 +551,11 @@ func (r *HybridScalerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&scalingv1.HybridScaler{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		For(&scalingv1.HybridScaler{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(r.scalersForWorkload(kindDeployment))).
+		Watches(&appsv1.StatefulSet{}, handler.EnqueueRequestsFromMapFunc(r.scalersForWorkload(kindStatefulSet))).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.scalersForPod), builder.WithPredicates(podStatusChangedPredicate())).
+		Watches(&scalingv1.HybridScalerPolicy{}, handler.EnqueueRequestsFromMapFunc(r.scalersForPolicy), builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
 
@@ -257,7 +567,7 @@ func addIndices(mgr ctrl.Manager) error {
 			return nil
 		}
 
-		if owner.Kind != "Deployment" {
+		if owner.Kind != kindDeployment {
 			return nil
 		}
 
@@ -273,7 +583,7 @@ func addIndices(mgr ctrl.Manager) error {
 			return nil
 		}
 
-		if owner.Kind != "ReplicaSet" {
+		if owner.Kind != "ReplicaSet" && owner.Kind != kindStatefulSet {
 			return nil
 		}
 
@@ -282,7 +592,106 @@ func addIndices(mgr ctrl.Manager) error {
 		return err
 	}
 
-	return nil
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &scalingv1.HybridScaler{}, scaleTargetKey, func(rawObj client.Object) []string {
+		scaler := rawObj.(*scalingv1.HybridScaler)
+		return []string{scaler.Spec.ScaleTargetRef.Name}
+	}); err != nil {
+		return err
+	}
+
+	return addPolicyIndex(mgr)
+}
+
+// scalersForWorkload maps a workload of the given kind to the scalers targeting it
+func (r *HybridScalerReconciler) scalersForWorkload(kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		return r.scalerRequests(ctx, kind, obj.GetNamespace(), obj.GetName())
+	}
+}
+
+// scalersForPod maps a pod to the scalers targeting the workload owning the pod
+func (r *HybridScalerReconciler) scalersForPod(ctx context.Context, obj client.Object) []reconcile.Request {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil {
+		return nil
+	}
+
+	switch owner.Kind {
+	case kindStatefulSet:
+		return r.scalerRequests(ctx, kindStatefulSet, obj.GetNamespace(), owner.Name)
+
+	case "ReplicaSet":
+		var replicaSet appsv1.ReplicaSet
+		if err := r.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: owner.Name}, &replicaSet); err != nil {
+			return nil
+		}
+
+		deployment := metav1.GetControllerOf(&replicaSet)
+		if deployment == nil || deployment.Kind != kindDeployment {
+			return nil
+		}
+
+		return r.scalerRequests(ctx, kindDeployment, obj.GetNamespace(), deployment.Name)
+
+	default:
+		return nil
+	}
+}
+
+func (r *HybridScalerReconciler) scalerRequests(ctx context.Context, kind, namespace, name string) []reconcile.Request {
+	var scalers scalingv1.HybridScalerList
+	if err := r.List(ctx, &scalers, client.InNamespace(namespace), client.MatchingFields{scaleTargetKey: name}); err != nil {
+		log.FromContext(ctx).Error(err, "cannot list scalers for workload", "kind", kind, "name", name)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for _, scaler := range scalers.Items {
+		if scaler.Spec.ScaleTargetRef.Kind != kind {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&scaler)})
+	}
+
+	return requests
+}
+
+// podStatusChangedPredicate passes pod creations, deletions, phase transitions and out-of-memory kills, but no other updates
+func podStatusChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+
+			if oldPod.Status.Phase != newPod.Status.Phase {
+				return true
+			}
+
+			return restarts(oldPod) != restarts(newPod) && len(oomKills([]corev1.Pod{*newPod}, nil)) > 0
+		},
+	}
+}
+
+// untilNextDecision returns how long to wait until the next scaling decision is due
+func untilNextDecision(status scalingv1.HybridScalerStatus, interval time.Duration, now time.Time) time.Duration {
+	if status.LastScaleTime == nil {
+		return 0
+	}
+
+	next := status.LastScaleTime.Add(interval)
+	if !now.Before(next) {
+		return 0
+	}
+
+	return next.Sub(now)
 }
 
 func getContainerResources(containers []corev1.Container) map[string]scalingv1.ContainerResources {
@@ -302,7 +711,25 @@ func getContainerResources(containers []corev1.Container) map[string]scalingv1.C
 	return resources
 }
 
-func prepareState(status scalingv1.HybridScalerStatus, spec scalingv1.HybridScalerSpec) (*strategy.State, error) {
+// currentContainerResources returns the resources the scaler works with, which are taken from the pod template
+// unless pods are resized in place without updating the template, in which case the last applied resources are kept
+func currentContainerResources(scaler scalingv1.HybridScaler, w *workload) map[string]scalingv1.ContainerResources {
+	templateResources := getContainerResources(w.template.Spec.Containers)
+
+	if !inPlaceUpdate(scaler.Spec.UpdateMode) || !w.replacesPods || len(scaler.Status.ContainerResources) != len(templateResources) {
+		return templateResources
+	}
+
+	for name := range templateResources {
+		if _, ok := scaler.Status.ContainerResources[name]; !ok {
+			return templateResources
+		}
+	}
+
+	return scaler.Status.ContainerResources
+}
+
+func prepareState(status scalingv1.HybridScalerStatus, spec scalingv1.HybridScalerSpec, now time.Time) (*strategy.State, error) {
 	podCpuRequests := inf.NewDec(0, 0)
 	podMemoryRequests := inf.NewDec(0, 0)
 	podCpuLimits := inf.NewDec(0, 0)
@@ -352,6 +779,13 @@ func prepareState(status scalingv1.HybridScalerStatus, spec scalingv1.HybridScal
 		},
 	}
 
+	if forecastUsage := status.PodMetrics.ForecastResourceUsage; len(forecastUsage) > 0 {
+		podMetrics.ForecastUsage = &strategy.ResourcesList{
+			CPU:    forecastUsage.Cpu().AsDec(),
+			Memory: forecastUsage.Memory().AsDec(),
+		}
+	}
+
 	constraints := strategy.Constraints{
 		MinReplicas: ptr.Deref(spec.MinReplicas, 0),
 		MaxReplicas: ptr.Deref(spec.MaxReplicas, 0),
@@ -363,8 +797,10 @@ func prepareState(status scalingv1.HybridScalerStatus, spec scalingv1.HybridScal
 			CPU:    spec.ResourcePolicy.MaxAllowed.Cpu().AsDec(),
 			Memory: spec.ResourcePolicy.MaxAllowed.Memory().AsDec(),
 		},
-		LimitsToRequestsRatioCPU:    spec.ResourcePolicy.LimitsToRequestsRatioCPU.AsDec(),
-		LimitsToRequestsRatioMemory: spec.ResourcePolicy.LimitsToRequestsRatioMemory.AsDec(),
+		LimitsToRequestsRatioCPU:    quantityOrZeroDec(spec.ResourcePolicy.LimitsToRequestsRatioCPU),
+		LimitsToRequestsRatioMemory: quantityOrZeroDec(spec.ResourcePolicy.LimitsToRequestsRatioMemory),
+		FixedReplicas:               spec.Coexistence == scalingv1.CoexistenceModeResourcesOnly,
+		FixedResources:              spec.Coexistence == scalingv1.CoexistenceModeReplicasOnly,
 	}
 
 	targetCpuUtilization, ok := spec.ResourcePolicy.TargetUtilization[corev1.ResourceCPU]
@@ -382,28 +818,113 @@ func prepareState(status scalingv1.HybridScalerStatus, spec scalingv1.HybridScal
 		Memory: inf.NewDec(int64(targetMemoryUtilization), 2),
 	}
 
+	location, err := time.LoadLocation(ptr.Deref(spec.QLearningParams.TimeZone, "UTC"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load time zone, %w", err)
+	}
+
 	state := &strategy.State{
 		Replicas:           status.Replicas,
 		Constraints:        constraints,
 		ContainerResources: containerResources,
 		PodMetrics:         podMetrics,
 		TargetUtilization:  targetUtilization,
+		Time:               now.In(location),
+		Pressure:           preparePressure(status.ContainerPressure),
+		UnschedulablePods:  status.UnschedulablePods,
+		VerticalPolicy:     prepareVerticalPolicy(spec),
+	}
+
+	if spec.Recommender != nil {
+		recommendations, err := usageRecommendations(status, *spec.Recommender, now)
+		if err != nil {
+			return nil, err
+		}
+
+		state.UsageRecommendations = recommendations
 	}
 
 	return state, nil
 }
 
-func interpretResourceScaling(decision *strategy.ScalingDecision) map[string]scalingv1.ContainerResources {
+func prepareVerticalPolicy(spec scalingv1.HybridScalerSpec) strategy.VerticalPolicy {
+	headroom := spec.ResourcePolicy.Headroom
+	rounding := spec.ResourcePolicy.Rounding
+	pressure := spec.PressurePolicy
+
+	return strategy.VerticalPolicy{
+		Headroom: strategy.ResourcesList{
+			CPU:    quantityDec(headroom, corev1.ResourceCPU),
+			Memory: quantityDec(headroom, corev1.ResourceMemory),
+		},
+		Rounding:             prepareRounding(rounding),
+		OOMBumpFactor:        quantityOrDefaultDec(pressure.OOMBumpFactor, defaultOOMBumpFactor),
+		ThrottlingThreshold:  quantityOrDefaultDec(pressure.ThrottlingThreshold, defaultThrottlingThreshold),
+		ThrottlingBumpFactor: quantityOrDefaultDec(pressure.ThrottlingBumpFactor, defaultThrottlingBumpFactor),
+	}
+}
+
+// quantityDec returns the quantity of the resource as decimal or nil if it is not in the map
+func quantityDec(quantities map[corev1.ResourceName]resource.Quantity, name corev1.ResourceName) *inf.Dec {
+	quantity, ok := quantities[name]
+	if !ok {
+		return nil
+	}
+
+	return quantity.AsDec()
+}
+
+func prepareRounding(rounding map[corev1.ResourceName]scalingv1.RoundingPolicy) strategy.RoundingPolicies {
+	convert := func(policy scalingv1.RoundingPolicy) strategy.Rounding {
+		var granularity *inf.Dec
+		if policy.Granularity != nil {
+			granularity = policy.Granularity.AsDec()
+		}
+
+		return strategy.Rounding{
+			Granularity: granularity,
+			Direction:   strategy.RoundingDirection(policy.Direction),
+		}
+	}
+
+	return strategy.RoundingPolicies{
+		CPU:    convert(rounding[corev1.ResourceCPU]),
+		Memory: convert(rounding[corev1.ResourceMemory]),
+	}
+}
+
+// enforceFixedConstraints resets the parts of the decision the scaler does not own
+func enforceFixedConstraints(decision *strategy.ScalingDecision, state *strategy.State) {
+	if state.FixedReplicas {
+		decision.Replicas = state.Replicas
+	}
+
+	if state.FixedResources {
+		decision.ContainerResources = state.ContainerResources
+	}
+}
+
+// interpretResourceScaling converts the decision's resources to resource lists rounded according to the resource policy,
+// memory is formatted like its granularity, which keeps binary steps such as 16Mi readable
+func interpretResourceScaling(decision *strategy.ScalingDecision, policy scalingv1.ResourcePolicy) map[string]scalingv1.ContainerResources {
 	containerResources := make(map[string]scalingv1.ContainerResources)
+	rounding := prepareRounding(policy.Rounding)
+
+	memoryFormat := resource.DecimalSI
+	if granularity := policy.Rounding[corev1.ResourceMemory].Granularity; granularity != nil && granularity.Format == resource.BinarySI {
+		memoryFormat = resource.BinarySI
+	}
 
 	for name, resources := range decision.ContainerResources {
 		requests := make(corev1.ResourceList)
 		limits := make(corev1.ResourceList)
 
+		resources = scaling.RoundResources(resources, rounding)
+
 		requests[corev1.ResourceCPU] = *resource.NewDecimalQuantity(*resources.Requests.CPU, resource.DecimalExponent)
-		requests[corev1.ResourceMemory] = *resource.NewDecimalQuantity(*resources.Requests.Memory, resource.DecimalSI)
+		requests[corev1.ResourceMemory] = *resource.NewDecimalQuantity(*resources.Requests.Memory, memoryFormat)
 		limits[corev1.ResourceCPU] = *resource.NewDecimalQuantity(*resources.Limits.CPU, resource.DecimalExponent)
-		limits[corev1.ResourceMemory] = *resource.NewDecimalQuantity(*resources.Limits.Memory, resource.DecimalSI)
+		limits[corev1.ResourceMemory] = *resource.NewDecimalQuantity(*resources.Limits.Memory, memoryFormat)
 
 		containerResources[name] = scalingv1.ContainerResources{
 			Requests: requests,
@@ -414,19 +935,40 @@ func interpretResourceScaling(decision *strategy.ScalingDecision) map[string]sca
 	return containerResources
 }
 
-func getScalingStrategy(learningType scalingv1.LearningType, qParams scalingv1.QLearningParams) strategy.ScalingStrategy {
+// getScalingStrategy returns the strategy of the learning type, unknown learning types are rejected rather than ignored
+func getScalingStrategy(learningType scalingv1.LearningType, qParams scalingv1.QLearningParams, ruleParams scalingv1.RuleBasedParams) (strategy.ScalingStrategy, error) {
 	switch learningType {
 	case scalingv1.LearningTypeQLearning:
-		cpuCost := qParams.CpuCost.AsDec()
-		memoryCost := qParams.MemoryCost.AsDec()
-		underprovisioningPenalty := qParams.UnderprovisioningPenalty.AsDec()
-		alpha := qParams.LearningRate.AsDec()
-		gamma := qParams.DiscountFactor.AsDec()
-		epsilon := qParams.Epsilon.AsDec()
-
-		return reinforcement.NewQAgent(cpuCost, memoryCost, underprovisioningPenalty, alpha, gamma, epsilon)
+		cpuCost := quantityOrZeroDec(qParams.CpuCost)
+		memoryCost := quantityOrZeroDec(qParams.MemoryCost)
+		underprovisioningPenalty := quantityOrZeroDec(qParams.UnderprovisioningPenalty)
+		oomPenalty := quantityOrZeroDec(qParams.OOMPenalty)
+		throttlingPenalty := quantityOrZeroDec(qParams.ThrottlingPenalty)
+		unschedulablePenalty := quantityOrZeroDec(qParams.UnschedulablePenalty)
+		alpha := quantityOrZeroDec(qParams.LearningRate)
+		gamma := quantityOrZeroDec(qParams.DiscountFactor)
+		epsilon := quantityOrZeroDec(qParams.Epsilon)
+		lambda := quantityOrDefaultDec(qParams.TraceDecay, defaultTraceDecay)
+		traceLength := ptr.Deref(qParams.TraceLength, defaultTraceLength)
+		timeOfDayBuckets := ptr.Deref(qParams.TimeOfDayBuckets, 0)
+
+		return reinforcement.NewQAgent(cpuCost, memoryCost, underprovisioningPenalty, oomPenalty, throttlingPenalty, unschedulablePenalty, alpha, gamma, epsilon, lambda, traceLength, timeOfDayBuckets), nil
+	case scalingv1.LearningTypeHorizontal:
+		return &classic.Horizontal{}, nil
+	case scalingv1.LearningTypeVertical:
+		return &classic.Vertical{}, nil
+	case scalingv1.LearningTypeHybrid:
+		return &classic.Hybrid{}, nil
+	case scalingv1.LearningTypeRuleBased:
+		tolerance := quantityOrDefaultDec(ruleParams.Tolerance, defaultRuleTolerance)
+		scaleUpStep := ptr.Deref(ruleParams.ScaleUpStep, defaultRuleStep)
+		scaleDownStep := ptr.Deref(ruleParams.ScaleDownStep, defaultRuleStep)
+
+		return classic.NewRuleBased(tolerance, scaleUpStep, scaleDownStep), nil
+	case scalingv1.LearningTypeNone:
+		return &strategy.NoOp{}, nil
 	default:
-		return &strategy.NoOp{}
+		return nil, fmt.Errorf("unknown learning type %q", learningType)
 	}
 }
 
@@ -0,0 +1,79 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+// HybridScalerPolicyReconciler lists the scalers referencing a HybridScalerPolicy in its status
+type HybridScalerPolicyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=scaling.autoscaling.custom,resources=hybridscalerpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=scaling.autoscaling.custom,resources=hybridscalerpolicies/status,verbs=get;update;patch
+
+func (r *HybridScalerPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var policy scalingv1.HybridScalerPolicy
+	if err := r.Get(ctx, req.NamespacedName, &policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var scalers scalingv1.HybridScalerList
+	if err := r.List(ctx, &scalers, client.MatchingFields{policyKey: policy.Name}); err != nil {
+		logger.Error(err, "cannot list scalers for policy", "policy", policy.Name)
+		return ctrl.Result{}, nil
+	}
+
+	refs := scalerReferences(scalers.Items)
+	if equality.Semantic.DeepEqual(refs, policy.Status.Scalers) {
+		return ctrl.Result{}, nil
+	}
+
+	policy.Status.Scalers = refs
+	// a conflicting update is retried with the latest policy
+	if err := r.Status().Update(ctx, &policy); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HybridScalerPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := addPolicyIndex(mgr); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&scalingv1.HybridScalerPolicy{}).
+		Watches(&scalingv1.HybridScaler{}, handler.EnqueueRequestsFromMapFunc(policyForScaler)).
+		Complete(r)
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"k8s.io/utils/ptr"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+// queryActivity runs the activity query and reports whether any of the returned samples is nonzero
+func (r *HybridScalerReconciler) queryActivity(ctx context.Context, query string, now time.Time) (bool, error) {
+	res, _, err := r.PromAPI.Query(ctx, query, now)
+	if err != nil {
+		return false, fmt.Errorf("cannot query activity, %w", err)
+	}
+
+	switch resTyped := res.(type) {
+	case model.Vector:
+		for _, sample := range resTyped {
+			if sample.Value != 0 {
+				return true, nil
+			}
+		}
+
+		return false, nil
+
+	case *model.Scalar:
+		return resTyped.Value != 0, nil
+
+	default:
+		return false, fmt.Errorf("unexpected result type %s received from prometheus", res.Type())
+	}
+}
+
+// scaleToZeroReplicas decides on the replicas while the target is scaled to zero or has been idle for the idle period,
+// it returns false if the regular scaling strategy is in charge
+func scaleToZeroReplicas(spec scalingv1.HybridScalerSpec, status scalingv1.HybridScalerStatus, active bool, now time.Time) (int32, bool) {
+	if spec.ScaleToZero == nil {
+		return 0, false
+	}
+
+	if status.Replicas == 0 {
+		if active {
+			return activationReplicas(spec), true
+		}
+
+		return 0, true
+	}
+
+	if active || ptr.Deref(spec.MinReplicas, 0) > 0 || status.LastActiveTime == nil {
+		return 0, false
+	}
+
+	idlePeriod := time.Duration(spec.ScaleToZero.IdlePeriod) * time.Second
+	if now.Sub(status.LastActiveTime.Time) < idlePeriod {
+		return 0, false
+	}
+
+	return 0, true
+}
+
+// activationReplicas returns the number of replicas to wake the target up with, which is at least one and within the allowed range
+func activationReplicas(spec scalingv1.HybridScalerSpec) int32 {
+	replicas := ptr.Deref(spec.ScaleToZero.ActivationReplicas, 1)
+
+	if minReplicas := ptr.Deref(spec.MinReplicas, 0); replicas < minReplicas {
+		replicas = minReplicas
+	}
+
+	if maxReplicas := ptr.Deref(spec.MaxReplicas, 0); maxReplicas > 0 && replicas > maxReplicas {
+		replicas = maxReplicas
+	}
+
+	if replicas < 1 {
+		replicas = 1
+	}
+
+	return replicas
+}
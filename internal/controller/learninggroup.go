@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+	"github.com/iljarotar/hybrid-scaler/internal/reinforcement"
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+)
+
+// groupDecision makes a q-learning decision with the parameters and the shared table of the learning group. The updated table is
+// written back with optimistic concurrency, if another member updated it in the meantime, the decision is made again on the
+// latest table. The member's own pending decision and traces are returned as its new learning state.
+func (r *HybridScalerReconciler) groupDecision(ctx context.Context, groupName string, state *strategy.State, learningState []byte) (*strategy.ScalingDecision, []byte, error) {
+	var decision *strategy.ScalingDecision
+	var own []byte
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var group scalingv1.LearningGroup
+		if err := r.Get(ctx, client.ObjectKey{Name: groupName}, &group); err != nil {
+			return fmt.Errorf("cannot get learning group %q, %w", groupName, err)
+		}
+
+		scalingStrategy, err := getScalingStrategy(scalingv1.LearningTypeQLearning, group.Spec.QLearningParams, scalingv1.RuleBasedParams{})
+		if err != nil {
+			return err
+		}
+
+		joined, err := reinforcement.JoinLearningState(group.Status.LearningState, learningState)
+		if err != nil {
+			return err
+		}
+
+		d, newLearningState, err := scalingStrategy.MakeDecision(state, joined)
+		if err != nil {
+			return err
+		}
+
+		table, newOwn, err := reinforcement.SplitLearningState(newLearningState)
+		if err != nil {
+			return err
+		}
+
+		group.Status.LearningState = table
+		if err := r.Status().Update(ctx, &group); err != nil {
+			return err
+		}
+
+		decision, own = d, newOwn
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return decision, own, nil
+}
+
+// setLearningGroupCondition reports whether the scaler's learning type or q-learning parameters conflict with its learning group
+// and emits an event if they newly do
+func (r *HybridScalerReconciler) setLearningGroupCondition(ctx context.Context, scaler *scalingv1.HybridScaler) error {
+	groupName := scaler.Spec.LearningGroup
+	if groupName == "" {
+		meta.RemoveStatusCondition(&scaler.Status.Conditions, scalingv1.ConditionLearningGroupConflict)
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:               scalingv1.ConditionLearningGroupConflict,
+		Status:             metav1.ConditionFalse,
+		Reason:             "GroupParameters",
+		Message:            fmt.Sprintf("using the shared learning state and parameters of learning group %s", groupName),
+		ObservedGeneration: scaler.Generation,
+	}
+
+	if scaler.Spec.LearningType != scalingv1.LearningTypeQLearning {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "LearningTypeMismatch"
+		condition.Message = fmt.Sprintf("learning group %s is not used by learning type %s, only by %s", groupName, scaler.Spec.LearningType, scalingv1.LearningTypeQLearning)
+	} else {
+		var group scalingv1.LearningGroup
+		if err := r.Get(ctx, client.ObjectKey{Name: groupName}, &group); err != nil {
+			return fmt.Errorf("cannot get learning group %q, %w", groupName, err)
+		}
+
+		if overridden := overriddenQLearningParams(scaler.Spec.QLearningParams, group.Spec.QLearningParams); len(overridden) > 0 {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "ParametersOverridden"
+			condition.Message = fmt.Sprintf("learning group %s overrides %s", groupName, strings.Join(overridden, ", "))
+		}
+	}
+
+	if setCondition(&scaler.Status.Conditions, condition) && condition.Status == metav1.ConditionTrue {
+		r.Recorder.Event(scaler, corev1.EventTypeWarning, condition.Reason, condition.Message)
+	}
+
+	return nil
+}
+
+// overriddenQLearningParams returns the names of the parameters the member sets to other values than its learning group
+func overriddenQLearningParams(member, group scalingv1.QLearningParams) []string {
+	var overridden []string
+
+	quantities := []struct {
+		name          string
+		member, group *resource.Quantity
+	}{
+		{"learningRate", member.LearningRate, group.LearningRate},
+		{"discountFactor", member.DiscountFactor, group.DiscountFactor},
+		{"epsilon", member.Epsilon, group.Epsilon},
+		{"cpuCost", member.CpuCost, group.CpuCost},
+		{"memoryCost", member.MemoryCost, group.MemoryCost},
+		{"underprovisioningPenalty", member.UnderprovisioningPenalty, group.UnderprovisioningPenalty},
+		{"oomPenalty", member.OOMPenalty, group.OOMPenalty},
+		{"throttlingPenalty", member.ThrottlingPenalty, group.ThrottlingPenalty},
+		{"unschedulablePenalty", member.UnschedulablePenalty, group.UnschedulablePenalty},
+		{"traceDecay", member.TraceDecay, group.TraceDecay},
+	}
+	for _, q := range quantities {
+		if q.member != nil && (q.group == nil || q.member.Cmp(*q.group) != 0) {
+			overridden = append(overridden, q.name)
+		}
+	}
+
+	if overriddenValue(member.TimeOfDayBuckets, group.TimeOfDayBuckets) {
+		overridden = append(overridden, "timeOfDayBuckets")
+	}
+
+	if overriddenValue(member.TimeZone, group.TimeZone) {
+		overridden = append(overridden, "timeZone")
+	}
+
+	if overriddenValue(member.TraceLength, group.TraceLength) {
+		overridden = append(overridden, "traceLength")
+	}
+
+	return overridden
+}
+
+func overriddenValue[T comparable](member, group *T) bool {
+	return member != nil && (group == nil || *member != *group)
+}
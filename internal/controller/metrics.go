@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+var decisionLabels = []string{"namespace", "name", "strategy", "shadow"}
+
+var (
+	decisionReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hybridscaler_decision_replicas",
+		Help: "Number of replicas of the last decision of each strategy, decisions of shadow strategies are never applied",
+	}, decisionLabels)
+	decisionEstimatedCost = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hybridscaler_decision_estimated_cost",
+		Help: "Estimated cost of the last decision of each strategy, decisions of shadow strategies are never applied",
+	}, decisionLabels)
+)
+
+func init() {
+	metrics.Registry.MustRegister(decisionReplicas, decisionEstimatedCost)
+}
+
+// recordDecisions exports the applied decision and the decisions of the shadow strategies of a scaler
+func recordDecisions(scaler *scalingv1.HybridScaler, strategyName string, replicas int32) {
+	forgetDecisions(scaler.Namespace, scaler.Name)
+	recordDecision(scaler.Namespace, scaler.Name, strategyName, false, replicas, scaler.Status.EstimatedCost)
+
+	for _, decision := range scaler.Status.ShadowDecisions {
+		if decision.Error == "" {
+			recordDecision(scaler.Namespace, scaler.Name, decision.Name, true, decision.Replicas, decision.EstimatedCost)
+		}
+	}
+}
+
+// recordDecision exports the replicas and estimated cost of a decision, the cost is left out if it is unknown
+func recordDecision(namespace, name, strategyName string, shadow bool, replicas int32, cost *resource.Quantity) {
+	labels := prometheus.Labels{
+		"namespace": namespace,
+		"name":      name,
+		"strategy":  strategyName,
+		"shadow":    strconv.FormatBool(shadow),
+	}
+
+	decisionReplicas.With(labels).Set(float64(replicas))
+	if cost != nil {
+		decisionEstimatedCost.With(labels).Set(cost.AsApproximateFloat64())
+	}
+}
+
+// forgetDecisions removes the exported decisions of a scaler, so that removed strategies and scalers do not linger
+func forgetDecisions(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+
+	decisionReplicas.DeletePartialMatch(labels)
+	decisionEstimatedCost.DeletePartialMatch(labels)
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+)
+
+// nodeCapacities returns the allocatable resources of the schedulable nodes the pod template's
+// node selector, required node affinity and tolerations allow its pods to run on
+func (r *HybridScalerReconciler) nodeCapacities(ctx context.Context, template corev1.PodTemplateSpec) ([]strategy.ResourcesList, error) {
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		return nil, fmt.Errorf("cannot list nodes, %w", err)
+	}
+
+	capacities := make([]strategy.ResourcesList, 0)
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable || !podFitsNode(&template.Spec, &node) {
+			continue
+		}
+
+		capacities = append(capacities, strategy.ResourcesList{
+			CPU:    node.Status.Allocatable.Cpu().AsDec(),
+			Memory: node.Status.Allocatable.Memory().AsDec(),
+		})
+	}
+
+	return capacities, nil
+}
+
+// podFitsNode reports whether the pod may be scheduled on the node regardless of its resources
+func podFitsNode(spec *corev1.PodSpec, node *corev1.Node) bool {
+	if !labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+
+	affinity := spec.Affinity
+	if affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		if !matchesNodeSelectorTerms(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, node) {
+			return false
+		}
+	}
+
+	return toleratesTaints(spec.Tolerations, node.Spec.Taints)
+}
+
+// matchesNodeSelectorTerms reports whether the node matches any of the terms
+func matchesNodeSelectorTerms(terms []corev1.NodeSelectorTerm, node *corev1.Node) bool {
+	for _, term := range terms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+
+		if matchesRequirements(term.MatchExpressions, labels.Set(node.Labels)) &&
+			matchesRequirements(term.MatchFields, labels.Set{"metadata.name": node.Name}) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesRequirements(requirements []corev1.NodeSelectorRequirement, set labels.Set) bool {
+	operators := map[corev1.NodeSelectorOperator]selection.Operator{
+		corev1.NodeSelectorOpIn:           selection.In,
+		corev1.NodeSelectorOpNotIn:        selection.NotIn,
+		corev1.NodeSelectorOpExists:       selection.Exists,
+		corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+		corev1.NodeSelectorOpGt:           selection.GreaterThan,
+		corev1.NodeSelectorOpLt:           selection.LessThan,
+	}
+
+	for _, requirement := range requirements {
+		operator, ok := operators[requirement.Operator]
+		if !ok {
+			return false
+		}
+
+		r, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+		if err != nil || !r.Matches(set) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// toleratesTaints reports whether the tolerations tolerate all taints which prevent scheduling
+func toleratesTaints(tolerations []corev1.Toleration, taints []corev1.Taint) bool {
+	for i := range taints {
+		taint := &taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+
+		if !tolerated {
+			return false
+		}
+	}
+
+	return true
+}
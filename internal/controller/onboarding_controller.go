@@ -0,0 +1,143 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+// policyAnnotation names the HybridScalerPolicy a workload is onboarded with
+const policyAnnotation = "hybrid-scaler/policy"
+
+// OnboardingReconciler creates a HybridScaler for each workload of its kind annotated with a policy, the scaler is named after
+// and owned by the workload, so it is deleted together with it, removing the annotation deletes the scaler as well
+type OnboardingReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Kind is the kind of the watched workloads, either Deployment or StatefulSet
+	Kind string
+}
+
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=scaling.autoscaling.custom,resources=hybridscalers,verbs=get;list;watch;create;update;patch;delete
+
+func (r *OnboardingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	obj, err := r.newWorkload()
+	if err != nil {
+		logger.Error(err, "cannot onboard workload", "kind", r.Kind)
+		return ctrl.Result{}, nil
+	}
+
+	// the scaler of a deleted workload is garbage collected through its owner reference
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	policy := obj.GetAnnotations()[policyAnnotation]
+
+	var scaler scalingv1.HybridScaler
+	err = r.Get(ctx, req.NamespacedName, &scaler)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "cannot fetch scaler of workload", "workload", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	exists := err == nil
+
+	if exists && !metav1.IsControlledBy(&scaler, obj) {
+		if policy != "" {
+			logger.Info("leaving scaler which is not owned by the workload", "scaler", req.NamespacedName)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if policy == "" {
+		if exists {
+			logger.Info("deleting scaler of workload without policy", "scaler", req.NamespacedName)
+			if err := r.Delete(ctx, &scaler); err != nil {
+				return ctrl.Result{}, client.IgnoreNotFound(err)
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	scaler = scalingv1.HybridScaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: req.Namespace,
+			Name:      req.Name,
+		},
+	}
+
+	// only the scale target and the policy are set, so fields added to the scaler to override the policy are kept
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, &scaler, func() error {
+		scaler.Spec.ScaleTargetRef.APIVersion = appsv1.SchemeGroupVersion.String()
+		scaler.Spec.ScaleTargetRef.Kind = r.Kind
+		scaler.Spec.ScaleTargetRef.Name = obj.GetName()
+		scaler.Spec.Policy = policy
+
+		return controllerutil.SetControllerReference(obj, &scaler, r.Scheme)
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if op != controllerutil.OperationResultNone {
+		logger.Info("onboarded workload", "scaler", req.NamespacedName, "policy", policy, "operation", op)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *OnboardingReconciler) newWorkload() (client.Object, error) {
+	switch r.Kind {
+	case kindDeployment:
+		return &appsv1.Deployment{}, nil
+	case kindStatefulSet:
+		return &appsv1.StatefulSet{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %q", r.Kind)
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OnboardingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	obj, err := r.newWorkload()
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("onboarding-" + strings.ToLower(r.Kind)).
+		For(obj).
+		Owns(&scalingv1.HybridScaler{}).
+		Complete(r)
+}
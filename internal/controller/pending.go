@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+const defaultPendingTimeout = 300
+
+// unschedulablePods counts the pods the scheduler could not place and how many of them have been waiting longer than the timeout
+func unschedulablePods(pods []corev1.Pod, timeout time.Duration, now time.Time) (unschedulable, expired int32) {
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodPending {
+			continue
+		}
+
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type != corev1.PodScheduled || condition.Status != corev1.ConditionFalse || condition.Reason != corev1.PodReasonUnschedulable {
+				continue
+			}
+
+			unschedulable++
+			if now.Sub(condition.LastTransitionTime.Time) >= timeout {
+				expired++
+			}
+		}
+	}
+
+	return unschedulable, expired
+}
+
+func pendingTimeout(spec scalingv1.HybridScalerSpec) time.Duration {
+	return time.Duration(ptr.Deref(spec.PendingTimeout, defaultPendingTimeout)) * time.Second
+}
+
+// rollbackScaleUp returns the replicas and resources which undo the last scale-up as far as needed for the expired pods,
+// replicas are reduced by the number of expired pods but not below the replicas before the scale-up,
+// resources are reset if the scale-up increased them
+func rollbackScaleUp(scaleUp *scalingv1.ScaleUp, replicas, expired int32) (int32, map[string]scalingv1.ContainerResources) {
+	if replicas > scaleUp.Replicas {
+		replicas -= expired
+		if replicas < scaleUp.Replicas {
+			replicas = scaleUp.Replicas
+		}
+	}
+
+	return replicas, scaleUp.ContainerResources
+}
+
+// scaleUp returns what is needed to roll back the decision if it scales the target up, otherwise nil
+func scaleUp(replicas int32, resources map[string]scalingv1.ContainerResources, newReplicas int32, newResources map[string]scalingv1.ContainerResources) *scalingv1.ScaleUp {
+	resourcesIncreased := false
+
+	for name, newContainerResources := range newResources {
+		current, ok := resources[name]
+		if !ok {
+			continue
+		}
+
+		if newContainerResources.Requests.Cpu().Cmp(*current.Requests.Cpu()) > 0 || newContainerResources.Requests.Memory().Cmp(*current.Requests.Memory()) > 0 {
+			resourcesIncreased = true
+			break
+		}
+	}
+
+	if newReplicas <= replicas && !resourcesIncreased {
+		return nil
+	}
+
+	scaleUp := &scalingv1.ScaleUp{Replicas: replicas}
+	if resourcesIncreased {
+		scaleUp.ContainerResources = resources
+	}
+
+	return scaleUp
+}
+
+// rollBackScaleUp undoes the last scale-up as far as needed for the pods which could not be scheduled within the timeout,
+// resources resized in place are left to the resize handling
+func (r *HybridScalerReconciler) rollBackScaleUp(ctx context.Context, scaler *scalingv1.HybridScaler, target *workload, expired int32, now time.Time) error {
+	replicas, resources := rollbackScaleUp(scaler.Status.LastScaleUp, target.replicas, expired)
+
+	var replicasToApply *int32
+	if scaler.Spec.Coexistence != scalingv1.CoexistenceModeResourcesOnly && replicas != target.replicas {
+		replicasToApply = &replicas
+	}
+
+	if scaler.Spec.Coexistence == scalingv1.CoexistenceModeReplicasOnly || inPlaceUpdate(scaler.Spec.UpdateMode) {
+		resources = nil
+	}
+
+	scaler.Status.LastScaleUp = nil
+	scaler.Status.LastScaleTime = &metav1.Time{Time: now}
+	if resources != nil {
+		scaler.Status.ContainerResources = resources
+		scaler.Status.RolloutPending = true
+	}
+
+	if err := r.applyStatus(ctx, scaler); err != nil {
+		return fmt.Errorf("cannot update scaler status, %w", err)
+	}
+
+	r.Recorder.Event(scaler, corev1.EventTypeWarning, "ScaleUpRolledBack",
+		fmt.Sprintf("rolled back the last scale-up, %d pods could not be scheduled within %s", expired, pendingTimeout(scaler.Spec)))
+
+	if replicasToApply == nil && resources == nil {
+		return nil
+	}
+
+	return r.applyWorkload(ctx, target, replicasToApply, resources)
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+// podRevision identifies the pods of a pod template by the value of a label
+type podRevision struct {
+	label, hash string
+}
+
+// samplePods returns the running pods which have been ready long enough and pass the filter,
+// the revision is ignored if its hash is unknown
+func samplePods(pods []corev1.Pod, filter scalingv1.PodFilter, revision podRevision, now time.Time) []corev1.Pod {
+	sampled := make([]corev1.Pod, 0)
+	minReady := time.Duration(filter.MinReadySeconds) * time.Second
+
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		if pod.DeletionTimestamp != nil && !filter.IncludeTerminating {
+			continue
+		}
+
+		if ptr.Deref(filter.CurrentRevisionOnly, true) && revision.hash != "" && pod.Labels[revision.label] != revision.hash {
+			continue
+		}
+
+		readySince, ready := readySince(&pod)
+		if !ready || now.Sub(readySince) < minReady {
+			continue
+		}
+
+		sampled = append(sampled, pod)
+	}
+
+	return sampled
+}
+
+// readySince returns the time since which the pod is ready
+func readySince(pod *corev1.Pod) (time.Time, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Time, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+// policyIndexers holds the field indexers the policy index was registered with, since registering it twice fails
+var policyIndexers sync.Map
+
+// addPolicyIndex indexes scalers by the policy they reference, both the scaler and the policy controller depend on it
+func addPolicyIndex(mgr ctrl.Manager) error {
+	if _, registered := policyIndexers.LoadOrStore(mgr.GetFieldIndexer(), struct{}{}); registered {
+		return nil
+	}
+
+	return mgr.GetFieldIndexer().IndexField(context.Background(), &scalingv1.HybridScaler{}, policyKey, scalerPolicy)
+}
+
+func scalerPolicy(obj client.Object) []string {
+	scaler := obj.(*scalingv1.HybridScaler)
+	if scaler.Spec.Policy == "" {
+		return nil
+	}
+
+	return []string{scaler.Spec.Policy}
+}
+
+// setIncompleteSpecCondition reports whether the scaler lacks max replicas after merging its policy, in which case it must not act,
+// and whether the condition changed
+func (r *HybridScalerReconciler) setIncompleteSpecCondition(scaler *scalingv1.HybridScaler) (incomplete, changed bool) {
+	condition := metav1.Condition{
+		Type:               scalingv1.ConditionIncompleteSpec,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Complete",
+		Message:            "all required fields are set",
+		ObservedGeneration: scaler.Generation,
+	}
+
+	if scaler.Spec.MaxReplicas == nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "MissingMaxReplicas"
+		condition.Message = "maxReplicas is set neither on the scaler nor on its policy"
+		if scaler.Spec.Policy != "" {
+			condition.Message = fmt.Sprintf("maxReplicas is set neither on the scaler nor on its policy %s", scaler.Spec.Policy)
+		}
+	}
+
+	changed = setCondition(&scaler.Status.Conditions, condition)
+	if changed && condition.Status == metav1.ConditionTrue {
+		r.Recorder.Event(scaler, corev1.EventTypeWarning, condition.Reason, condition.Message)
+	}
+
+	return condition.Status == metav1.ConditionTrue, changed
+}
+
+// withPolicy fills the fields left unset on the scaler with those of the policy
+func withPolicy(spec scalingv1.HybridScalerSpec, policy scalingv1.HybridScalerPolicySpec) scalingv1.HybridScalerSpec {
+	if spec.MinReplicas == nil {
+		spec.MinReplicas = policy.MinReplicas
+	}
+
+	if spec.MaxReplicas == nil {
+		spec.MaxReplicas = policy.MaxReplicas
+	}
+
+	if spec.LearningType == "" {
+		spec.LearningType = policy.LearningType
+	}
+
+	if spec.Interval == nil {
+		spec.Interval = policy.Interval
+	}
+
+	spec.QLearningParams = mergeQLearningParams(policy.QLearningParams, spec.QLearningParams)
+	spec.ResourcePolicy = mergeResourcePolicies(policy.ResourcePolicy, spec.ResourcePolicy)
+
+	return spec
+}
+
+// mergeQLearningParams returns the parameters of `base`, overridden by those set in `overrides`
+func mergeQLearningParams(base, overrides scalingv1.QLearningParams) scalingv1.QLearningParams {
+	merged := base
+
+	mergePtr(&merged.LearningRate, overrides.LearningRate)
+	mergePtr(&merged.DiscountFactor, overrides.DiscountFactor)
+	mergePtr(&merged.Epsilon, overrides.Epsilon)
+	mergePtr(&merged.CpuCost, overrides.CpuCost)
+	mergePtr(&merged.MemoryCost, overrides.MemoryCost)
+	mergePtr(&merged.UnderprovisioningPenalty, overrides.UnderprovisioningPenalty)
+	mergePtr(&merged.OOMPenalty, overrides.OOMPenalty)
+	mergePtr(&merged.ThrottlingPenalty, overrides.ThrottlingPenalty)
+	mergePtr(&merged.UnschedulablePenalty, overrides.UnschedulablePenalty)
+	mergePtr(&merged.TimeOfDayBuckets, overrides.TimeOfDayBuckets)
+	mergePtr(&merged.TimeZone, overrides.TimeZone)
+	mergePtr(&merged.TraceDecay, overrides.TraceDecay)
+	mergePtr(&merged.TraceLength, overrides.TraceLength)
+
+	return merged
+}
+
+// mergeResourcePolicies returns the resource policy `base`, overridden by the fields and resources set in `overrides`
+func mergeResourcePolicies(base, overrides scalingv1.ResourcePolicy) scalingv1.ResourcePolicy {
+	merged := scalingv1.ResourcePolicy{
+		MinAllowed:                  mergeMaps(base.MinAllowed, overrides.MinAllowed),
+		MaxAllowed:                  mergeMaps(base.MaxAllowed, overrides.MaxAllowed),
+		TargetUtilization:           mergeMaps(base.TargetUtilization, overrides.TargetUtilization),
+		LimitsToRequestsRatioCPU:    base.LimitsToRequestsRatioCPU,
+		LimitsToRequestsRatioMemory: base.LimitsToRequestsRatioMemory,
+		Headroom:                    mergeMaps(base.Headroom, overrides.Headroom),
+		Rounding:                    mergeMaps(base.Rounding, overrides.Rounding),
+	}
+
+	mergePtr(&merged.LimitsToRequestsRatioCPU, overrides.LimitsToRequestsRatioCPU)
+	mergePtr(&merged.LimitsToRequestsRatioMemory, overrides.LimitsToRequestsRatioMemory)
+
+	return merged
+}
+
+// mergePtr replaces the value unless the override is unset
+func mergePtr[T any](value **T, override *T) {
+	if override != nil {
+		*value = override
+	}
+}
+
+// mergeMaps returns a new map containing all entries of `base`, overridden by those of `overrides`, or nil if both are empty
+func mergeMaps[K comparable, V any](base, overrides map[K]V) map[K]V {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+
+	merged := make(map[K]V, len(base)+len(overrides))
+
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range overrides {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// scalerReferences returns references to the scalers, sorted by namespace and name
+func scalerReferences(scalers []scalingv1.HybridScaler) []scalingv1.ScalerReference {
+	var refs []scalingv1.ScalerReference
+
+	for _, scaler := range scalers {
+		refs = append(refs, scalingv1.ScalerReference{Namespace: scaler.Namespace, Name: scaler.Name})
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Namespace != refs[j].Namespace {
+			return refs[i].Namespace < refs[j].Namespace
+		}
+		return refs[i].Name < refs[j].Name
+	})
+
+	return refs
+}
+
+// scalersForPolicy maps a policy to the scalers referencing it
+func (r *HybridScalerReconciler) scalersForPolicy(ctx context.Context, obj client.Object) []reconcile.Request {
+	var scalers scalingv1.HybridScalerList
+	if err := r.List(ctx, &scalers, client.MatchingFields{policyKey: obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "cannot list scalers for policy", "policy", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(scalers.Items))
+	for _, scaler := range scalers.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&scaler)})
+	}
+
+	return requests
+}
+
+// policyForScaler maps a scaler to the policy it references
+func policyForScaler(ctx context.Context, obj client.Object) []reconcile.Request {
+	scaler, ok := obj.(*scalingv1.HybridScaler)
+	if !ok || scaler.Spec.Policy == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: scaler.Spec.Policy}}}
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/inf.v0"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+)
+
+const reasonOOMKilled = "OOMKilled"
+
+var (
+	defaultOOMBumpFactor        = resource.MustParse("1.2")
+	defaultThrottlingThreshold  = resource.MustParse("0.1")
+	defaultThrottlingBumpFactor = resource.MustParse("1.2")
+)
+
+// oomKills counts for each container the pods in which it was terminated for running out of memory after `since`,
+// only the last termination of each container is known, so repeated kills of the same container count once
+func oomKills(pods []corev1.Pod, since *metav1.Time) map[string]int32 {
+	kills := make(map[string]int32)
+
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			if oomKilledAfter(status, since) {
+				kills[status.Name]++
+			}
+		}
+	}
+
+	return kills
+}
+
+func oomKilledAfter(status corev1.ContainerStatus, since *metav1.Time) bool {
+	for _, terminated := range []*corev1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+		if terminated == nil || terminated.Reason != reasonOOMKilled {
+			continue
+		}
+
+		if since == nil || terminated.FinishedAt.After(since.Time) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// restarts returns the total number of container restarts of the pod
+func restarts(pod *corev1.Pod) int32 {
+	var count int32
+	for _, status := range pod.Status.ContainerStatuses {
+		count += status.RestartCount
+	}
+
+	return count
+}
+
+// throttlingRatios returns for each container the share of cpu periods in which it was throttled during the window
+func (r *HybridScalerReconciler) throttlingRatios(ctx context.Context, namespace string, pods []corev1.Pod, window time.Duration, now time.Time) (map[string]float64, error) {
+	if window < time.Minute {
+		window = time.Minute
+	}
+
+	selector := podSelector(namespace, pods)
+	query := fmt.Sprintf(
+		`sum by (container) (rate(container_cpu_cfs_throttled_periods_total{%[1]s}[%[2]s])) / sum by (container) (rate(container_cpu_cfs_periods_total{%[1]s}[%[2]s]))`,
+		selector, model.Duration(window),
+	)
+
+	samples, err := r.queryContainerUsage(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+
+	ratios := make(map[string]float64)
+	for _, sample := range samples {
+		ratios[string(sample.Metric["container"])] = float64(sample.Value)
+	}
+
+	return ratios, nil
+}
+
+// containerPressure combines the out-of-memory kills and throttling ratios of all containers
+func containerPressure(kills map[string]int32, throttlingRatios map[string]float64) map[string]scalingv1.ContainerPressure {
+	pressure := make(map[string]scalingv1.ContainerPressure)
+
+	for name, count := range kills {
+		p := pressure[name]
+		p.OOMKills = count
+		pressure[name] = p
+	}
+
+	for name, ratio := range throttlingRatios {
+		p := pressure[name]
+		p.ThrottlingRatio = resource.NewDecimalQuantity(*float64ToDec(ratio), resource.DecimalExponent)
+		pressure[name] = p
+	}
+
+	return pressure
+}
+
+func preparePressure(pressure map[string]scalingv1.ContainerPressure) map[string]strategy.Pressure {
+	if len(pressure) == 0 {
+		return nil
+	}
+
+	prepared := make(map[string]strategy.Pressure)
+
+	for name, p := range pressure {
+		var throttlingRatio *inf.Dec
+		if p.ThrottlingRatio != nil {
+			throttlingRatio = p.ThrottlingRatio.AsDec()
+		}
+
+		prepared[name] = strategy.Pressure{
+			OOMKills:        p.OOMKills,
+			ThrottlingRatio: throttlingRatio,
+		}
+	}
+
+	return prepared
+}
+
+func quantityOrDefaultDec(q *resource.Quantity, def resource.Quantity) *inf.Dec {
+	if q == nil {
+		return def.AsDec()
+	}
+
+	return q.AsDec()
+}
+
+func quantityOrZeroDec(q *resource.Quantity) *inf.Dec {
+	return quantityOrDefaultDec(q, resource.Quantity{})
+}
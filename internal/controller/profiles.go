@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+	"github.com/iljarotar/hybrid-scaler/internal/schedule"
+)
+
+// activeProfile returns the first profile whose schedule is active at `now` or nil if none is
+func activeProfile(profiles []scalingv1.ScalingProfile, now time.Time) (*scalingv1.ScalingProfile, error) {
+	for i := range profiles {
+		profile := &profiles[i]
+
+		s, err := schedule.Parse(profile.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse schedule of profile %s, %w", profile.Name, err)
+		}
+
+		location, err := time.LoadLocation(profile.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load time zone of profile %s, %w", profile.Name, err)
+		}
+
+		if s.ActiveAt(now.In(location), time.Duration(profile.Duration)*time.Second) {
+			return profile, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// withProfile returns a copy of the spec with the profile's constraints applied
+func withProfile(spec scalingv1.HybridScalerSpec, profile *scalingv1.ScalingProfile) scalingv1.HybridScalerSpec {
+	if profile == nil {
+		return spec
+	}
+
+	if profile.MinReplicas != nil {
+		spec.MinReplicas = profile.MinReplicas
+	}
+
+	if profile.MaxReplicas != nil {
+		spec.MaxReplicas = profile.MaxReplicas
+	}
+
+	spec.ResourcePolicy.MinAllowed = mergeResourceLists(spec.ResourcePolicy.MinAllowed, profile.MinAllowed)
+	spec.ResourcePolicy.MaxAllowed = mergeResourceLists(spec.ResourcePolicy.MaxAllowed, profile.MaxAllowed)
+
+	return spec
+}
+
+// mergeResourceLists returns a new list containing all resources of `base`, overridden by those of `overrides`
+func mergeResourceLists(base, overrides corev1.ResourceList) corev1.ResourceList {
+	merged := make(corev1.ResourceList, len(base))
+
+	for name, quantity := range base {
+		merged[name] = quantity
+	}
+
+	for name, quantity := range overrides {
+		merged[name] = quantity
+	}
+
+	return merged
+}
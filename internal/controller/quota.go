@@ -0,0 +1,382 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/inf.v0"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+)
+
+// namespaceLimits are the constraints the limit ranges and resource quotas of the target's namespace impose on its pods,
+// unset values are unconstrained
+type namespaceLimits struct {
+	containerMin, containerMax, maxLimitRequestRatio strategy.ResourcesList
+	// podMax limits the sum of the limits of all containers of a pod
+	podMax strategy.ResourcesList
+	// remainingQuota maps requests.cpu, requests.memory, limits.cpu, limits.memory and pods to the amount
+	// the workload may use in total, which includes what its pods currently use
+	remainingQuota map[corev1.ResourceName]*inf.Dec
+}
+
+// quotaResource selects the amount of a quota resource used by a container
+type quotaResource struct {
+	name  corev1.ResourceName
+	value func(r *strategy.Resources) **inf.Dec
+}
+
+var (
+	requestsCPU    = quotaResource{name: corev1.ResourceRequestsCPU, value: func(r *strategy.Resources) **inf.Dec { return &r.Requests.CPU }}
+	requestsMemory = quotaResource{name: corev1.ResourceRequestsMemory, value: func(r *strategy.Resources) **inf.Dec { return &r.Requests.Memory }}
+	limitsCPU      = quotaResource{name: corev1.ResourceLimitsCPU, value: func(r *strategy.Resources) **inf.Dec { return &r.Limits.CPU }}
+	limitsMemory   = quotaResource{name: corev1.ResourceLimitsMemory, value: func(r *strategy.Resources) **inf.Dec { return &r.Limits.Memory }}
+	// quotaResources are ordered so that limits are reduced after requests, which are then kept below the limits
+	quotaResources = []quotaResource{requestsCPU, requestsMemory, limitsCPU, limitsMemory}
+)
+
+// namespaceLimits reads the limit ranges and resource quotas of the namespace, quotas with scopes are ignored
+func (r *HybridScalerReconciler) namespaceLimits(ctx context.Context, namespace string, pods []corev1.Pod) (*namespaceLimits, error) {
+	var limitRanges corev1.LimitRangeList
+	if err := r.List(ctx, &limitRanges, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("cannot list limit ranges, %w", err)
+	}
+
+	var quotas corev1.ResourceQuotaList
+	if err := r.List(ctx, &quotas, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("cannot list resource quotas, %w", err)
+	}
+
+	limits := &namespaceLimits{remainingQuota: make(map[corev1.ResourceName]*inf.Dec)}
+
+	for _, limitRange := range limitRanges.Items {
+		for _, item := range limitRange.Spec.Limits {
+			switch item.Type {
+			case corev1.LimitTypeContainer:
+				limits.containerMin = higher(limits.containerMin, item.Min)
+				limits.containerMax = lower(limits.containerMax, item.Max)
+				limits.maxLimitRequestRatio = lower(limits.maxLimitRequestRatio, item.MaxLimitRequestRatio)
+			case corev1.LimitTypePod:
+				limits.podMax = lower(limits.podMax, item.Max)
+			}
+		}
+	}
+
+	used := quotaUsage(pods)
+
+	for _, quota := range quotas.Items {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+
+		for name, hard := range quota.Status.Hard {
+			name = quotaResourceName(name)
+			if _, ok := used[name]; !ok {
+				continue
+			}
+
+			remaining := hard.AsDec()
+			if quotaUsed, ok := quota.Status.Used[name]; ok {
+				remaining.Sub(remaining, quotaUsed.AsDec())
+			} else if quotaUsed, ok := quota.Status.Used[requestsAlias(name)]; ok {
+				remaining.Sub(remaining, quotaUsed.AsDec())
+			}
+			remaining.Add(remaining, used[name])
+
+			if current, ok := limits.remainingQuota[name]; !ok || remaining.Cmp(current) < 0 {
+				limits.remainingQuota[name] = remaining
+			}
+		}
+	}
+
+	return limits, nil
+}
+
+// quotaResourceName maps the quota resources cpu and memory to their explicit names requests.cpu and requests.memory
+func quotaResourceName(name corev1.ResourceName) corev1.ResourceName {
+	switch name {
+	case corev1.ResourceCPU:
+		return corev1.ResourceRequestsCPU
+	case corev1.ResourceMemory:
+		return corev1.ResourceRequestsMemory
+	default:
+		return name
+	}
+}
+
+// requestsAlias returns the short name of the requests quota resources, under which their usage may be reported as well
+func requestsAlias(name corev1.ResourceName) corev1.ResourceName {
+	switch name {
+	case corev1.ResourceRequestsCPU:
+		return corev1.ResourceCPU
+	case corev1.ResourceRequestsMemory:
+		return corev1.ResourceMemory
+	default:
+		return name
+	}
+}
+
+// quotaUsage returns the amount of each quota resource the non-terminated pods use
+func quotaUsage(pods []corev1.Pod) map[corev1.ResourceName]*inf.Dec {
+	used := map[corev1.ResourceName]*inf.Dec{
+		corev1.ResourcePods:           inf.NewDec(0, 0),
+		corev1.ResourceRequestsCPU:    inf.NewDec(0, 0),
+		corev1.ResourceRequestsMemory: inf.NewDec(0, 0),
+		corev1.ResourceLimitsCPU:      inf.NewDec(0, 0),
+		corev1.ResourceLimitsMemory:   inf.NewDec(0, 0),
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		used[corev1.ResourcePods].Add(used[corev1.ResourcePods], inf.NewDec(1, 0))
+
+		for _, container := range pod.Spec.Containers {
+			used[corev1.ResourceRequestsCPU].Add(used[corev1.ResourceRequestsCPU], container.Resources.Requests.Cpu().AsDec())
+			used[corev1.ResourceRequestsMemory].Add(used[corev1.ResourceRequestsMemory], container.Resources.Requests.Memory().AsDec())
+			used[corev1.ResourceLimitsCPU].Add(used[corev1.ResourceLimitsCPU], container.Resources.Limits.Cpu().AsDec())
+			used[corev1.ResourceLimitsMemory].Add(used[corev1.ResourceLimitsMemory], container.Resources.Limits.Memory().AsDec())
+		}
+	}
+
+	return used
+}
+
+// clampToNamespace limits the decision to the namespace's limit ranges and remaining quota and returns what limited it,
+// replicas are reduced to fit the quota before resources are, but never below one and never if they are fixed
+func clampToNamespace(decision *strategy.ScalingDecision, limits *namespaceLimits, fixedReplicas bool) []string {
+	limitedBy := make([]string, 0)
+
+	// the container resources may be shared with the state, so they are copied before being changed
+	containerResources := make(strategy.ContainerResources)
+	for name, resources := range decision.ContainerResources {
+		containerResources[name] = resources
+	}
+	decision.ContainerResources = containerResources
+
+	if clampToLimitRanges(decision, limits) {
+		limitedBy = append(limitedBy, "limit range")
+	}
+
+	if remaining, ok := limits.remainingQuota[corev1.ResourcePods]; ok && !fixedReplicas {
+		if inf.NewDec(int64(decision.Replicas), 0).Cmp(remaining) > 0 {
+			decision.Replicas = int32(atLeastOne(remaining))
+			limitedBy = append(limitedBy, fmt.Sprintf("%s quota", corev1.ResourcePods))
+		}
+	}
+
+	for _, selected := range quotaResources {
+		remaining, ok := limits.remainingQuota[selected.name]
+		if !ok || decision.Replicas == 0 {
+			continue
+		}
+
+		perPod := inf.NewDec(0, 0)
+		for _, resources := range decision.ContainerResources {
+			if value := *selected.value(&resources); value != nil {
+				perPod.Add(perPod, value)
+			}
+		}
+
+		total := new(inf.Dec).Mul(perPod, inf.NewDec(int64(decision.Replicas), 0))
+		if perPod.Sign() == 0 || total.Cmp(remaining) <= 0 {
+			continue
+		}
+
+		limitedBy = append(limitedBy, fmt.Sprintf("%s quota", selected.name))
+
+		if !fixedReplicas {
+			fitting := new(inf.Dec).QuoRound(remaining, perPod, 0, inf.RoundFloor)
+			if fitting.Sign() > 0 {
+				decision.Replicas = int32(fitting.UnscaledBig().Int64())
+				continue
+			}
+
+			decision.Replicas = 1
+			total = perPod
+		}
+
+		if remaining.Sign() <= 0 {
+			continue
+		}
+
+		factor := new(inf.Dec).QuoRound(remaining, total, 8, inf.RoundDown)
+		scaleContainerResources(decision, selected, factor)
+	}
+
+	for name, resources := range decision.ContainerResources {
+		resources.Requests.CPU = minDec(resources.Requests.CPU, resources.Limits.CPU)
+		resources.Requests.Memory = minDec(resources.Requests.Memory, resources.Limits.Memory)
+		decision.ContainerResources[name] = resources
+	}
+
+	return limitedBy
+}
+
+// clampToLimitRanges limits each container's resources to the limit ranges and reports whether any changed
+func clampToLimitRanges(decision *strategy.ScalingDecision, limits *namespaceLimits) bool {
+	limited := false
+
+	clamp := func(value **inf.Dec, min, max *inf.Dec) {
+		if *value == nil {
+			return
+		}
+
+		if min != nil && (*value).Cmp(min) < 0 {
+			*value = min
+			limited = true
+		}
+
+		if max != nil && (*value).Cmp(max) > 0 {
+			*value = max
+			limited = true
+		}
+	}
+
+	limitRatio := func(limit **inf.Dec, request, ratio *inf.Dec) {
+		if *limit == nil || request == nil || ratio == nil {
+			return
+		}
+
+		maxLimit := new(inf.Dec).Mul(request, ratio)
+		if (*limit).Cmp(maxLimit) > 0 {
+			*limit = maxLimit
+			limited = true
+		}
+	}
+
+	for name, resources := range decision.ContainerResources {
+		clamp(&resources.Requests.CPU, limits.containerMin.CPU, limits.containerMax.CPU)
+		clamp(&resources.Requests.Memory, limits.containerMin.Memory, limits.containerMax.Memory)
+		clamp(&resources.Limits.CPU, limits.containerMin.CPU, limits.containerMax.CPU)
+		clamp(&resources.Limits.Memory, limits.containerMin.Memory, limits.containerMax.Memory)
+
+		limitRatio(&resources.Limits.CPU, resources.Requests.CPU, limits.maxLimitRequestRatio.CPU)
+		limitRatio(&resources.Limits.Memory, resources.Requests.Memory, limits.maxLimitRequestRatio.Memory)
+
+		decision.ContainerResources[name] = resources
+	}
+
+	podLimits := strategy.ResourcesList{CPU: inf.NewDec(0, 0), Memory: inf.NewDec(0, 0)}
+	for _, resources := range decision.ContainerResources {
+		if resources.Limits.CPU != nil {
+			podLimits.CPU.Add(podLimits.CPU, resources.Limits.CPU)
+		}
+		if resources.Limits.Memory != nil {
+			podLimits.Memory.Add(podLimits.Memory, resources.Limits.Memory)
+		}
+	}
+
+	if limits.podMax.CPU != nil && podLimits.CPU.Cmp(limits.podMax.CPU) > 0 {
+		factor := new(inf.Dec).QuoRound(limits.podMax.CPU, podLimits.CPU, 8, inf.RoundDown)
+		scaleContainerResources(decision, limitsCPU, factor)
+		limited = true
+	}
+
+	if limits.podMax.Memory != nil && podLimits.Memory.Cmp(limits.podMax.Memory) > 0 {
+		factor := new(inf.Dec).QuoRound(limits.podMax.Memory, podLimits.Memory, 8, inf.RoundDown)
+		scaleContainerResources(decision, limitsMemory, factor)
+		limited = true
+	}
+
+	return limited
+}
+
+// scaleContainerResources multiplies the selected resource of all containers by the factor, rounding down to millicores and bytes
+func scaleContainerResources(decision *strategy.ScalingDecision, selected quotaResource, factor *inf.Dec) {
+	scale := inf.Scale(0)
+	if selected.name == corev1.ResourceRequestsCPU || selected.name == corev1.ResourceLimitsCPU {
+		scale = 3
+	}
+
+	for name, resources := range decision.ContainerResources {
+		value := selected.value(&resources)
+		if *value != nil {
+			scaled := new(inf.Dec).Mul(*value, factor)
+			*value = scaled.Round(scaled, scale, inf.RoundDown)
+		}
+		decision.ContainerResources[name] = resources
+	}
+}
+
+// setQuotaLimitedCondition updates the scaler's quota condition and emits an event if the decision was newly limited
+func (r *HybridScalerReconciler) setQuotaLimitedCondition(scaler *scalingv1.HybridScaler, limitedBy []string) bool {
+	condition := metav1.Condition{
+		Type:               scalingv1.ConditionQuotaLimited,
+		Status:             metav1.ConditionFalse,
+		Reason:             "WithinLimits",
+		Message:            "the last decision was within the namespace's quota and limit ranges",
+		ObservedGeneration: scaler.Generation,
+	}
+
+	if len(limitedBy) > 0 {
+		sort.Strings(limitedBy)
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "DecisionLimited"
+		condition.Message = fmt.Sprintf("the last decision was limited by the namespace's %s", strings.Join(limitedBy, ", "))
+	}
+
+	changed := setCondition(&scaler.Status.Conditions, condition)
+	if changed && condition.Status == metav1.ConditionTrue {
+		r.Recorder.Event(scaler, corev1.EventTypeWarning, condition.Reason, condition.Message)
+	}
+
+	return changed
+}
+
+// higher returns the higher cpu and memory of the current values and the resource list
+func higher(current strategy.ResourcesList, list corev1.ResourceList) strategy.ResourcesList {
+	return mergeLimit(current, list, 1)
+}
+
+// lower returns the lower cpu and memory of the current values and the resource list
+func lower(current strategy.ResourcesList, list corev1.ResourceList) strategy.ResourcesList {
+	return mergeLimit(current, list, -1)
+}
+
+func mergeLimit(current strategy.ResourcesList, list corev1.ResourceList, preferred int) strategy.ResourcesList {
+	pick := func(value *inf.Dec, name corev1.ResourceName) *inf.Dec {
+		quantity, ok := list[name]
+		if !ok {
+			return value
+		}
+
+		candidate := quantity.AsDec()
+		if value == nil || candidate.Cmp(value) == preferred {
+			return candidate
+		}
+
+		return value
+	}
+
+	return strategy.ResourcesList{
+		CPU:    pick(current.CPU, corev1.ResourceCPU),
+		Memory: pick(current.Memory, corev1.ResourceMemory),
+	}
+}
+
+func minDec(a, b *inf.Dec) *inf.Dec {
+	if a == nil || b == nil || a.Cmp(b) <= 0 {
+		return a
+	}
+
+	return b
+}
+
+// atLeastOne returns the integer part of the value, but at least one
+func atLeastOne(value *inf.Dec) int64 {
+	floor := new(inf.Dec).Round(value, 0, inf.RoundFloor)
+	if floor.Sign() <= 0 {
+		return 1
+	}
+
+	return floor.UnscaledBig().Int64()
+}
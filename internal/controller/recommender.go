@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+	"github.com/iljarotar/hybrid-scaler/internal/recommender"
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+)
+
+const (
+	defaultCPUPercentile    = 90
+	defaultMemoryPercentile = 99
+	defaultHalfLife         = 24 * 60 * 60
+)
+
+// recordUsage adds the current cpu usage and the peak memory usage since the last decision of each container to the
+// scaler's usage histograms
+func (r *HybridScalerReconciler) recordUsage(ctx context.Context, scaler *scalingv1.HybridScaler, w *workload, pods []corev1.Pod, interval time.Duration, now time.Time) error {
+	rec, err := recommender.Decode(scaler.Status.RecommenderState)
+	if err != nil {
+		return fmt.Errorf("cannot decode recommender state, %w", err)
+	}
+
+	halfLife := time.Duration(ptr.Deref(scaler.Spec.Recommender.HalfLife, defaultHalfLife)) * time.Second
+	selector := podSelector(w.GetNamespace(), pods)
+
+	window := interval
+	if window < time.Minute {
+		window = time.Minute
+	}
+
+	cpuQuery := fmt.Sprintf(`rate(container_cpu_usage_seconds_total{%s}[1m])`, selector)
+	cpuSamples, err := r.queryContainerUsage(ctx, cpuQuery, now)
+	if err != nil {
+		return fmt.Errorf("cannot query container cpu usage, %w", err)
+	}
+
+	memoryQuery := fmt.Sprintf(`max_over_time(container_memory_working_set_bytes{%s}[%s])`, selector, model.Duration(window))
+	memorySamples, err := r.queryContainerUsage(ctx, memoryQuery, now)
+	if err != nil {
+		return fmt.Errorf("cannot query container memory usage, %w", err)
+	}
+
+	for _, sample := range cpuSamples {
+		rec.AddCPUSample(string(sample.Metric["container"]), float64(sample.Value), now, halfLife)
+	}
+
+	for _, sample := range memorySamples {
+		rec.AddMemorySample(string(sample.Metric["container"]), float64(sample.Value), now, halfLife)
+	}
+
+	containers := make([]string, 0)
+	for _, container := range w.template.Spec.Containers {
+		containers = append(containers, container.Name)
+	}
+	rec.Prune(containers)
+
+	encoded, err := rec.Encode()
+	if err != nil {
+		return fmt.Errorf("cannot encode recommender state, %w", err)
+	}
+
+	scaler.Status.RecommenderState = encoded
+
+	return nil
+}
+
+func (r *HybridScalerReconciler) queryContainerUsage(ctx context.Context, query string, now time.Time) (model.Vector, error) {
+	res, _, err := r.PromAPI.Query(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+
+	vector, ok := res.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %s received from prometheus", res.Type())
+	}
+
+	return vector, nil
+}
+
+// podSelector returns a label selector matching the containers of the given pods,
+// backslashes of the escaped pod names are escaped once more as the regex is placed in a PromQL string
+func podSelector(namespace string, pods []corev1.Pod) string {
+	names := make([]string, 0)
+	for _, pod := range pods {
+		names = append(names, strings.ReplaceAll(regexp.QuoteMeta(pod.Name), `\`, `\\`))
+	}
+
+	return fmt.Sprintf(`namespace="%s",pod=~"%s",container!=""`, namespace, strings.Join(names, "|"))
+}
+
+// usageRecommendations returns the usage recommendations of all containers with enough samples
+func usageRecommendations(status scalingv1.HybridScalerStatus, policy scalingv1.RecommenderPolicy, now time.Time) (map[string]strategy.UsageRecommendation, error) {
+	rec, err := recommender.Decode(status.RecommenderState)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode recommender state, %w", err)
+	}
+
+	cpuPercentile := float64(ptr.Deref(policy.CPUPercentile, defaultCPUPercentile)) / 100
+	memoryPercentile := float64(ptr.Deref(policy.MemoryPercentile, defaultMemoryPercentile)) / 100
+
+	recommendations := make(map[string]strategy.UsageRecommendation)
+
+	for name := range status.ContainerResources {
+		recommendation, ok := rec.Recommend(name, cpuPercentile, memoryPercentile, now)
+		if !ok {
+			continue
+		}
+
+		recommendations[name] = strategy.UsageRecommendation{
+			Target: strategy.ResourcesList{
+				CPU:    float64ToDec(recommendation.CPU.Target),
+				Memory: float64ToDec(recommendation.Memory.Target),
+			},
+			LowerBound: strategy.ResourcesList{
+				CPU:    float64ToDec(recommendation.CPU.LowerBound),
+				Memory: float64ToDec(recommendation.Memory.LowerBound),
+			},
+			UpperBound: strategy.ResourcesList{
+				CPU:    float64ToDec(recommendation.CPU.UpperBound),
+				Memory: float64ToDec(recommendation.Memory.UpperBound),
+			},
+		}
+	}
+
+	return recommendations, nil
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+const (
+	// pod conditions used for in-place resizes since kubernetes 1.33, replacing `status.resize`
+	podResizePending    corev1.PodConditionType = "PodResizePending"
+	podResizeInProgress corev1.PodConditionType = "PodResizeInProgress"
+	reasonInfeasible                            = "Infeasible"
+)
+
+var errResizeInfeasible = errors.New("in-place resize is infeasible")
+
+// inPlaceUpdate reports whether decisions are applied by resizing running pods
+func inPlaceUpdate(mode scalingv1.UpdateMode) bool {
+	return mode == scalingv1.UpdateModeInPlace || mode == scalingv1.UpdateModeInPlaceOrRecreate
+}
+
+// resizePods patches the resources of all running pods whose containers differ from the new resources
+// using the `resize` subresource and returns the number of resized pods
+func (r *HybridScalerReconciler) resizePods(ctx context.Context, pods []corev1.Pod, newResources map[string]scalingv1.ContainerResources) (int, error) {
+	resized := 0
+
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		if resizeInfeasible(pod) {
+			return resized, fmt.Errorf("pod %s, %w", pod.Name, errResizeInfeasible)
+		}
+
+		patch, ok, err := resizePatch(pod.Spec.Containers, newResources)
+		if err != nil {
+			return resized, fmt.Errorf("cannot create resize patch for pod %s, %w", pod.Name, err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err := r.SubResource("resize").Patch(ctx, pod, client.RawPatch(types.StrategicMergePatchType, patch)); err != nil {
+			return resized, fmt.Errorf("pod %s, %w: %w", pod.Name, errResizeInfeasible, err)
+		}
+
+		resized++
+	}
+
+	return resized, nil
+}
+
+// resizePatch creates a strategic merge patch for all containers whose resources differ from the new resources,
+// it returns false if no container needs to be resized
+func resizePatch(containers []corev1.Container, newResources map[string]scalingv1.ContainerResources) ([]byte, bool, error) {
+	patchContainers := make([]map[string]any, 0)
+
+	for _, container := range containers {
+		resources, ok := newResources[container.Name]
+		if !ok {
+			continue
+		}
+
+		if resourceListsEqual(container.Resources.Requests, resources.Requests) && resourceListsEqual(container.Resources.Limits, resources.Limits) {
+			continue
+		}
+
+		patchContainers = append(patchContainers, map[string]any{
+			"name": container.Name,
+			"resources": corev1.ResourceRequirements{
+				Requests: resources.Requests,
+				Limits:   resources.Limits,
+			},
+		})
+	}
+
+	if len(patchContainers) == 0 {
+		return nil, false, nil
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"containers": patchContainers,
+		},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return patch, true, nil
+}
+
+// resizeInProgress reports whether any pod has a pending or ongoing in-place resize
+func resizeInProgress(pods []corev1.Pod) bool {
+	for _, pod := range pods {
+		switch pod.Status.Resize {
+		case corev1.PodResizeStatusProposed, corev1.PodResizeStatusInProgress:
+			return true
+		}
+
+		for _, condition := range pod.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				continue
+			}
+
+			if condition.Type == podResizeInProgress {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func resizeInfeasible(pod *corev1.Pod) bool {
+	if pod.Status.Resize == corev1.PodResizeStatusInfeasible {
+		return true
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == podResizePending && condition.Status == corev1.ConditionTrue && condition.Reason == reasonInfeasible {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+// rolloutInProgress reports whether the deployment's pods are still being replaced or started,
+// in which case the measured metrics mix pods of different revisions
+func rolloutInProgress(deployment *appsv1.Deployment) bool {
+	status := deployment.Status
+
+	if deployment.Generation > status.ObservedGeneration {
+		return true
+	}
+
+	replicas := ptr.Deref(deployment.Spec.Replicas, 1)
+	if status.UpdatedReplicas < replicas {
+		return true
+	}
+
+	if status.Replicas > status.UpdatedReplicas {
+		return true
+	}
+
+	return status.AvailableReplicas < status.UpdatedReplicas
+}
+
+// remainingWarmup returns how long to wait after the last rollout before metrics can be sampled again
+func remainingWarmup(status scalingv1.HybridScalerStatus, spec scalingv1.HybridScalerSpec, now time.Time) time.Duration {
+	if status.LastRolloutTime == nil || spec.WarmupPeriod == nil {
+		return 0
+	}
+
+	warmupEnd := status.LastRolloutTime.Add(time.Duration(*spec.WarmupPeriod) * time.Second)
+	if !now.Before(warmupEnd) {
+		return 0
+	}
+
+	return warmupEnd.Sub(now)
+}
+
+// templateResourcesChanged reports whether applying the new resources would change the pod template and thus trigger a rollout
+func templateResourcesChanged(containers []corev1.Container, newResources map[string]scalingv1.ContainerResources) bool {
+	for _, container := range containers {
+		resources, ok := newResources[container.Name]
+		if !ok {
+			continue
+		}
+
+		if !resourceListsEqual(container.Resources.Requests, resources.Requests) || !resourceListsEqual(container.Resources.Limits, resources.Limits) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func resourceListsEqual(a, b corev1.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name, quantity := range a {
+		other, ok := b[name]
+		if !ok || quantity.Cmp(other) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+	"github.com/iljarotar/hybrid-scaler/internal/scaling"
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+)
+
+// shadowDecisions lets each shadow strategy decide on the same state as the active strategy, the decisions are subject
+// to the same constraints but never applied, each shadow strategy keeps its own learning state
+func shadowDecisions(shadows []scalingv1.ShadowStrategy, previous []scalingv1.ShadowDecision, state *strategy.State, limits *namespaceLimits, spec scalingv1.HybridScalerSpec) []scalingv1.ShadowDecision {
+	if len(shadows) == 0 {
+		return nil
+	}
+
+	learningStates := make(map[string][]byte)
+	for _, decision := range previous {
+		learningStates[decision.Name] = decision.LearningState
+	}
+
+	decisions := make([]scalingv1.ShadowDecision, 0, len(shadows))
+	for _, shadow := range shadows {
+		decisions = append(decisions, shadowDecision(shadow, learningStates[shadow.Name], state, limits, spec))
+	}
+
+	return decisions
+}
+
+func shadowDecision(shadow scalingv1.ShadowStrategy, learningState []byte, state *strategy.State, limits *namespaceLimits, spec scalingv1.HybridScalerSpec) scalingv1.ShadowDecision {
+	result := scalingv1.ShadowDecision{
+		Name:          shadow.Name,
+		LearningState: learningState,
+	}
+
+	scalingStrategy, err := getScalingStrategy(shadow.LearningType, shadow.QLearningParams, shadow.RuleBasedParams)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	decision, newLearningState, err := scalingStrategy.MakeDecision(state, learningState)
+	if err != nil {
+		result.Error = fmt.Sprintf("cannot make a scaling decision, %v", err)
+		return result
+	}
+	result.LearningState = newLearningState
+
+	constrainDecision(decision, state, limits)
+	result.Description = decision.Description
+	result.Replicas = decision.Replicas
+	result.ContainerResources = interpretResourceScaling(decision, spec.ResourcePolicy)
+
+	cost, err := estimatedCost(state, decision, spec.QLearningParams)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.EstimatedCost = cost
+
+	return result
+}
+
+// constrainDecision subjects a decision to the fixed constraints, the node capacities and the namespace's limits,
+// it reports whether the resources were scaled down to fit on a node and what limited the decision within the namespace
+func constrainDecision(decision *strategy.ScalingDecision, state *strategy.State, limits *namespaceLimits) (fitted bool, limitedBy []string) {
+	enforceFixedConstraints(decision, state)
+	fitted = !state.FixedResources && scaling.FitToNode(decision, state)
+
+	return fitted, clampToNamespace(decision, limits, state.FixedReplicas)
+}
+
+// estimatedCost estimates the cost of a decision with the costs of the q-learning parameters, so that decisions of all strategies are comparable
+func estimatedCost(state *strategy.State, decision *strategy.ScalingDecision, params scalingv1.QLearningParams) (*resource.Quantity, error) {
+	cost, err := scaling.EstimateCost(state, decision, quantityOrZeroDec(params.CpuCost), quantityOrZeroDec(params.MemoryCost), quantityOrZeroDec(params.UnderprovisioningPenalty))
+	if err != nil {
+		return nil, fmt.Errorf("unable to estimate cost, %w", err)
+	}
+
+	return resource.NewDecimalQuantity(*cost, resource.DecimalSI), nil
+}
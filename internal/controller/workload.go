@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scalingv1 "github.com/iljarotar/hybrid-scaler/api/v1"
+)
+
+const (
+	kindDeployment  = "Deployment"
+	kindStatefulSet = "StatefulSet"
+
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// workload wraps the resource targeted by a scaler, which is either a deployment or a stateful set
+type workload struct {
+	client.Object
+	kind              string
+	replicas          int32
+	template          corev1.PodTemplateSpec
+	rolloutInProgress bool
+	// replacesPods is true if the workload controller replaces the pods when the pod template changes
+	replacesPods bool
+	// updateRevision is the revision of the stateful set's current pod template
+	updateRevision string
+}
+
+// getWorkload fetches the scaler's scale target
+func (r *HybridScalerReconciler) getWorkload(ctx context.Context, scaler *scalingv1.HybridScaler) (*workload, error) {
+	key := client.ObjectKey{Namespace: scaler.Namespace, Name: scaler.Spec.ScaleTargetRef.Name}
+
+	switch scaler.Spec.ScaleTargetRef.Kind {
+	case kindDeployment:
+		var deployment appsv1.Deployment
+		if err := r.Get(ctx, key, &deployment); err != nil {
+			return nil, err
+		}
+
+		return &workload{
+			Object:            &deployment,
+			kind:              kindDeployment,
+			replicas:          deployment.Status.Replicas,
+			template:          deployment.Spec.Template,
+			rolloutInProgress: rolloutInProgress(&deployment),
+			replacesPods:      true,
+		}, nil
+
+	case kindStatefulSet:
+		var statefulSet appsv1.StatefulSet
+		if err := r.Get(ctx, key, &statefulSet); err != nil {
+			return nil, err
+		}
+
+		return &workload{
+			Object:            &statefulSet,
+			kind:              kindStatefulSet,
+			replicas:          statefulSet.Status.Replicas,
+			template:          statefulSet.Spec.Template,
+			rolloutInProgress: statefulSetRolloutInProgress(&statefulSet),
+			replacesPods:      statefulSet.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType,
+			updateRevision:    statefulSet.Status.UpdateRevision,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported scale target kind %q", scaler.Spec.ScaleTargetRef.Kind)
+	}
+}
+
+func (w *workload) groupVersionKind() schema.GroupVersionKind {
+	return appsv1.SchemeGroupVersion.WithKind(w.kind)
+}
+
+// listPods lists the workload's pods, for deployments these are the pods of all its replica sets
+func (r *HybridScalerReconciler) listPods(ctx context.Context, w *workload) ([]corev1.Pod, error) {
+	owners := []string{w.GetName()}
+
+	if w.kind == kindDeployment {
+		var replicaSets appsv1.ReplicaSetList
+		if err := r.List(ctx, &replicaSets, client.InNamespace(w.GetNamespace()), client.MatchingFields{ownerKey: w.GetName()}); err != nil {
+			return nil, fmt.Errorf("cannot list replica sets, %w", err)
+		}
+
+		owners = make([]string, 0)
+		for _, rs := range replicaSets.Items {
+			owners = append(owners, rs.Name)
+		}
+	}
+
+	pods := make([]corev1.Pod, 0)
+
+	for _, owner := range owners {
+		var podList corev1.PodList
+		if err := r.List(ctx, &podList, client.InNamespace(w.GetNamespace()), client.MatchingFields{ownerKey: owner}); err != nil {
+			return nil, fmt.Errorf("cannot list pods, %w", err)
+		}
+
+		pods = append(pods, podList.Items...)
+	}
+
+	return pods, nil
+}
+
+// currentRevision returns the label and its value which identify the pods of the workload's current pod template,
+// the value is empty if the current revision is not known yet
+func (r *HybridScalerReconciler) currentRevision(ctx context.Context, w *workload) (label, hash string, err error) {
+	if w.kind == kindStatefulSet {
+		return appsv1.StatefulSetRevisionLabel, w.updateRevision, nil
+	}
+
+	var replicaSets appsv1.ReplicaSetList
+	if err := r.List(ctx, &replicaSets, client.InNamespace(w.GetNamespace()), client.MatchingFields{ownerKey: w.GetName()}); err != nil {
+		return "", "", fmt.Errorf("cannot list replica sets, %w", err)
+	}
+
+	revision := w.GetAnnotations()[revisionAnnotation]
+	for _, rs := range replicaSets.Items {
+		if revision != "" && rs.Annotations[revisionAnnotation] == revision {
+			return appsv1.DefaultDeploymentUniqueLabelKey, rs.Labels[appsv1.DefaultDeploymentUniqueLabelKey], nil
+		}
+	}
+
+	return appsv1.DefaultDeploymentUniqueLabelKey, "", nil
+}
+
+// statefulSetRolloutInProgress reports whether the stateful set's pods are still being replaced or started
+func statefulSetRolloutInProgress(statefulSet *appsv1.StatefulSet) bool {
+	status := statefulSet.Status
+
+	if statefulSet.Generation > status.ObservedGeneration {
+		return true
+	}
+
+	replicas := ptr.Deref(statefulSet.Spec.Replicas, 1)
+	if status.Replicas != replicas {
+		return true
+	}
+
+	// pods of stateful sets with the `OnDelete` strategy are only replaced manually, so there is no rollout to wait for
+	if statefulSet.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType {
+		if status.CurrentRevision != status.UpdateRevision || status.UpdatedReplicas < replicas {
+			return true
+		}
+	}
+
+	return status.AvailableReplicas < replicas
+}
@@ -0,0 +1,72 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+)
+
+// HoltWinters forecasts a series with additive trend and seasonality using triple exponential smoothing
+type HoltWinters struct {
+	// Alpha, Beta and Gamma are the smoothing factors of level, trend and seasonality in the range [0, 1]
+	Alpha, Beta, Gamma float64
+	// SeasonLength is the number of points per season
+	SeasonLength int
+}
+
+// Forecast fits the model to the series and predicts the value `horizon` points after its last point,
+// the series must cover at least two seasons and forecasts are never negative
+func (hw HoltWinters) Forecast(series []float64, horizon int) (float64, error) {
+	m := hw.SeasonLength
+
+	if m < 1 {
+		return 0, fmt.Errorf("season length must be positive")
+	}
+
+	if len(series) < 2*m {
+		return 0, fmt.Errorf("series of %d points does not cover two seasons of %d points", len(series), m)
+	}
+
+	if horizon < 1 {
+		return 0, fmt.Errorf("horizon must be positive")
+	}
+
+	for _, factor := range []float64{hw.Alpha, hw.Beta, hw.Gamma} {
+		if factor < 0 || factor > 1 {
+			return 0, fmt.Errorf("smoothing factor %v out of range [0, 1]", factor)
+		}
+	}
+
+	firstSeason := mean(series[:m])
+	secondSeason := mean(series[m : 2*m])
+
+	level := firstSeason
+	trend := (secondSeason - firstSeason) / float64(m)
+
+	seasonal := make([]float64, m)
+	for i := 0; i < m; i++ {
+		seasonal[i] = series[i] - firstSeason
+	}
+
+	for t, value := range series {
+		s := seasonal[t%m]
+		previousLevel := level
+
+		level = hw.Alpha*(value-s) + (1-hw.Alpha)*(level+trend)
+		trend = hw.Beta*(level-previousLevel) + (1-hw.Beta)*trend
+		seasonal[t%m] = hw.Gamma*(value-level) + (1-hw.Gamma)*s
+	}
+
+	last := len(series) - 1
+	forecast := level + float64(horizon)*trend + seasonal[(last+horizon)%m]
+
+	return math.Max(forecast, 0), nil
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+
+	return sum / float64(len(values))
+}
@@ -0,0 +1,103 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHoltWinters_Forecast(t *testing.T) {
+	// a daily pattern of four points per day: low at night, high during the day
+	pattern := []float64{10, 50, 90, 50}
+
+	seasonal := make([]float64, 0)
+	for day := 0; day < 4; day++ {
+		seasonal = append(seasonal, pattern...)
+	}
+
+	trending := make([]float64, 0)
+	for i := 0; i < 16; i++ {
+		trending = append(trending, pattern[i%4]+float64(i))
+	}
+
+	tests := []struct {
+		name    string
+		hw      HoltWinters
+		series  []float64
+		horizon int
+		want    float64
+		// tolerance is the allowed deviation from want
+		tolerance float64
+		wantErr   bool
+	}{
+		{
+			name:    "constant series",
+			hw:      HoltWinters{Alpha: 0.5, Beta: 0.1, Gamma: 0.3, SeasonLength: 2},
+			series:  []float64{5, 5, 5, 5, 5, 5},
+			horizon: 3,
+			want:    5,
+		},
+		{
+			name:    "next point of a seasonal series",
+			hw:      HoltWinters{Alpha: 0.5, Beta: 0.1, Gamma: 0.3, SeasonLength: 4},
+			series:  seasonal,
+			horizon: 1,
+			want:    10,
+		},
+		{
+			name:    "peak of a seasonal series",
+			hw:      HoltWinters{Alpha: 0.5, Beta: 0.1, Gamma: 0.3, SeasonLength: 4},
+			series:  seasonal,
+			horizon: 3,
+			want:    90,
+		},
+		{
+			name:      "seasonal series with trend",
+			hw:        HoltWinters{Alpha: 0.5, Beta: 0.5, Gamma: 0.5, SeasonLength: 4},
+			series:    trending,
+			horizon:   3,
+			want:      108,
+			tolerance: 1,
+		},
+		{
+			name:    "forecast is never negative",
+			hw:      HoltWinters{Alpha: 1, Beta: 1, Gamma: 0, SeasonLength: 1},
+			series:  []float64{10, 5, 0},
+			horizon: 5,
+			want:    0,
+		},
+		{
+			name:    "less than two seasons",
+			hw:      HoltWinters{Alpha: 0.5, Beta: 0.1, Gamma: 0.3, SeasonLength: 4},
+			series:  pattern,
+			horizon: 1,
+			wantErr: true,
+		},
+		{
+			name:    "invalid smoothing factor",
+			hw:      HoltWinters{Alpha: 1.5, Beta: 0.1, Gamma: 0.3, SeasonLength: 4},
+			series:  seasonal,
+			horizon: 1,
+			wantErr: true,
+		},
+		{
+			name:    "invalid horizon",
+			hw:      HoltWinters{Alpha: 0.5, Beta: 0.1, Gamma: 0.3, SeasonLength: 4},
+			series:  seasonal,
+			horizon: 0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.hw.Forecast(tt.series, tt.horizon)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HoltWinters.Forecast() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if math.Abs(got-tt.want) > math.Max(tt.tolerance, 1e-6) {
+				t.Errorf("HoltWinters.Forecast() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package recommender
+
+import (
+	"math"
+	"time"
+)
+
+// maxDecayExponent bounds the growth of sample weights before the histogram is renormalized
+const maxDecayExponent = 100
+
+// histogramOptions define exponentially growing buckets, bucket `i` starts at `firstBucketSize * (ratio^i - 1) / (ratio - 1)`
+type histogramOptions struct {
+	firstBucketSize float64
+	ratio           float64
+	numBuckets      int
+}
+
+var (
+	// cpu usage in cores from 0.01 up to about 1000 cores
+	cpuHistogramOptions = newHistogramOptions(0.01, 1.05, 1000)
+	// memory usage in bytes from 10MB up to about 1TB
+	memoryHistogramOptions = newHistogramOptions(1e7, 1.05, 1e12)
+)
+
+func newHistogramOptions(firstBucketSize, ratio, maxValue float64) histogramOptions {
+	o := histogramOptions{firstBucketSize: firstBucketSize, ratio: ratio}
+	o.numBuckets = int(math.Ceil(math.Log(1+maxValue*(ratio-1)/firstBucketSize)/math.Log(ratio))) + 1
+
+	return o
+}
+
+func (o histogramOptions) bucket(value float64) int {
+	if value < o.firstBucketSize {
+		return 0
+	}
+
+	bucket := int(math.Floor(math.Log(1+value*(o.ratio-1)/o.firstBucketSize) / math.Log(o.ratio)))
+	if bucket >= o.numBuckets {
+		return o.numBuckets - 1
+	}
+
+	return bucket
+}
+
+func (o histogramOptions) bucketStart(bucket int) float64 {
+	return o.firstBucketSize * (math.Pow(o.ratio, float64(bucket)) - 1) / (o.ratio - 1)
+}
+
+// Histogram is a histogram of usage samples whose weights decay exponentially with the sample's age,
+// instead of decaying existing weights new samples are weighted by `2^((t - ReferenceTime) / halfLife)`
+type Histogram struct {
+	// Weights maps bucket indices to their weights, empty buckets are omitted
+	Weights       map[int]float64
+	TotalWeight   float64
+	ReferenceTime time.Time
+}
+
+func newHistogram(referenceTime time.Time) *Histogram {
+	return &Histogram{
+		Weights:       make(map[int]float64),
+		ReferenceTime: referenceTime,
+	}
+}
+
+// addSample adds the value with a weight growing with its time, which makes older samples count less
+func (h *Histogram) addSample(o histogramOptions, value float64, t time.Time, halfLife time.Duration) {
+	exponent := float64(t.Sub(h.ReferenceTime)) / float64(halfLife)
+	if exponent > maxDecayExponent {
+		h.shiftReferenceTime(t, halfLife)
+		exponent = 0
+	}
+
+	weight := math.Pow(2, exponent)
+	h.Weights[o.bucket(value)] += weight
+	h.TotalWeight += weight
+}
+
+// shiftReferenceTime rescales all weights to the new reference time, keeping their relation
+func (h *Histogram) shiftReferenceTime(referenceTime time.Time, halfLife time.Duration) {
+	factor := math.Pow(2, -float64(referenceTime.Sub(h.ReferenceTime))/float64(halfLife))
+
+	for bucket, weight := range h.Weights {
+		h.Weights[bucket] = weight * factor
+	}
+
+	h.TotalWeight *= factor
+	h.ReferenceTime = referenceTime
+}
+
+func (h *Histogram) empty() bool {
+	return h.TotalWeight <= 0
+}
+
+// percentile returns the end of the bucket in which the given share of the total weight is reached, `p` is in the range [0, 1]
+func (h *Histogram) percentile(o histogramOptions, p float64) float64 {
+	if h.empty() {
+		return 0
+	}
+
+	threshold := p * h.TotalWeight
+	sum := 0.0
+
+	for bucket := 0; bucket < o.numBuckets; bucket++ {
+		sum += h.Weights[bucket]
+		if sum >= threshold {
+			return o.bucketStart(bucket + 1)
+		}
+	}
+
+	return o.bucketStart(o.numBuckets)
+}
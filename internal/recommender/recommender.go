@@ -0,0 +1,137 @@
+package recommender
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// multipliers and exponents of the confidence bounds, the same as used by the VerticalPodAutoscaler
+	upperBoundMultiplier = 1.0
+	upperBoundExponent   = 1.0
+	lowerBoundMultiplier = 0.001
+	lowerBoundExponent   = -2.0
+	// minConfidence prevents infinitely wide bounds for histograms without any history
+	minConfidence = 1.0 / (24 * 60)
+)
+
+// Recommender keeps the usage histograms of each container
+type Recommender struct {
+	Containers map[string]*ContainerHistograms
+}
+
+// ContainerHistograms stores a container's cpu usage in cores and peak memory usage in bytes
+type ContainerHistograms struct {
+	CPU, Memory     *Histogram
+	FirstSampleTime time.Time
+}
+
+// Estimate is a usage recommendation together with its confidence bounds
+type Estimate struct {
+	Target, LowerBound, UpperBound float64
+}
+
+// Recommendation contains a container's cpu and memory usage estimates
+type Recommendation struct {
+	CPU, Memory Estimate
+}
+
+func New() *Recommender {
+	return &Recommender{
+		Containers: make(map[string]*ContainerHistograms),
+	}
+}
+
+// Decode restores a recommender from its encoded state, an empty state results in an empty recommender
+func Decode(encoded []byte) (*Recommender, error) {
+	r := New()
+
+	decoder := gob.NewDecoder(bytes.NewBuffer(encoded))
+	if err := decoder.Decode(r); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if r.Containers == nil {
+		r.Containers = make(map[string]*ContainerHistograms)
+	}
+
+	return r, nil
+}
+
+func (r *Recommender) Encode() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+
+	if err := gob.NewEncoder(buffer).Encode(r); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// AddCPUSample adds a cpu usage sample in cores for the container
+func (r *Recommender) AddCPUSample(container string, value float64, t time.Time, halfLife time.Duration) {
+	r.container(container, t).CPU.addSample(cpuHistogramOptions, value, t, halfLife)
+}
+
+// AddMemorySample adds a peak memory usage sample in bytes for the container
+func (r *Recommender) AddMemorySample(container string, value float64, t time.Time, halfLife time.Duration) {
+	r.container(container, t).Memory.addSample(memoryHistogramOptions, value, t, halfLife)
+}
+
+func (r *Recommender) container(name string, t time.Time) *ContainerHistograms {
+	c, ok := r.Containers[name]
+	if !ok {
+		c = &ContainerHistograms{
+			CPU:             newHistogram(t),
+			Memory:          newHistogram(t),
+			FirstSampleTime: t,
+		}
+		r.Containers[name] = c
+	}
+
+	return c
+}
+
+// Prune removes the histograms of all containers not contained in `containers`
+func (r *Recommender) Prune(containers []string) {
+	keep := make(map[string]bool)
+	for _, name := range containers {
+		keep[name] = true
+	}
+
+	for name := range r.Containers {
+		if !keep[name] {
+			delete(r.Containers, name)
+		}
+	}
+}
+
+// Recommend returns the usage at the given percentiles, which are in the range [0, 1],
+// the confidence bounds are the wider the younger the container's history is,
+// it returns false if there are no samples for one of the resources yet
+func (r *Recommender) Recommend(container string, cpuPercentile, memoryPercentile float64, now time.Time) (*Recommendation, bool) {
+	c, ok := r.Containers[container]
+	if !ok || c.CPU.empty() || c.Memory.empty() {
+		return nil, false
+	}
+
+	confidence := math.Max(now.Sub(c.FirstSampleTime).Hours()/24, minConfidence)
+
+	return &Recommendation{
+		CPU:    estimate(c.CPU.percentile(cpuHistogramOptions, cpuPercentile), confidence),
+		Memory: estimate(c.Memory.percentile(memoryHistogramOptions, memoryPercentile), confidence),
+	}, true
+}
+
+// estimate widens the bounds around the target by `target * (1 + multiplier / confidence)^exponent`,
+// confidence being the age of the history in days
+func estimate(target, confidence float64) Estimate {
+	return Estimate{
+		Target:     target,
+		LowerBound: target * math.Pow(1+lowerBoundMultiplier/confidence, lowerBoundExponent),
+		UpperBound: target * math.Pow(1+upperBoundMultiplier/confidence, upperBoundExponent),
+	}
+}
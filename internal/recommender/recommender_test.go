@@ -0,0 +1,200 @@
+package recommender
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHistogram_percentile(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	halfLife := 24 * time.Hour
+
+	tests := []struct {
+		name    string
+		samples []float64
+		// age of the first half of the samples
+		age        time.Duration
+		percentile float64
+		want       float64
+	}{
+		{
+			name:       "empty histogram",
+			percentile: 0.9,
+			want:       0,
+		},
+		{
+			name:       "single sample",
+			samples:    []float64{1},
+			percentile: 0.9,
+			want:       cpuHistogramOptions.bucketStart(cpuHistogramOptions.bucket(1) + 1),
+		},
+		{
+			name:       "median of equally weighted samples",
+			samples:    []float64{1, 1, 4, 4},
+			percentile: 0.5,
+			want:       cpuHistogramOptions.bucketStart(cpuHistogramOptions.bucket(1) + 1),
+		},
+		{
+			name:       "older samples count less",
+			samples:    []float64{1, 1, 4, 4},
+			age:        2 * halfLife,
+			percentile: 0.5,
+			want:       cpuHistogramOptions.bucketStart(cpuHistogramOptions.bucket(4) + 1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHistogram(start)
+			for i, value := range tt.samples {
+				sampleTime := start.Add(tt.age)
+				if i < len(tt.samples)/2 {
+					sampleTime = start
+				}
+
+				h.addSample(cpuHistogramOptions, value, sampleTime, halfLife)
+			}
+
+			if got := h.percentile(cpuHistogramOptions, tt.percentile); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Histogram.percentile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistogram_shiftReferenceTime(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	halfLife := time.Hour
+
+	h := newHistogram(start)
+	h.addSample(cpuHistogramOptions, 1, start, halfLife)
+	h.addSample(cpuHistogramOptions, 4, start.Add(101*halfLife), halfLife)
+
+	if !h.ReferenceTime.Equal(start.Add(101 * halfLife)) {
+		t.Errorf("Histogram.addSample() did not shift reference time, got %v", h.ReferenceTime)
+	}
+
+	if math.IsInf(h.TotalWeight, 0) || h.TotalWeight > 2 {
+		t.Errorf("Histogram.addSample() total weight = %v, want at most 2", h.TotalWeight)
+	}
+
+	if got, want := h.percentile(cpuHistogramOptions, 0.5), cpuHistogramOptions.bucketStart(cpuHistogramOptions.bucket(4)+1); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Histogram.percentile() = %v, want %v", got, want)
+	}
+}
+
+func TestRecommender_Recommend(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	halfLife := 24 * time.Hour
+
+	r := New()
+	r.AddCPUSample("container", 0.5, start, halfLife)
+	r.AddMemorySample("container", 1e9, start, halfLife)
+	r.AddCPUSample("cpu-only", 0.5, start, halfLife)
+
+	tests := []struct {
+		name      string
+		container string
+		now       time.Time
+		wantOk    bool
+	}{
+		{
+			name:      "unknown container",
+			container: "unknown",
+			now:       start,
+			wantOk:    false,
+		},
+		{
+			name:      "missing memory samples",
+			container: "cpu-only",
+			now:       start,
+			wantOk:    false,
+		},
+		{
+			name:      "young history",
+			container: "container",
+			now:       start.Add(time.Hour),
+			wantOk:    true,
+		},
+		{
+			name:      "old history",
+			container: "container",
+			now:       start.Add(7 * 24 * time.Hour),
+			wantOk:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := r.Recommend(tt.container, 0.9, 0.99, tt.now)
+			if ok != tt.wantOk {
+				t.Errorf("Recommender.Recommend() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+
+			if !ok {
+				return
+			}
+
+			for _, e := range []Estimate{got.CPU, got.Memory} {
+				if e.LowerBound > e.Target || e.Target > e.UpperBound {
+					t.Errorf("Recommender.Recommend() target %v not within bounds [%v, %v]", e.Target, e.LowerBound, e.UpperBound)
+				}
+			}
+		})
+	}
+
+	young, _ := r.Recommend("container", 0.9, 0.99, start.Add(time.Hour))
+	old, _ := r.Recommend("container", 0.9, 0.99, start.Add(7*24*time.Hour))
+
+	if young.CPU.UpperBound-young.CPU.LowerBound <= old.CPU.UpperBound-old.CPU.LowerBound {
+		t.Errorf("Recommender.Recommend() bounds of young history %v are not wider than those of old history %v", young.CPU, old.CPU)
+	}
+}
+
+func TestRecommender_Encode(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r := New()
+	r.AddCPUSample("container", 0.5, start, time.Hour)
+	r.AddMemorySample("container", 1e9, start, time.Hour)
+
+	encoded, err := r.Encode()
+	if err != nil {
+		t.Errorf("Recommender.Encode() error = %v", err)
+		return
+	}
+
+	decoded, err := Decode(encoded)
+	if err != nil {
+		t.Errorf("Decode() error = %v", err)
+		return
+	}
+
+	want, _ := r.Recommend("container", 0.9, 0.99, start)
+	got, ok := decoded.Recommend("container", 0.9, 0.99, start)
+	if !ok || *got != *want {
+		t.Errorf("Decode() recommendation = %v, want %v", got, want)
+	}
+
+	empty, err := Decode(nil)
+	if err != nil || len(empty.Containers) != 0 {
+		t.Errorf("Decode() of empty state = %v, %v", empty, err)
+	}
+}
+
+func TestRecommender_Prune(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r := New()
+	r.AddCPUSample("kept", 0.5, start, time.Hour)
+	r.AddCPUSample("removed", 0.5, start, time.Hour)
+	r.Prune([]string{"kept"})
+
+	if _, ok := r.Containers["removed"]; ok {
+		t.Errorf("Recommender.Prune() kept removed container")
+	}
+
+	if _, ok := r.Containers["kept"]; !ok {
+		t.Errorf("Recommender.Prune() removed kept container")
+	}
+}
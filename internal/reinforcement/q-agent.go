@@ -1,9 +1,8 @@
 package reinforcement
 
 import (
-	"crypto/rand"
 	"fmt"
-	"math/big"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/iljarotar/hybrid-scaler/internal/scaling"
@@ -31,6 +30,7 @@ var (
 
 // stateName represents a state as a string of the form
 // <replicas>_<cpu-requests>_<memory-requests>_<cpu-utilization-ratio>_<memory-utilization-ratio>
+// optionally followed by _f<cpu-forecast-utilization-ratio>_<memory-forecast-utilization-ratio> and _t<time-of-day-bucket>
 type stateName string
 
 type state struct {
@@ -40,6 +40,13 @@ type state struct {
 	CpuRequests, MemoryRequests                   *inf.Dec
 	CpuUtilization, MemoryUtilization             *inf.Dec
 	CpuTargetUtilization, MemoryTargetUtilization *inf.Dec
+
+	// OOMKills is the number of out-of-memory kills of all containers since the last decision
+	OOMKills int32
+	// ThrottlingRatio is the highest throttling ratio of all containers, nil if unknown
+	ThrottlingRatio *inf.Dec
+	// UnschedulablePods is the number of pods which could not be scheduled since the last decision
+	UnschedulablePods int32
 }
 
 type qAgent struct {
@@ -47,42 +54,62 @@ type qAgent struct {
 	logger          logr.Logger
 	epsilon         *inf.Dec
 	possibleActions actions
+	// timeOfDayBuckets is the number of buckets the day is split into, zero disables the time of day as a state dimension
+	timeOfDayBuckets int32
+	random           RandomSource
 }
 
-func NewQAgent(cpuCost, memoryCost, underprovisioningPenalty, alpha, gamma, epsilon *inf.Dec) *qAgent {
+func NewQAgent(cpuCost, memoryCost, underprovisioningPenalty, oomPenalty, throttlingPenalty, unschedulablePenalty, alpha, gamma, epsilon, lambda *inf.Dec, traceLength, timeOfDayBuckets int32) *qAgent {
 	possibleActions := allActions
 	logger := log.Log.WithName("q-learning agent")
-	qLearning := NewQLearning(cpuCost, memoryCost, underprovisioningPenalty, alpha, gamma, possibleActions, logger)
+	qLearning := NewQLearning(cpuCost, memoryCost, underprovisioningPenalty, oomPenalty, throttlingPenalty, unschedulablePenalty, alpha, gamma, lambda, traceLength, possibleActions, logger)
 
 	return &qAgent{
-		logger:          logger,
-		QLearning:       *qLearning,
-		epsilon:         epsilon,
-		possibleActions: possibleActions,
+		logger:           logger,
+		QLearning:        *qLearning,
+		epsilon:          epsilon,
+		possibleActions:  possibleActions,
+		timeOfDayBuckets: timeOfDayBuckets,
+		random:           CryptoRandomSource{},
 	}
 }
 
+// WithRandomSource replaces the agent's random source, which makes its exploration reproducible if the source is seeded
+func (a *qAgent) WithRandomSource(random RandomSource) *qAgent {
+	a.random = random
+	return a
+}
+
+// MakeDecision observes the outcome of the previous decision in the current state before it decides on the next action
 func (a *qAgent) MakeDecision(state *strategy.State, learningState []byte) (*strategy.ScalingDecision, []byte, error) {
-	s, err := convertState(state)
+	s, err := convertState(state, a.timeOfDayBuckets)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	greedy, err := iAmGreedy(a.epsilon)
+	ls, err := decodeToLearningState(learningState)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot decode learning state, %w", err)
+	}
+
+	a.logger.Info("current learning state", "learning state", ls)
+
+	if err := a.Observe(s, ls); err != nil {
+		return nil, nil, fmt.Errorf("cannot observe outcome of the previous decision, %w", err)
+	}
+
+	greedy, err := iAmGreedy(a.epsilon, a.random)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot decide which action to choose, %w", err)
 	}
 
-	possibleActions := a.possibleActions
+	possibleActions := allowedActions(a.possibleActions, state.Constraints)
 
 	if greedy {
-		possibleActions, err = a.GetGreedyActions(s.Name, learningState)
-		if err != nil {
-			return nil, nil, fmt.Errorf("cannot get greedy actions, %w", err)
-		}
+		possibleActions = greedyActionsFrom(s.Name, possibleActions, ls.Table)
 	}
 
-	action, err := getRandomActionFrom(possibleActions)
+	action, err := getRandomActionFrom(possibleActions, a.random)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot decide which action to choose, %w", err)
 	}
@@ -92,9 +119,11 @@ func (a *qAgent) MakeDecision(state *strategy.State, learningState []byte) (*str
 		return nil, nil, err
 	}
 
-	newLearningState, err := a.Update(s, action, learningState)
+	a.Decide(s, *action, decisionTarget(decision), ls)
+
+	newLearningState, err := encodeLearningState(ls)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("cannot encode learning state, %w", err)
 	}
 
 	a.logger.Info("scaling decision", "decision", decision, "action", action, "state", s, "greedy", greedy)
@@ -102,7 +131,28 @@ func (a *qAgent) MakeDecision(state *strategy.State, learningState []byte) (*str
 	return decision, newLearningState, nil
 }
 
-func convertState(s *strategy.State) (*state, error) {
+// decisionTarget returns the configuration the decision scales the workload to
+func decisionTarget(decision *strategy.ScalingDecision) target {
+	t := target{
+		Replicas:       decision.Replicas,
+		CpuRequests:    inf.NewDec(0, 0),
+		MemoryRequests: inf.NewDec(0, 0),
+	}
+
+	for _, resources := range decision.ContainerResources {
+		if resources.Requests.CPU != nil {
+			t.CpuRequests.Add(t.CpuRequests, resources.Requests.CPU)
+		}
+
+		if resources.Requests.Memory != nil {
+			t.MemoryRequests.Add(t.MemoryRequests, resources.Requests.Memory)
+		}
+	}
+
+	return t
+}
+
+func convertState(s *strategy.State, timeOfDayBuckets int32) (*state, error) {
 	zero := inf.NewDec(0, 0)
 	hundred := inf.NewDec(100, 0)
 
@@ -169,6 +219,17 @@ func convertState(s *strategy.State) (*state, error) {
 	}
 
 	name := fmt.Sprintf("%d_%d_%d_%d_%d", s.Replicas, cpuLimitsQuantized, memoryLimitsQuantized, cpuUtilizationRatioQuantized, memoryUtilizationRatioQuantized)
+	if forecast := s.PodMetrics.ForecastUsage; forecast != nil {
+		cpuForecastRatio := quantizedUtilizationRatio(forecast.CPU, podCpuRequests, cpuTargetUtilization)
+		memoryForecastRatio := quantizedUtilizationRatio(forecast.Memory, podMemoryRequests, memoryTargetUtilization)
+		name = fmt.Sprintf("%s_f%d_%d", name, cpuForecastRatio, memoryForecastRatio)
+	}
+
+	if timeOfDayBuckets > 0 {
+		name = fmt.Sprintf("%s_t%d", name, timeOfDayBucket(s.Time, timeOfDayBuckets))
+	}
+
+	oomKills, throttlingRatio := aggregatePressure(s.Pressure)
 
 	return &state{
 		Name:                    stateName(name),
@@ -179,9 +240,47 @@ func convertState(s *strategy.State) (*state, error) {
 		MemoryUtilization:       memoryUsageInPercent,
 		CpuTargetUtilization:    cpuTargetUtilization,
 		MemoryTargetUtilization: memoryTargetUtilization,
+		OOMKills:                oomKills,
+		UnschedulablePods:       s.UnschedulablePods,
+		ThrottlingRatio:         throttlingRatio,
 	}, nil
 }
 
+// aggregatePressure sums up the out-of-memory kills and returns the highest throttling ratio of all containers
+func aggregatePressure(pressure map[string]strategy.Pressure) (oomKills int32, throttlingRatio *inf.Dec) {
+	for _, p := range pressure {
+		oomKills += p.OOMKills
+
+		if p.ThrottlingRatio != nil && (throttlingRatio == nil || p.ThrottlingRatio.Cmp(throttlingRatio) > 0) {
+			throttlingRatio = p.ThrottlingRatio
+		}
+	}
+
+	return oomKills, throttlingRatio
+}
+
+// quantizedUtilizationRatio returns the ratio of utilization to target utilization in percent, quantized and capped at 100
+func quantizedUtilizationRatio(usage, requests, targetUtilization *inf.Dec) int64 {
+	hundred := inf.NewDec(100, 0)
+
+	utilization := new(inf.Dec).QuoRound(usage, requests, 8, inf.RoundHalfUp)
+	ratio := new(inf.Dec).QuoRound(utilization, targetUtilization, 8, inf.RoundHalfUp)
+
+	quantized := quantizePercentage(new(inf.Dec).Mul(ratio, hundred), percentageQuantum)
+	if quantized > 100 {
+		return 100
+	}
+
+	return quantized
+}
+
+// timeOfDayBucket returns the index of the bucket of the day `t` falls into, the day is taken in the location of `t`
+func timeOfDayBucket(t time.Time, buckets int32) int32 {
+	minuteOfDay := int32(t.Hour()*60 + t.Minute())
+
+	return minuteOfDay * buckets / (24 * 60)
+}
+
 func (a *qAgent) convertAction(chosenAction action, s *strategy.State) (*strategy.ScalingDecision, error) {
 	decision := &strategy.ScalingDecision{
 		Replicas:           s.Replicas,
@@ -211,29 +310,44 @@ func (a *qAgent) convertAction(chosenAction action, s *strategy.State) (*strateg
 	return decision, err
 }
 
-func getRandomActionFrom(as actions) (*action, error) {
+// allowedActions removes all actions that would change what the constraints keep fixed
+func allowedActions(as actions, c strategy.Constraints) actions {
+	allowed := make(actions, 0)
+
+	for _, a := range as {
+		switch {
+		case c.FixedReplicas && (a == actionHorizontal || a == actionHybrid):
+		case c.FixedResources && (a == actionVertical || a == actionHybrid):
+		default:
+			allowed = append(allowed, a)
+		}
+	}
+
+	return allowed
+}
+
+func getRandomActionFrom(as actions, random RandomSource) (*action, error) {
 	if len(as) < 1 {
 		return nil, fmt.Errorf("no actions to choose from")
 	}
 
-	index, err := rand.Int(rand.Reader, big.NewInt(int64(len(as))))
+	idx, err := random.Intn(len(as))
 	if err != nil {
 		return nil, err
 	}
 
-	idx := int(index.Int64())
 	a := as[idx]
 
 	return &a, nil
 }
 
-func iAmGreedy(epsilon *inf.Dec) (bool, error) {
-	random, err := rand.Int(rand.Reader, big.NewInt(1000))
+func iAmGreedy(epsilon *inf.Dec, random RandomSource) (bool, error) {
+	value, err := random.Intn(1000)
 	if err != nil {
 		return false, err
 	}
 
-	randomDec := new(inf.Dec).SetUnscaledBig(random).SetScale(3)
+	randomDec := inf.NewDec(int64(value), 3)
 
 	return randomDec.Cmp(epsilon) >= 0, nil
 }
@@ -13,19 +13,32 @@ import (
 
 type QLearning struct {
 	cpuCost, memoryCost, underprovisioningPenalty, alpha, gamma *inf.Dec
-	allActions                                                  actions
-	logger                                                      logr.Logger
+	// oomPenalty is added for each out-of-memory kill, throttlingPenalty is weighted by the throttling ratio of each replica
+	oomPenalty, throttlingPenalty *inf.Dec
+	// unschedulablePenalty is added for each pod which could not be scheduled
+	unschedulablePenalty *inf.Dec
+	// lambda is the factor by which the eligibility of earlier state-action pairs decays each step, zero only updates the last pair
+	lambda *inf.Dec
+	// traceLength is the maximum number of recent state-action pairs which are updated
+	traceLength int32
+	allActions  actions
+	logger      logr.Logger
 }
 
-func NewQLearning(cpuCost, memoryCost, underprovisioningPenalty, alpha, gamma *inf.Dec, possibleActions actions, logger logr.Logger) *QLearning {
+func NewQLearning(cpuCost, memoryCost, underprovisioningPenalty, oomPenalty, throttlingPenalty, unschedulablePenalty, alpha, gamma, lambda *inf.Dec, traceLength int32, possibleActions actions, logger logr.Logger) *QLearning {
 	return &QLearning{
 		logger:                   logger,
 		allActions:               possibleActions,
 		cpuCost:                  cpuCost,
 		memoryCost:               memoryCost,
 		underprovisioningPenalty: underprovisioningPenalty,
+		oomPenalty:               oomPenalty,
+		throttlingPenalty:        throttlingPenalty,
+		unschedulablePenalty:     unschedulablePenalty,
 		alpha:                    alpha,
 		gamma:                    gamma,
+		lambda:                   lambda,
+		traceLength:              traceLength,
 	}
 }
 
@@ -33,99 +46,167 @@ type qTable map[stateName]qTableRow
 type qTableRow map[action]*inf.Dec
 
 type learningState struct {
-	Table          qTable
-	PreviousState  *state
-	PreviousAction *action
+	Table qTable
+	// Pending is the last decision, whose outcome has not been observed yet
+	Pending *transition
+	// Traces are the eligibilities of the most recently visited state-action pairs, the most recent last
+	Traces []trace
 }
 
-var initialValue = inf.NewDec(0, 0)
+// trace is the eligibility of a visited state-action pair to be credited with the costs observed after it
+type trace struct {
+	State       stateName
+	Action      action
+	Eligibility *inf.Dec
+}
 
-func (l *QLearning) Update(currentState *state, currentAction *action, learningStateEncoded []byte) ([]byte, error) {
-	ls, err := decodeToLearningState(learningStateEncoded)
-	if err != nil {
-		return nil, fmt.Errorf("cannot decode learning state, %w", err)
+// transition is an action taken in a state together with the configuration it scales the workload to
+type transition struct {
+	State  *state
+	Action action
+	Target target
+}
+
+// target is the configuration of the workload after a decision has been applied
+type target struct {
+	Replicas                    int32
+	CpuRequests, MemoryRequests *inf.Dec
+}
+
+var (
+	initialValue = inf.NewDec(0, 0)
+	// targetTolerance is the relative deviation from a target's requests up to which it counts as reached, which allows for rounding
+	targetTolerance = inf.NewDec(5, 2)
+)
+
+// Observe resolves the pending transition with the observed state, which is its outcome once the workload reached the target.
+// If the workload was left unchanged instead, the outcome is credited to not scaling, otherwise it is discarded, since it
+// cannot be attributed to any action.
+func (l *QLearning) Observe(observed *state, ls *learningState) error {
+	pending := ls.Pending
+	if pending == nil {
+		return nil
 	}
 
-	l.logger.Info("current learning state", "learning state", ls)
+	ls.Pending = nil
+
+	taken := pending.Action
+	switch {
+	case observed.reached(pending.Target):
+	case observed.reached(pending.State.configuration()):
+		taken = actionNone
+	default:
+		l.logger.Info("discarding transition, the workload reached neither the target nor stayed unchanged", "transition", pending, "observed", observed)
+		ls.Traces = nil
+		return nil
+	}
 
+	if ls.Table == nil {
+		ls.Table = make(qTable)
+	}
 	table := ls.Table
-	previousState := ls.PreviousState
-	previousAction := ls.PreviousAction
 
-	if previousState == nil || previousAction == nil {
-		previousState = currentState
-		previousAction = currentAction
+	if _, ok := table[pending.State.Name]; !ok {
+		l.initializeRow(pending.State.Name, table)
+	}
+
+	currentValue, ok := table[pending.State.Name][taken]
+	if !ok {
+		currentValue = initialValue
+	}
+
+	tdError, err := l.temporalDifference(currentValue, observed, table)
+	if err != nil {
+		return fmt.Errorf("cannot calculate new value for q table, %w", err)
+	}
 
-		newLearningState := &learningState{
-			Table:          make(qTable),
-			PreviousState:  previousState,
-			PreviousAction: previousAction,
+	traces := l.updateTraces(ls.Traces, pending.State.Name, taken)
+	for _, t := range traces {
+		if _, ok := table[t.State]; !ok {
+			l.initializeRow(t.State, table)
 		}
 
-		encoded, err := encodeLearningState(newLearningState)
-		if err != nil {
-			return nil, fmt.Errorf("cannot encode learning state, %w", err)
+		value, ok := table[t.State][t.Action]
+		if !ok {
+			value = initialValue
 		}
-		return encoded, nil
-	}
 
-	if table == nil {
-		table = make(qTable)
+		table[t.State][t.Action] = l.creditedValue(value, tdError, t.Eligibility)
 	}
 
-	if _, ok := table[previousState.Name]; !ok {
-		l.initializeRow(previousState.Name, table)
+	ls.Traces = traces
+	return nil
+}
+
+// Decide records the action taken in the state as pending until its outcome is observed
+func (l *QLearning) Decide(s *state, a action, t target, ls *learningState) {
+	// costs observed after an exploratory action say nothing about the greedy policy, so earlier pairs are not credited with them
+	if !isGreedyAction(s.Name, a, ls.Table) {
+		ls.Traces = nil
 	}
 
-	var currentValue *inf.Dec
-	_, ok := table[previousState.Name][*previousAction]
-	if ok {
-		currentValue = table[previousState.Name][*previousAction]
-	} else {
-		currentValue = initialValue
+	ls.Pending = &transition{
+		State:  s,
+		Action: a,
+		Target: t,
 	}
+}
 
-	newValue, err := l.newQValue(currentValue, l.alpha, l.gamma, currentState, table)
-	if err != nil {
-		return nil, fmt.Errorf("cannot calculate new value for q table, %w", err)
+// configuration returns the configuration of the workload in the state
+func (s *state) configuration() target {
+	return target{
+		Replicas:       s.Replicas,
+		CpuRequests:    s.CpuRequests,
+		MemoryRequests: s.MemoryRequests,
 	}
-	table[previousState.Name][*previousAction] = newValue
+}
 
-	ls.Table = table
-	ls.PreviousAction = currentAction
-	ls.PreviousState = currentState
+// reached reports whether the state's configuration matches the target
+func (s *state) reached(t target) bool {
+	return s.Replicas == t.Replicas && withinTolerance(s.CpuRequests, t.CpuRequests) && withinTolerance(s.MemoryRequests, t.MemoryRequests)
+}
 
-	encoded, err := encodeLearningState(ls)
-	if err != nil {
-		return nil, fmt.Errorf("cannot encode learning state, %w", err)
+func withinTolerance(value, target *inf.Dec) bool {
+	if value == nil || target == nil {
+		return value == target
 	}
 
-	return encoded, nil
+	deviation := new(inf.Dec).Sub(value, target)
+	deviation.Abs(deviation)
+	allowed := new(inf.Dec).Mul(targetTolerance, new(inf.Dec).Abs(target))
+
+	return deviation.Cmp(allowed) <= 0
 }
 
-func (l *QLearning) GetGreedyActions(state stateName, learningState []byte) (actions, error) {
-	ls, err := decodeToLearningState(learningState)
-	if err != nil {
-		return nil, fmt.Errorf("cannot decode learning state, %w", err)
+// greedyActionsFrom returns the candidates with the lowest expected cost in the given state,
+// all candidates if none of them has been tried in the state yet
+func greedyActionsFrom(state stateName, candidates actions, table qTable) actions {
+	row, ok := table[state]
+	if !ok {
+		return candidates
 	}
-	table := ls.Table
 
-	greedyActions := make(actions, 0)
-	bestValue := bestActionValueInState(state, table)
+	var bestValue *inf.Dec
+	for _, a := range candidates {
+		value, ok := row[a]
+		if ok && (bestValue == nil || value.Cmp(bestValue) < 0) {
+			bestValue = value
+		}
+	}
 
-	if _, ok := table[state]; !ok {
-		return l.allActions, nil
+	if bestValue == nil {
+		return candidates
 	}
 
-	row := table[state]
-	for _, a := range l.allActions {
+	greedyActions := make(actions, 0)
+	for _, a := range candidates {
 		value, ok := row[a]
 		if ok && value.Cmp(bestValue) <= 0 {
 			greedyActions = append(greedyActions, a)
 		}
 	}
 
-	return greedyActions, nil
+	return greedyActions
 }
 
 func (l *QLearning) evaluateCost(s *state) (*inf.Dec, error) {
@@ -167,6 +248,22 @@ func (l *QLearning) evaluateCost(s *state) (*inf.Dec, error) {
 	totalPodCost := new(inf.Dec).Add(podCpuCost, podMemoryCost)
 	totalCost := new(inf.Dec).Mul(totalPodCost, replicas)
 
+	if l.oomPenalty != nil && s.OOMKills > 0 {
+		oomPenalty := new(inf.Dec).Mul(l.oomPenalty, inf.NewDec(int64(s.OOMKills), 0))
+		totalCost.Add(totalCost, oomPenalty)
+	}
+
+	if l.throttlingPenalty != nil && s.ThrottlingRatio != nil {
+		throttlingPenalty := new(inf.Dec).Mul(l.throttlingPenalty, s.ThrottlingRatio)
+		throttlingPenalty.Mul(throttlingPenalty, replicas)
+		totalCost.Add(totalCost, throttlingPenalty)
+	}
+
+	if l.unschedulablePenalty != nil && s.UnschedulablePods > 0 {
+		unschedulablePenalty := new(inf.Dec).Mul(l.unschedulablePenalty, inf.NewDec(int64(s.UnschedulablePods), 0))
+		totalCost.Add(totalCost, unschedulablePenalty)
+	}
+
 	return totalCost, nil
 }
 
@@ -227,7 +324,9 @@ func encodeLearningState(s *learningState) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
-func (l *QLearning) newQValue(currentValue, alpha, gamma *inf.Dec, s *state, table qTable) (*inf.Dec, error) {
+// temporalDifference returns the difference between the cost estimate of the previous state-action pair and its
+// current value, which is the observed cost plus the discounted best value of the current state
+func (l *QLearning) temporalDifference(currentValue *inf.Dec, s *state, table qTable) (*inf.Dec, error) {
 	bestNextValue := bestActionValueInState(s.Name, table)
 	discountedBestNextValue := new(inf.Dec).Mul(l.gamma, bestNextValue)
 	currentNegative := new(inf.Dec).Neg(currentValue)
@@ -237,10 +336,63 @@ func (l *QLearning) newQValue(currentValue, alpha, gamma *inf.Dec, s *state, tab
 		return nil, err
 	}
 
-	newCostEstimate := new(inf.Dec).Add(cost, new(inf.Dec).Add(discountedBestNextValue, currentNegative))
-	difference := new(inf.Dec).Mul(l.alpha, newCostEstimate)
+	return new(inf.Dec).Add(cost, new(inf.Dec).Add(discountedBestNextValue, currentNegative)), nil
+}
+
+// creditedValue moves the value towards the new cost estimate in proportion to the learning rate and the eligibility
+func (l *QLearning) creditedValue(value, tdError, eligibility *inf.Dec) *inf.Dec {
+	difference := new(inf.Dec).Mul(l.alpha, tdError)
+	difference.Mul(difference, eligibility)
 
-	newValue := new(inf.Dec).Add(currentValue, difference)
+	newValue := new(inf.Dec).Add(value, difference)
 	newValue.Round(newValue, 4, inf.RoundHalfUp)
-	return newValue, nil
+	return newValue
+}
+
+// updateTraces decays the eligibility of all traces, drops those which are no longer eligible and appends the visited
+// state-action pair with full eligibility, keeping at most traceLength traces
+func (l *QLearning) updateTraces(traces []trace, s stateName, a action) []trace {
+	zero := inf.NewDec(0, 0)
+	decay := zero
+	if l.lambda != nil {
+		decay = new(inf.Dec).Mul(l.gamma, l.lambda)
+	}
+
+	updated := make([]trace, 0, len(traces)+1)
+	for _, t := range traces {
+		if t.State == s && t.Action == a {
+			continue
+		}
+
+		eligibility := new(inf.Dec).Mul(t.Eligibility, decay)
+		eligibility.Round(eligibility, 8, inf.RoundHalfUp)
+		if eligibility.Cmp(zero) <= 0 {
+			continue
+		}
+
+		updated = append(updated, trace{State: t.State, Action: t.Action, Eligibility: eligibility})
+	}
+
+	updated = append(updated, trace{State: s, Action: a, Eligibility: inf.NewDec(1, 0)})
+
+	traceLength := int(l.traceLength)
+	if traceLength < 1 {
+		traceLength = 1
+	}
+
+	if len(updated) > traceLength {
+		updated = updated[len(updated)-traceLength:]
+	}
+
+	return updated
+}
+
+// isGreedyAction returns true if no action has a lower value than `a` in the given state
+func isGreedyAction(s stateName, a action, table qTable) bool {
+	value, ok := table[s][a]
+	if !ok {
+		value = initialValue
+	}
+
+	return value.Cmp(bestActionValueInState(s, table)) <= 0
 }
@@ -0,0 +1,43 @@
+package reinforcement
+
+import (
+	"crypto/rand"
+	"math/big"
+	mathrand "math/rand"
+	"sync"
+)
+
+// RandomSource draws the random numbers the agent explores with
+type RandomSource interface {
+	// Intn returns a uniformly distributed number in [0, n)
+	Intn(n int) (int, error)
+}
+
+// CryptoRandomSource draws from crypto/rand and is used by default
+type CryptoRandomSource struct{}
+
+func (CryptoRandomSource) Intn(n int) (int, error) {
+	value, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(value.Int64()), nil
+}
+
+// SeededRandomSource draws a reproducible sequence from math/rand, which makes tests and simulations deterministic
+type SeededRandomSource struct {
+	mu     sync.Mutex
+	random *mathrand.Rand
+}
+
+func NewSeededRandomSource(seed int64) *SeededRandomSource {
+	return &SeededRandomSource{random: mathrand.New(mathrand.NewSource(seed))}
+}
+
+func (s *SeededRandomSource) Intn(n int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.random.Intn(n), nil
+}
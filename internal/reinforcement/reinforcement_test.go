@@ -2,8 +2,11 @@ package reinforcement
 
 import (
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/iljarotar/hybrid-scaler/internal/strategy"
 	"gopkg.in/inf.v0"
 )
@@ -157,72 +160,14 @@ func TestQLearning_bestActionValueInState(t *testing.T) {
 	}
 }
 
-func TestQLearning_GetGreedyActions(t *testing.T) {
-	l := &QLearning{
-		allActions: allActions,
-	}
-	tests := []struct {
-		name          string
-		state         stateName
-		learningState *learningState
-		want          actions
-		wantErr       bool
-	}{
-		{
-			name:  "no entry for this state yet",
-			state: "state1",
-			learningState: &learningState{
-				Table: qTable{},
-			},
-			want:    allActions,
-			wantErr: false,
-		},
-		{
-			name:  "choose cheapest actions",
-			state: "state1",
-			learningState: &learningState{
-				Table: qTable{
-					"state1": {
-						actionNone:       inf.NewDec(1, 0),
-						actionHorizontal: inf.NewDec(1, 0),
-						actionVertical:   inf.NewDec(2, 0),
-						actionHybrid:     inf.NewDec(1, 0),
-					},
-				},
-			},
-			want: actions{
-				actionNone,
-				actionHorizontal,
-				actionHybrid,
-			},
-			wantErr: false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			learningState, err := encodeLearningState(tt.learningState)
-			if err != nil {
-				t.Errorf("QLearning.GetGreedyActions() q-table encoding error = %v", err)
-			}
-
-			got, err := l.GetGreedyActions(tt.state, learningState)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("QLearning.GetGreedyActions() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-
-			if diff := cmp.Diff(tt.want, got); diff != "" {
-				t.Errorf("QLearning.GetGreedyActions() %v", diff)
-			}
-		})
-	}
-}
-
 func TestQLearning_evaluateCost(t *testing.T) {
 	l := &QLearning{
 		cpuCost:                  inf.NewDec(1, 0),
 		memoryCost:               inf.NewDec(1, 9),
 		underprovisioningPenalty: inf.NewDec(2, 0),
+		oomPenalty:               inf.NewDec(1, 0),
+		throttlingPenalty:        inf.NewDec(1, 0),
+		unschedulablePenalty:     inf.NewDec(5, 0),
 	}
 	tests := []struct {
 		name    string
@@ -258,6 +203,37 @@ func TestQLearning_evaluateCost(t *testing.T) {
 			want:    inf.NewDec(303, 3),
 			wantErr: false,
 		},
+		{
+			name: "penalize out-of-memory kills and throttling",
+			state: &state{
+				Replicas:                3,
+				CpuRequests:             inf.NewDec(100, 3),
+				MemoryRequests:          inf.NewDec(1, -6),
+				CpuUtilization:          inf.NewDec(60, 2),
+				MemoryUtilization:       inf.NewDec(80, 2),
+				CpuTargetUtilization:    inf.NewDec(60, 2),
+				MemoryTargetUtilization: inf.NewDec(80, 2),
+				OOMKills:                2,
+				ThrottlingRatio:         inf.NewDec(1, 1),
+			},
+			want:    inf.NewDec(2603, 3),
+			wantErr: false,
+		},
+		{
+			name: "penalize unschedulable pods",
+			state: &state{
+				Replicas:                3,
+				CpuRequests:             inf.NewDec(100, 3),
+				MemoryRequests:          inf.NewDec(1, -6),
+				CpuUtilization:          inf.NewDec(60, 2),
+				MemoryUtilization:       inf.NewDec(80, 2),
+				CpuTargetUtilization:    inf.NewDec(60, 2),
+				MemoryTargetUtilization: inf.NewDec(80, 2),
+				UnschedulablePods:       2,
+			},
+			want:    inf.NewDec(10303, 3),
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -310,10 +286,11 @@ func Test_quantizePercentage(t *testing.T) {
 
 func Test_convertState(t *testing.T) {
 	tests := []struct {
-		name    string
-		state   *strategy.State
-		want    *state
-		wantErr bool
+		name             string
+		state            *strategy.State
+		timeOfDayBuckets int32
+		want             *state
+		wantErr          bool
 	}{
 		{
 			name: "correctly convert strategy state to q-learning state",
@@ -358,10 +335,102 @@ func Test_convertState(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "add time of day",
+			state: &strategy.State{
+				Replicas: 4,
+				Constraints: strategy.Constraints{
+					MaxResources: strategy.ResourcesList{
+						CPU:    inf.NewDec(500, 0),
+						Memory: inf.NewDec(800, 0),
+					},
+				},
+				PodMetrics: strategy.PodMetrics{
+					ResourceUsage: strategy.ResourcesList{
+						CPU:    inf.NewDec(600, 0),
+						Memory: inf.NewDec(180, 0),
+					},
+					Resources: strategy.Resources{
+						Requests: strategy.ResourcesList{
+							CPU:    inf.NewDec(100, 0),
+							Memory: inf.NewDec(250, 0),
+						},
+						Limits: strategy.ResourcesList{
+							CPU:    inf.NewDec(500, 0),
+							Memory: inf.NewDec(700, 0),
+						},
+					},
+				},
+				TargetUtilization: strategy.ResourcesList{
+					CPU:    inf.NewDec(50, 2),
+					Memory: inf.NewDec(80, 2),
+				},
+				Time: time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+			},
+			timeOfDayBuckets: 4,
+			want: &state{
+				Name:                    "4_100_75_100_75_t2",
+				Replicas:                4,
+				CpuRequests:             inf.NewDec(100, 0),
+				MemoryRequests:          inf.NewDec(250, 0),
+				CpuUtilization:          inf.NewDec(6, 0),
+				MemoryUtilization:       inf.NewDec(72, 2),
+				CpuTargetUtilization:    inf.NewDec(50, 2),
+				MemoryTargetUtilization: inf.NewDec(80, 2),
+			},
+			wantErr: false,
+		},
+		{
+			name: "add forecast",
+			state: &strategy.State{
+				Replicas: 4,
+				Constraints: strategy.Constraints{
+					MaxResources: strategy.ResourcesList{
+						CPU:    inf.NewDec(500, 0),
+						Memory: inf.NewDec(800, 0),
+					},
+				},
+				PodMetrics: strategy.PodMetrics{
+					ResourceUsage: strategy.ResourcesList{
+						CPU:    inf.NewDec(600, 0),
+						Memory: inf.NewDec(180, 0),
+					},
+					ForecastUsage: &strategy.ResourcesList{
+						CPU:    inf.NewDec(40, 0),
+						Memory: inf.NewDec(120, 0),
+					},
+					Resources: strategy.Resources{
+						Requests: strategy.ResourcesList{
+							CPU:    inf.NewDec(100, 0),
+							Memory: inf.NewDec(250, 0),
+						},
+						Limits: strategy.ResourcesList{
+							CPU:    inf.NewDec(500, 0),
+							Memory: inf.NewDec(700, 0),
+						},
+					},
+				},
+				TargetUtilization: strategy.ResourcesList{
+					CPU:    inf.NewDec(50, 2),
+					Memory: inf.NewDec(80, 2),
+				},
+			},
+			want: &state{
+				Name:                    "4_100_75_100_75_f75_50",
+				Replicas:                4,
+				CpuRequests:             inf.NewDec(100, 0),
+				MemoryRequests:          inf.NewDec(250, 0),
+				CpuUtilization:          inf.NewDec(6, 0),
+				MemoryUtilization:       inf.NewDec(72, 2),
+				CpuTargetUtilization:    inf.NewDec(50, 2),
+				MemoryTargetUtilization: inf.NewDec(80, 2),
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := convertState(tt.state)
+			got, err := convertState(tt.state, tt.timeOfDayBuckets)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("convertState() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -372,3 +441,711 @@ func Test_convertState(t *testing.T) {
 		})
 	}
 }
+
+func Test_greedyActionsFrom(t *testing.T) {
+	table := qTable{
+		"state1": {
+			actionNone:       inf.NewDec(3, 0),
+			actionHorizontal: inf.NewDec(1, 0),
+			actionVertical:   inf.NewDec(2, 0),
+			actionHybrid:     inf.NewDec(2, 0),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		state      stateName
+		candidates actions
+		want       actions
+	}{
+		{
+			name:       "no entry for this state yet",
+			state:      "state2",
+			candidates: actions{actionNone, actionVertical},
+			want:       actions{actionNone, actionVertical},
+		},
+		{
+			name:       "cheapest among all actions",
+			state:      "state1",
+			candidates: allActions,
+			want:       actions{actionHorizontal},
+		},
+		{
+			name:       "cheapest among candidates",
+			state:      "state1",
+			candidates: actions{actionNone, actionVertical, actionHybrid},
+			want:       actions{actionVertical, actionHybrid},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := greedyActionsFrom(tt.state, tt.candidates, table)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("greedyActionsFrom() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_allowedActions(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints strategy.Constraints
+		want        actions
+	}{
+		{
+			name: "all actions allowed",
+			want: allActions,
+		},
+		{
+			name:        "replicas fixed",
+			constraints: strategy.Constraints{FixedReplicas: true},
+			want:        actions{actionNone, actionVertical},
+		},
+		{
+			name:        "resources fixed",
+			constraints: strategy.Constraints{FixedResources: true},
+			want:        actions{actionNone, actionHorizontal},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allowedActions(allActions, tt.constraints)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("allowedActions() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_timeOfDayBucket(t *testing.T) {
+	tests := []struct {
+		name    string
+		t       time.Time
+		buckets int32
+		want    int32
+	}{
+		{
+			name:    "start of day",
+			t:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			buckets: 24,
+			want:    0,
+		},
+		{
+			name:    "end of day",
+			t:       time.Date(2024, 1, 1, 23, 59, 59, 0, time.UTC),
+			buckets: 24,
+			want:    23,
+		},
+		{
+			name:    "day in the location of the time",
+			t:       time.Date(2024, 1, 1, 6, 0, 0, 0, time.FixedZone("UTC+1", 3600)),
+			buckets: 4,
+			want:    1,
+		},
+		{
+			name:    "single bucket",
+			t:       time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC),
+			buckets: 1,
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := timeOfDayBucket(tt.t, tt.buckets); got != tt.want {
+				t.Errorf("timeOfDayBucket() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_aggregatePressure(t *testing.T) {
+	tests := []struct {
+		name                string
+		pressure            map[string]strategy.Pressure
+		wantOOMKills        int32
+		wantThrottlingRatio *inf.Dec
+	}{
+		{
+			name:     "no pressure",
+			pressure: nil,
+		},
+		{
+			name: "sum kills and take highest throttling ratio",
+			pressure: map[string]strategy.Pressure{
+				"a": {OOMKills: 1, ThrottlingRatio: inf.NewDec(2, 1)},
+				"b": {OOMKills: 2, ThrottlingRatio: inf.NewDec(5, 1)},
+				"c": {},
+			},
+			wantOOMKills:        3,
+			wantThrottlingRatio: inf.NewDec(5, 1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oomKills, throttlingRatio := aggregatePressure(tt.pressure)
+			if oomKills != tt.wantOOMKills {
+				t.Errorf("aggregatePressure() oomKills = %v, want %v", oomKills, tt.wantOOMKills)
+			}
+			if diff := cmp.Diff(tt.wantThrottlingRatio, throttlingRatio, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("aggregatePressure() %v", diff)
+			}
+		})
+	}
+}
+
+func TestQLearning_updateTraces(t *testing.T) {
+	tests := []struct {
+		name        string
+		lambda      *inf.Dec
+		traceLength int32
+		traces      []trace
+		state       stateName
+		action      action
+		want        []trace
+	}{
+		{
+			name:        "first visited pair",
+			lambda:      inf.NewDec(5, 1),
+			traceLength: 3,
+			state:       "state1",
+			action:      actionNone,
+			want:        []trace{{State: "state1", Action: actionNone, Eligibility: inf.NewDec(1, 0)}},
+		},
+		{
+			name:        "earlier pairs decay",
+			lambda:      inf.NewDec(5, 1),
+			traceLength: 3,
+			traces: []trace{
+				{State: "state1", Action: actionNone, Eligibility: inf.NewDec(5, 1)},
+				{State: "state2", Action: actionVertical, Eligibility: inf.NewDec(1, 0)},
+			},
+			state:  "state3",
+			action: actionHorizontal,
+			want: []trace{
+				{State: "state1", Action: actionNone, Eligibility: inf.NewDec(225, 3)},
+				{State: "state2", Action: actionVertical, Eligibility: inf.NewDec(45, 2)},
+				{State: "state3", Action: actionHorizontal, Eligibility: inf.NewDec(1, 0)},
+			},
+		},
+		{
+			name:        "revisited pair is reset to full eligibility",
+			lambda:      inf.NewDec(5, 1),
+			traceLength: 3,
+			traces: []trace{
+				{State: "state1", Action: actionNone, Eligibility: inf.NewDec(5, 1)},
+				{State: "state2", Action: actionVertical, Eligibility: inf.NewDec(1, 0)},
+			},
+			state:  "state1",
+			action: actionNone,
+			want: []trace{
+				{State: "state2", Action: actionVertical, Eligibility: inf.NewDec(45, 2)},
+				{State: "state1", Action: actionNone, Eligibility: inf.NewDec(1, 0)},
+			},
+		},
+		{
+			name:        "oldest pairs beyond the trace length are dropped",
+			lambda:      inf.NewDec(5, 1),
+			traceLength: 2,
+			traces: []trace{
+				{State: "state1", Action: actionNone, Eligibility: inf.NewDec(5, 1)},
+				{State: "state2", Action: actionVertical, Eligibility: inf.NewDec(1, 0)},
+			},
+			state:  "state3",
+			action: actionHorizontal,
+			want: []trace{
+				{State: "state2", Action: actionVertical, Eligibility: inf.NewDec(45, 2)},
+				{State: "state3", Action: actionHorizontal, Eligibility: inf.NewDec(1, 0)},
+			},
+		},
+		{
+			name:        "without decay only the last pair is eligible",
+			traceLength: 3,
+			traces: []trace{
+				{State: "state1", Action: actionNone, Eligibility: inf.NewDec(1, 0)},
+			},
+			state:  "state2",
+			action: actionHybrid,
+			want:   []trace{{State: "state2", Action: actionHybrid, Eligibility: inf.NewDec(1, 0)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &QLearning{
+				gamma:       inf.NewDec(9, 1),
+				lambda:      tt.lambda,
+				traceLength: tt.traceLength,
+			}
+
+			got := l.updateTraces(tt.traces, tt.state, tt.action)
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("QLearning.updateTraces() %v", diff)
+			}
+		})
+	}
+}
+
+func TestQLearning_Observe(t *testing.T) {
+	l := &QLearning{
+		cpuCost:                  inf.NewDec(1, 0),
+		memoryCost:               inf.NewDec(0, 0),
+		underprovisioningPenalty: inf.NewDec(0, 0),
+		alpha:                    inf.NewDec(5, 1),
+		gamma:                    inf.NewDec(1, 0),
+		lambda:                   inf.NewDec(5, 1),
+		traceLength:              3,
+		allActions:               allActions,
+		logger:                   logr.Discard(),
+	}
+	observed := func(replicas int32, cpuRequests *inf.Dec) *state {
+		return &state{
+			Name:                    "state2",
+			Replicas:                replicas,
+			CpuRequests:             cpuRequests,
+			MemoryRequests:          inf.NewDec(10, 0),
+			CpuUtilization:          inf.NewDec(5, 1),
+			MemoryUtilization:       inf.NewDec(5, 1),
+			CpuTargetUtilization:    inf.NewDec(8, 1),
+			MemoryTargetUtilization: inf.NewDec(8, 1),
+		}
+	}
+	row := func(a action, value *inf.Dec) qTableRow {
+		r := qTableRow{actionNone: initialValue, actionHorizontal: initialValue, actionVertical: initialValue, actionHybrid: initialValue}
+		r[a] = value
+		return r
+	}
+	pending := &transition{
+		State:  &state{Name: "state1", Replicas: 1, CpuRequests: inf.NewDec(10, 0), MemoryRequests: inf.NewDec(10, 0)},
+		Action: actionHorizontal,
+		Target: target{Replicas: 2, CpuRequests: inf.NewDec(10, 0), MemoryRequests: inf.NewDec(10, 0)},
+	}
+	traces := []trace{{State: "state0", Action: actionVertical, Eligibility: inf.NewDec(1, 0)}}
+
+	tests := []struct {
+		name     string
+		ls       *learningState
+		observed *state
+		want     *learningState
+	}{
+		{
+			name:     "nothing pending",
+			ls:       &learningState{},
+			observed: observed(2, inf.NewDec(10, 0)),
+			want:     &learningState{},
+		},
+		{
+			name:     "reached target credits the action and earlier pairs",
+			ls:       &learningState{Pending: pending, Traces: traces},
+			observed: observed(2, inf.NewDec(10, 0)),
+			want: &learningState{
+				Table: qTable{
+					"state0": row(actionVertical, inf.NewDec(5, 0)),
+					"state1": row(actionHorizontal, inf.NewDec(10, 0)),
+				},
+				Traces: []trace{
+					{State: "state0", Action: actionVertical, Eligibility: inf.NewDec(5, 1)},
+					{State: "state1", Action: actionHorizontal, Eligibility: inf.NewDec(1, 0)},
+				},
+			},
+		},
+		{
+			name:     "unchanged workload credits not scaling",
+			ls:       &learningState{Pending: pending, Traces: traces},
+			observed: observed(1, inf.NewDec(102, 1)),
+			want: &learningState{
+				Table: qTable{
+					"state0": row(actionVertical, inf.NewDec(255, 2)),
+					"state1": row(actionNone, inf.NewDec(51, 1)),
+				},
+				Traces: []trace{
+					{State: "state0", Action: actionVertical, Eligibility: inf.NewDec(5, 1)},
+					{State: "state1", Action: actionNone, Eligibility: inf.NewDec(1, 0)},
+				},
+			},
+		},
+		{
+			name:     "diverged workload discards the transition",
+			ls:       &learningState{Pending: pending, Traces: traces},
+			observed: observed(3, inf.NewDec(10, 0)),
+			want:     &learningState{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := l.Observe(tt.observed, tt.ls); err != nil {
+				t.Errorf("QLearning.Observe() error = %v", err)
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, tt.ls, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("QLearning.Observe() %v", diff)
+			}
+		})
+	}
+}
+
+func TestQLearning_Decide(t *testing.T) {
+	l := &QLearning{}
+	s := &state{Name: "state1", Replicas: 1}
+	t1 := target{Replicas: 2}
+	traces := []trace{{State: "state0", Action: actionVertical, Eligibility: inf.NewDec(1, 0)}}
+
+	tests := []struct {
+		name   string
+		table  qTable
+		action action
+		want   *learningState
+	}{
+		{
+			name: "greedy action keeps the traces",
+			table: qTable{
+				"state1": {actionNone: inf.NewDec(2, 0), actionHorizontal: inf.NewDec(1, 0)},
+			},
+			action: actionHorizontal,
+			want: &learningState{
+				Table: qTable{
+					"state1": {actionNone: inf.NewDec(2, 0), actionHorizontal: inf.NewDec(1, 0)},
+				},
+				Pending: &transition{State: s, Action: actionHorizontal, Target: t1},
+				Traces:  traces,
+			},
+		},
+		{
+			name: "exploratory action cuts the traces",
+			table: qTable{
+				"state1": {actionNone: inf.NewDec(2, 0), actionHorizontal: inf.NewDec(1, 0)},
+			},
+			action: actionNone,
+			want: &learningState{
+				Table: qTable{
+					"state1": {actionNone: inf.NewDec(2, 0), actionHorizontal: inf.NewDec(1, 0)},
+				},
+				Pending: &transition{State: s, Action: actionNone, Target: t1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := &learningState{Table: tt.table, Traces: traces}
+			l.Decide(s, tt.action, t1, ls)
+
+			if diff := cmp.Diff(tt.want, ls, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("QLearning.Decide() %v", diff)
+			}
+		})
+	}
+}
+
+var episodeResources = strategy.Resources{
+	Requests: strategy.ResourcesList{CPU: inf.NewDec(200, 0), Memory: inf.NewDec(400, 0)},
+	Limits:   strategy.ResourcesList{CPU: inf.NewDec(400, 0), Memory: inf.NewDec(800, 0)},
+}
+
+// episodeState returns the state of a single container workload with a constant total load at the given step
+func episodeState(replicas int32, resources strategy.Resources, step int) *strategy.State {
+	replicasDec := inf.NewDec(int64(replicas), 0)
+
+	return &strategy.State{
+		Replicas:           replicas,
+		ContainerResources: strategy.ContainerResources{"app": resources},
+		Constraints: strategy.Constraints{
+			MinReplicas:                 1,
+			MaxReplicas:                 10,
+			MinResources:                strategy.ResourcesList{CPU: inf.NewDec(50, 0), Memory: inf.NewDec(100, 0)},
+			MaxResources:                strategy.ResourcesList{CPU: inf.NewDec(2000, 0), Memory: inf.NewDec(4000, 0)},
+			LimitsToRequestsRatioCPU:    inf.NewDec(2, 0),
+			LimitsToRequestsRatioMemory: inf.NewDec(2, 0),
+		},
+		PodMetrics: strategy.PodMetrics{
+			ResourceUsage: strategy.ResourcesList{
+				CPU:    new(inf.Dec).QuoRound(inf.NewDec(1200, 0), replicasDec, 3, inf.RoundHalfUp),
+				Memory: new(inf.Dec).QuoRound(inf.NewDec(1200, 0), replicasDec, 3, inf.RoundHalfUp),
+			},
+			Resources: resources,
+		},
+		TargetUtilization: strategy.ResourcesList{CPU: inf.NewDec(80, 2), Memory: inf.NewDec(80, 2)},
+		Time:              time.Date(2024, 1, 1, 0, step, 0, 0, time.UTC),
+	}
+}
+
+// runEpisode lets the agent scale the episode's workload for the given number of steps, applying each decision
+func runEpisode(agent *qAgent, steps int) ([]strategy.ScalingDecision, *learningState, error) {
+	replicas := int32(2)
+	resources := episodeResources
+
+	decisions := make([]strategy.ScalingDecision, 0, steps)
+	var encoded []byte
+
+	for i := 0; i < steps; i++ {
+		decision, learningState, err := agent.MakeDecision(episodeState(replicas, resources, i), encoded)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		decisions = append(decisions, *decision)
+		encoded = learningState
+		replicas = decision.Replicas
+		resources = decision.ContainerResources["app"]
+	}
+
+	learningState, err := decodeToLearningState(encoded)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return decisions, learningState, nil
+}
+
+func newEpisodeAgent(epsilon *inf.Dec, seed int64) *qAgent {
+	return NewQAgent(
+		inf.NewDec(1, 3), inf.NewDec(1, 3), inf.NewDec(10, 0), inf.NewDec(10, 0), inf.NewDec(10, 0), inf.NewDec(10, 0),
+		inf.NewDec(5, 1), inf.NewDec(9, 1), epsilon, nil, 1, 0,
+	).WithRandomSource(NewSeededRandomSource(seed))
+}
+
+func TestQAgent_MakeDecision_reproducibleEpisodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		epsilon *inf.Dec
+		seed    int64
+		steps   int
+	}{
+		{
+			name:    "mostly greedy agent",
+			epsilon: inf.NewDec(1, 1),
+			seed:    1,
+			steps:   30,
+		},
+		{
+			name:    "exploring agent",
+			epsilon: inf.NewDec(5, 1),
+			seed:    42,
+			steps:   50,
+		},
+		{
+			name:    "randomly acting agent",
+			epsilon: inf.NewDec(1, 0),
+			seed:    7,
+			steps:   50,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			firstDecisions, firstLearningState, err := runEpisode(newEpisodeAgent(tt.epsilon, tt.seed), tt.steps)
+			if err != nil {
+				t.Errorf("qAgent.MakeDecision() error = %v", err)
+				return
+			}
+
+			secondDecisions, secondLearningState, err := runEpisode(newEpisodeAgent(tt.epsilon, tt.seed), tt.steps)
+			if err != nil {
+				t.Errorf("qAgent.MakeDecision() error = %v", err)
+				return
+			}
+
+			if diff := cmp.Diff(firstDecisions, secondDecisions, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("qAgent.MakeDecision() decisions %v", diff)
+			}
+
+			if diff := cmp.Diff(firstLearningState, secondLearningState, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("qAgent.MakeDecision() learning state %v", diff)
+			}
+		})
+	}
+}
+
+func TestQAgent_MakeDecision_episode(t *testing.T) {
+	decisions, _, err := runEpisode(newEpisodeAgent(inf.NewDec(3, 1), 1), 30)
+	if err != nil {
+		t.Errorf("qAgent.MakeDecision() error = %v", err)
+		return
+	}
+
+	want := []string{
+		"HYBRID", "HYBRID", "VERTICAL", "NONE", "HORIZONAL", "HYBRID", "HORIZONAL", "NONE", "HORIZONAL", "VERTICAL",
+		"NONE", "VERTICAL", "HYBRID", "NONE", "HORIZONAL", "VERTICAL", "HYBRID", "HORIZONAL", "NONE", "VERTICAL",
+		"HYBRID", "HYBRID", "HORIZONAL", "NONE", "HORIZONAL", "VERTICAL", "HYBRID", "HORIZONAL", "NONE", "VERTICAL",
+	}
+	got := make([]string, 0, len(decisions))
+	for _, d := range decisions {
+		got = append(got, d.Description)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("qAgent.MakeDecision() descriptions %v", diff)
+	}
+}
+
+func TestQAgent_MakeDecision_cycle(t *testing.T) {
+	tests := []struct {
+		name string
+		// next returns the state observed after the first decision
+		next func(decision *strategy.ScalingDecision) *strategy.State
+		// credited returns the action credited with the observed cost, empty if the transition is discarded
+		credited func(decided action) action
+	}{
+		{
+			name: "applied decision",
+			next: func(decision *strategy.ScalingDecision) *strategy.State {
+				return episodeState(decision.Replicas, decision.ContainerResources["app"], 1)
+			},
+			credited: func(decided action) action { return decided },
+		},
+		{
+			name: "decision which was not applied",
+			next: func(decision *strategy.ScalingDecision) *strategy.State {
+				return episodeState(2, episodeResources, 1)
+			},
+			credited: func(decided action) action { return actionNone },
+		},
+		{
+			name: "workload changed by someone else",
+			next: func(decision *strategy.ScalingDecision) *strategy.State {
+				return episodeState(7, episodeResources, 1)
+			},
+			credited: func(decided action) action { return "" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := newEpisodeAgent(inf.NewDec(3, 1), 1)
+
+			// observe the first state and decide
+			first := episodeState(2, episodeResources, 0)
+			decision, encoded, err := agent.MakeDecision(first, nil)
+			if err != nil {
+				t.Errorf("qAgent.MakeDecision() error = %v", err)
+				return
+			}
+
+			ls, err := decodeToLearningState(encoded)
+			if err != nil {
+				t.Errorf("qAgent.MakeDecision() learning state decoding error = %v", err)
+				return
+			}
+
+			firstState, err := convertState(first, 0)
+			if err != nil {
+				t.Errorf("convertState() error = %v", err)
+				return
+			}
+
+			decided := action(decision.Description)
+			wantPending := &transition{State: firstState, Action: decided, Target: decisionTarget(decision)}
+			if diff := cmp.Diff(wantPending, ls.Pending, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("qAgent.MakeDecision() pending transition %v", diff)
+			}
+
+			if len(ls.Table) > 0 {
+				t.Errorf("qAgent.MakeDecision() table = %v, want no values before the outcome is observed", ls.Table)
+			}
+
+			// apply the decision or not and observe the outcome
+			next := tt.next(decision)
+			_, encoded, err = agent.MakeDecision(next, encoded)
+			if err != nil {
+				t.Errorf("qAgent.MakeDecision() error = %v", err)
+				return
+			}
+
+			ls, err = decodeToLearningState(encoded)
+			if err != nil {
+				t.Errorf("qAgent.MakeDecision() learning state decoding error = %v", err)
+				return
+			}
+
+			want := qTable{}
+			if credited := tt.credited(decided); credited != "" {
+				nextState, err := convertState(next, 0)
+				if err != nil {
+					t.Errorf("convertState() error = %v", err)
+					return
+				}
+
+				cost, err := agent.evaluateCost(nextState)
+				if err != nil {
+					t.Errorf("QLearning.evaluateCost() error = %v", err)
+					return
+				}
+
+				row := qTableRow{actionNone: initialValue, actionHorizontal: initialValue, actionVertical: initialValue, actionHybrid: initialValue}
+				row[credited] = new(inf.Dec).Round(new(inf.Dec).Mul(agent.alpha, cost), 4, inf.RoundHalfUp)
+				want[firstState.Name] = row
+			}
+
+			if diff := cmp.Diff(want, ls.Table, cmp.Comparer(decComparer), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("qAgent.MakeDecision() table %v", diff)
+			}
+		})
+	}
+}
+
+func TestSplitAndJoinLearningState(t *testing.T) {
+	ls := &learningState{
+		Table: qTable{
+			"state1": {actionNone: inf.NewDec(1, 0), actionHorizontal: inf.NewDec(2, 0)},
+		},
+		Pending: &transition{
+			State:  &state{Name: "state1", Replicas: 1},
+			Action: actionHorizontal,
+			Target: target{Replicas: 2, CpuRequests: inf.NewDec(1, 0), MemoryRequests: inf.NewDec(1, 0)},
+		},
+		Traces: []trace{{State: "state0", Action: actionNone, Eligibility: inf.NewDec(1, 0)}},
+	}
+	encoded, err := encodeLearningState(ls)
+	if err != nil {
+		t.Errorf("learning state encoding error = %v", err)
+		return
+	}
+
+	table, own, err := SplitLearningState(encoded)
+	if err != nil {
+		t.Errorf("SplitLearningState() error = %v", err)
+		return
+	}
+
+	decodedTable, err := decodeToLearningState(table)
+	if err != nil {
+		t.Errorf("SplitLearningState() table decoding error = %v", err)
+		return
+	}
+	if diff := cmp.Diff(&learningState{Table: ls.Table}, decodedTable, cmp.Comparer(decComparer)); diff != "" {
+		t.Errorf("SplitLearningState() table %v", diff)
+	}
+
+	decodedOwn, err := decodeToLearningState(own)
+	if err != nil {
+		t.Errorf("SplitLearningState() own learning state decoding error = %v", err)
+		return
+	}
+	if diff := cmp.Diff(&learningState{Pending: ls.Pending, Traces: ls.Traces}, decodedOwn, cmp.Comparer(decComparer)); diff != "" {
+		t.Errorf("SplitLearningState() own learning state %v", diff)
+	}
+
+	sharedTable, err := encodeLearningState(&learningState{Table: qTable{"state2": {actionVertical: inf.NewDec(3, 0)}}})
+	if err != nil {
+		t.Errorf("learning state encoding error = %v", err)
+		return
+	}
+
+	joined, err := JoinLearningState(sharedTable, encoded)
+	if err != nil {
+		t.Errorf("JoinLearningState() error = %v", err)
+		return
+	}
+
+	decodedJoined, err := decodeToLearningState(joined)
+	if err != nil {
+		t.Errorf("JoinLearningState() decoding error = %v", err)
+		return
+	}
+
+	want := &learningState{
+		Table:   qTable{"state2": {actionVertical: inf.NewDec(3, 0)}},
+		Pending: ls.Pending,
+		Traces:  ls.Traces,
+	}
+	if diff := cmp.Diff(want, decodedJoined, cmp.Comparer(decComparer)); diff != "" {
+		t.Errorf("JoinLearningState() %v", diff)
+	}
+}
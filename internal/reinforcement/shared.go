@@ -0,0 +1,46 @@
+package reinforcement
+
+import "fmt"
+
+// SplitLearningState separates the table, which agents of similar workloads can share, from the agent's own pending decision
+// and traces, both are encoded as learning states themselves
+func SplitLearningState(encoded []byte) (table, own []byte, err error) {
+	ls, err := decodeToLearningState(encoded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot decode learning state, %w", err)
+	}
+
+	table, err = encodeLearningState(&learningState{Table: ls.Table})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot encode shared table, %w", err)
+	}
+
+	own, err = encodeLearningState(&learningState{Pending: ls.Pending, Traces: ls.Traces})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot encode own learning state, %w", err)
+	}
+
+	return table, own, nil
+}
+
+// JoinLearningState combines a shared table with an agent's own learning state, whose table is replaced by the shared one
+func JoinLearningState(table, own []byte) ([]byte, error) {
+	shared, err := decodeToLearningState(table)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode shared table, %w", err)
+	}
+
+	ls, err := decodeToLearningState(own)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode own learning state, %w", err)
+	}
+
+	ls.Table = shared.Table
+
+	encoded, err := encodeLearningState(ls)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode learning state, %w", err)
+	}
+
+	return encoded, nil
+}
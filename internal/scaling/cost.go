@@ -0,0 +1,68 @@
+package scaling
+
+import (
+	"fmt"
+
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+	"gopkg.in/inf.v0"
+)
+
+// EstimateCost estimates the cost of a decision like the q-learning cost of a state,
+// the current total usage is spread across the decision's replicas and requests below the target utilization are penalized
+func EstimateCost(s *strategy.State, decision *strategy.ScalingDecision, cpuCost, memoryCost, underprovisioningPenalty *inf.Dec) (*inf.Dec, error) {
+	zero := inf.NewDec(0, 0)
+	if decision.Replicas == 0 {
+		return zero, nil
+	}
+
+	requests := strategy.ResourcesList{CPU: inf.NewDec(0, 0), Memory: inf.NewDec(0, 0)}
+	for _, resources := range decision.ContainerResources {
+		if resources.Requests.CPU != nil {
+			requests.CPU.Add(requests.CPU, resources.Requests.CPU)
+		}
+		if resources.Requests.Memory != nil {
+			requests.Memory.Add(requests.Memory, resources.Requests.Memory)
+		}
+	}
+
+	usage := strategy.ResourcesList{CPU: zero, Memory: zero}
+	if s.Replicas > 0 {
+		expected := ExpectedUsage(s.PodMetrics)
+		replicasRatio := new(inf.Dec).QuoRound(inf.NewDec(int64(s.Replicas), 0), inf.NewDec(int64(decision.Replicas), 0), 8, inf.RoundHalfUp)
+		usage.CPU = new(inf.Dec).Mul(expected.CPU, replicasRatio)
+		usage.Memory = new(inf.Dec).Mul(expected.Memory, replicasRatio)
+	}
+
+	cpuPenalty, err := underprovisioningCost(usage.CPU, requests.CPU, s.TargetUtilization.CPU, cpuCost, underprovisioningPenalty)
+	if err != nil {
+		return nil, fmt.Errorf("unable to estimate cpu underprovisioning, %w", err)
+	}
+
+	memoryPenalty, err := underprovisioningCost(usage.Memory, requests.Memory, s.TargetUtilization.Memory, memoryCost, underprovisioningPenalty)
+	if err != nil {
+		return nil, fmt.Errorf("unable to estimate memory underprovisioning, %w", err)
+	}
+
+	podCost := new(inf.Dec).Mul(cpuCost, requests.CPU)
+	podCost.Add(podCost, new(inf.Dec).Mul(memoryCost, requests.Memory))
+	podCost.Add(podCost, cpuPenalty)
+	podCost.Add(podCost, memoryPenalty)
+
+	totalCost := new(inf.Dec).Mul(podCost, inf.NewDec(int64(decision.Replicas), 0))
+	return totalCost.Round(totalCost, 4, inf.RoundHalfUp), nil
+}
+
+// underprovisioningCost penalizes the requests missing to keep the usage at the target utilization
+func underprovisioningCost(usage, requests, targetUtilization, cost, penalty *inf.Dec) (*inf.Dec, error) {
+	if targetUtilization == nil || targetUtilization.Sign() == 0 {
+		return nil, fmt.Errorf("target utilization cannot be zero")
+	}
+
+	targetRequests := new(inf.Dec).QuoRound(usage, targetUtilization, 8, inf.RoundHalfUp)
+	if targetRequests.Cmp(requests) <= 0 {
+		return inf.NewDec(0, 0), nil
+	}
+
+	difference := new(inf.Dec).Sub(targetRequests, requests)
+	return difference.Mul(difference, new(inf.Dec).Mul(cost, penalty)), nil
+}
@@ -30,18 +30,20 @@ func Horizontal(s *strategy.State) (*strategy.ScalingDecision, error) {
 	}, nil
 }
 
+// calculateDesiredReplicas returns zero if the workload is scaled to zero, since there is no usage to base a recommendation on
 func calculateDesiredReplicas(s *strategy.State) (*inf.Dec, error) {
 	currentReplicas := inf.NewDec(int64(s.Replicas), 0)
 	zero := inf.NewDec(0, 0)
 
 	if currentReplicas.Cmp(zero) == 0 {
-		return nil, fmt.Errorf("cannot calculate new number of replicas, current replicas is zero")
+		return zero, nil
 	}
 
 	cpuRequests := s.PodMetrics.Requests.CPU
 	memoryRequests := s.PodMetrics.Requests.Memory
+	usage := ExpectedUsage(s.PodMetrics)
 
-	cpuCurrentToTargetRatio, err := currentToTargetUtilizationRatio(s.PodMetrics.ResourceUsage.CPU, cpuRequests, s.TargetUtilization.CPU)
+	cpuCurrentToTargetRatio, err := currentToTargetUtilizationRatio(usage.CPU, cpuRequests, s.TargetUtilization.CPU)
 	if err != nil {
 		return nil, fmt.Errorf("unable to calculate cpu current to target utilization ratio, %w", err)
 	}
@@ -49,7 +51,7 @@ func calculateDesiredReplicas(s *strategy.State) (*inf.Dec, error) {
 	desiredReplicasCpu := new(inf.Dec).Mul(currentReplicas, cpuCurrentToTargetRatio)
 	desiredReplicasCpu.Round(desiredReplicasCpu, 0, inf.RoundCeil)
 
-	memoryCurrentToTargetRatio, err := currentToTargetUtilizationRatio(s.PodMetrics.ResourceUsage.Memory, memoryRequests, s.TargetUtilization.Memory)
+	memoryCurrentToTargetRatio, err := currentToTargetUtilizationRatio(usage.Memory, memoryRequests, s.TargetUtilization.Memory)
 	if err != nil {
 		return nil, fmt.Errorf("unable to calculate memory current to target utilization ratio, %w", err)
 	}
@@ -1,8 +1,6 @@
 package scaling
 
 import (
-	"fmt"
-
 	"github.com/iljarotar/hybrid-scaler/internal/strategy"
 	"gopkg.in/inf.v0"
 )
@@ -28,17 +26,56 @@ func Hybrid(s *strategy.State, cpuLimitsToRequestsRatio, memoryLimitsToRequestsR
 	hypotheticalState := *s
 	hypotheticalState.Replicas = int32(replicas)
 
+	// resources can neither be derived from nor applied to zero replicas, so only the replicas change
 	zero := inf.NewDec(0, 0)
-	if limitedReplicas.Cmp(zero) == 0 {
-		return nil, fmt.Errorf("attempting to scale to zero replicas, please provide min and max values for replicas to prevent this")
+	if currentReplicas.Cmp(zero) == 0 || limitedReplicas.Cmp(zero) == 0 {
+		return &strategy.ScalingDecision{
+			Replicas:           int32(replicas),
+			ContainerResources: s.ContainerResources,
+		}, nil
 	}
 
+	usage := ExpectedUsage(s.PodMetrics)
 	replicasRatio := new(inf.Dec).QuoRound(currentReplicas, limitedReplicas, 8, inf.RoundHalfUp)
-	podCpuUsage := new(inf.Dec).Mul(s.PodMetrics.ResourceUsage.CPU, replicasRatio)
-	podMemoryUsage := new(inf.Dec).Mul(s.PodMetrics.ResourceUsage.Memory, replicasRatio)
+	podCpuUsage := new(inf.Dec).Mul(usage.CPU, replicasRatio)
+	podMemoryUsage := new(inf.Dec).Mul(usage.Memory, replicasRatio)
 
 	hypotheticalState.PodMetrics.ResourceUsage.CPU = podCpuUsage
 	hypotheticalState.PodMetrics.ResourceUsage.Memory = podMemoryUsage
+	hypotheticalState.PodMetrics.ForecastUsage = nil
+	hypotheticalState.UsageRecommendations = scaleRecommendations(s.UsageRecommendations, replicasRatio)
 
 	return Vertical(&hypotheticalState, cpuLimitsToRequestsRatio, memoryLimitsToRequestsRatio)
 }
+
+// scaleRecommendations spreads the recommended container usage over the new number of replicas
+func scaleRecommendations(recommendations map[string]strategy.UsageRecommendation, replicasRatio *inf.Dec) map[string]strategy.UsageRecommendation {
+	if recommendations == nil {
+		return nil
+	}
+
+	scaled := make(map[string]strategy.UsageRecommendation, len(recommendations))
+	for name, recommendation := range recommendations {
+		scaled[name] = strategy.UsageRecommendation{
+			Target:     scaleResourcesList(recommendation.Target, replicasRatio),
+			LowerBound: scaleResourcesList(recommendation.LowerBound, replicasRatio),
+			UpperBound: scaleResourcesList(recommendation.UpperBound, replicasRatio),
+		}
+	}
+
+	return scaled
+}
+
+func scaleResourcesList(list strategy.ResourcesList, factor *inf.Dec) strategy.ResourcesList {
+	var scaled strategy.ResourcesList
+
+	if list.CPU != nil {
+		scaled.CPU = new(inf.Dec).Mul(list.CPU, factor)
+	}
+
+	if list.Memory != nil {
+		scaled.Memory = new(inf.Dec).Mul(list.Memory, factor)
+	}
+
+	return scaled
+}
@@ -0,0 +1,92 @@
+package scaling
+
+import (
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+	"gopkg.in/inf.v0"
+)
+
+// FitToNode scales the decision's resources down until the pod's requests fit on at least one of the node capacities
+// and raises the replicas to make up for the lost capacity, it reports whether the decision was changed
+func FitToNode(decision *strategy.ScalingDecision, s *strategy.State) bool {
+	if len(s.NodeCapacities) == 0 || decision.Replicas == 0 {
+		return false
+	}
+
+	podRequests := strategy.ResourcesList{CPU: inf.NewDec(0, 0), Memory: inf.NewDec(0, 0)}
+	for _, resources := range decision.ContainerResources {
+		if resources.Requests.CPU != nil {
+			podRequests.CPU.Add(podRequests.CPU, resources.Requests.CPU)
+		}
+		if resources.Requests.Memory != nil {
+			podRequests.Memory.Add(podRequests.Memory, resources.Requests.Memory)
+		}
+	}
+
+	var cpuFactor, memoryFactor, factor *inf.Dec
+	for _, capacity := range s.NodeCapacities {
+		nodeCpuFactor := fitFactor(podRequests.CPU, capacity.CPU)
+		nodeMemoryFactor := fitFactor(podRequests.Memory, capacity.Memory)
+		nodeFactor := minDec(nodeCpuFactor, nodeMemoryFactor)
+
+		if factor == nil || nodeFactor.Cmp(factor) > 0 {
+			cpuFactor, memoryFactor, factor = nodeCpuFactor, nodeMemoryFactor, nodeFactor
+		}
+	}
+
+	one := inf.NewDec(1, 0)
+	if factor.Cmp(one) >= 0 || factor.Sign() <= 0 {
+		return false
+	}
+
+	containerResources := make(strategy.ContainerResources)
+	for name, resources := range decision.ContainerResources {
+		containerResources[name] = strategy.Resources{
+			Requests: strategy.ResourcesList{
+				CPU:    scaleDown(resources.Requests.CPU, cpuFactor, 3),
+				Memory: scaleDown(resources.Requests.Memory, memoryFactor, 0),
+			},
+			Limits: strategy.ResourcesList{
+				CPU:    scaleDown(resources.Limits.CPU, cpuFactor, 3),
+				Memory: scaleDown(resources.Limits.Memory, memoryFactor, 0),
+			},
+		}
+	}
+	decision.ContainerResources = containerResources
+
+	if !s.FixedReplicas {
+		replicas := new(inf.Dec).QuoRound(inf.NewDec(int64(decision.Replicas), 0), factor, 0, inf.RoundCeil)
+		if maxReplicas := inf.NewDec(int64(s.MaxReplicas), 0); s.MaxReplicas > 0 && replicas.Cmp(maxReplicas) > 0 {
+			replicas = maxReplicas
+		}
+		decision.Replicas = int32(DecToInt64(replicas))
+	}
+
+	return true
+}
+
+// fitFactor returns the factor by which the requests must be scaled to fit the capacity, but at most one
+func fitFactor(requests, capacity *inf.Dec) *inf.Dec {
+	one := inf.NewDec(1, 0)
+	if capacity == nil || requests.Cmp(capacity) <= 0 {
+		return one
+	}
+
+	return new(inf.Dec).QuoRound(capacity, requests, 8, inf.RoundDown)
+}
+
+func scaleDown(value, factor *inf.Dec, scale inf.Scale) *inf.Dec {
+	if value == nil {
+		return nil
+	}
+
+	scaled := new(inf.Dec).Mul(value, factor)
+	return scaled.Round(scaled, scale, inf.RoundDown)
+}
+
+func minDec(a, b *inf.Dec) *inf.Dec {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+
+	return b
+}
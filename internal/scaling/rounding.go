@@ -0,0 +1,56 @@
+package scaling
+
+import (
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
+	"gopkg.in/inf.v0"
+)
+
+var (
+	defaultCpuGranularity    = inf.NewDec(1, 3)
+	defaultMemoryGranularity = inf.NewDec(1, 0)
+)
+
+// RoundResources rounds the requests and limits according to the rounding policies
+func RoundResources(resources strategy.Resources, rounding strategy.RoundingPolicies) strategy.Resources {
+	return strategy.Resources{
+		Requests: strategy.ResourcesList{
+			CPU:    round(resources.Requests.CPU, rounding.CPU, defaultCpuGranularity),
+			Memory: round(resources.Requests.Memory, rounding.Memory, defaultMemoryGranularity),
+		},
+		Limits: strategy.ResourcesList{
+			CPU:    round(resources.Limits.CPU, rounding.CPU, defaultCpuGranularity),
+			Memory: round(resources.Limits.Memory, rounding.Memory, defaultMemoryGranularity),
+		},
+	}
+}
+
+// round rounds the value to a multiple of the rounding's granularity, or of `defaultGranularity` if none is set,
+// positive values are never rounded down to zero, since they would remove the resource from the container
+func round(value *inf.Dec, rounding strategy.Rounding, defaultGranularity *inf.Dec) *inf.Dec {
+	if value == nil {
+		return nil
+	}
+
+	granularity := rounding.Granularity
+	if granularity == nil || granularity.Sign() <= 0 {
+		granularity = defaultGranularity
+	}
+
+	steps := new(inf.Dec).QuoRound(value, granularity, 0, rounder(rounding.Direction))
+	if steps.Sign() == 0 && value.Sign() > 0 {
+		steps = inf.NewDec(1, 0)
+	}
+
+	return steps.Mul(steps, granularity)
+}
+
+func rounder(direction strategy.RoundingDirection) inf.Rounder {
+	switch direction {
+	case strategy.RoundingDirectionUp:
+		return inf.RoundCeil
+	case strategy.RoundingDirectionDown:
+		return inf.RoundFloor
+	default:
+		return inf.RoundHalfUp
+	}
+}
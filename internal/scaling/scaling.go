@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/iljarotar/hybrid-scaler/internal/strategy"
 	"gopkg.in/inf.v0"
 )
 
@@ -26,6 +27,27 @@ func currentToTargetUtilizationRatio(usage, requests, targetUtilization *inf.Dec
 	return ratio, nil
 }
 
+// UtilizationRatio returns the higher of the cpu and memory ratios of the expected to the target utilization
+func UtilizationRatio(s *strategy.State) (*inf.Dec, error) {
+	usage := ExpectedUsage(s.PodMetrics)
+
+	cpuRatio, err := currentToTargetUtilizationRatio(usage.CPU, s.PodMetrics.Requests.CPU, s.TargetUtilization.CPU)
+	if err != nil {
+		return nil, fmt.Errorf("unable to calculate cpu current to target utilization ratio, %w", err)
+	}
+
+	memoryRatio, err := currentToTargetUtilizationRatio(usage.Memory, s.PodMetrics.Requests.Memory, s.TargetUtilization.Memory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to calculate memory current to target utilization ratio, %w", err)
+	}
+
+	if cpuRatio.Cmp(memoryRatio) < 0 {
+		return memoryRatio, nil
+	}
+
+	return cpuRatio, nil
+}
+
 // limits value to the range [min, max]
 func limitValue(value, min, max *inf.Dec) *inf.Dec {
 	if value.Cmp(min) < 0 {
@@ -47,3 +69,22 @@ func DecToInt64(value *inf.Dec) int64 {
 
 	return int64(floatValue)
 }
+
+// ExpectedUsage returns the higher of the current and the forecast usage for each resource,
+// so that scaling anticipates rising demand without giving up capacity ahead of a predicted drop
+func ExpectedUsage(m strategy.PodMetrics) strategy.ResourcesList {
+	usage := m.ResourceUsage
+	if m.ForecastUsage == nil {
+		return usage
+	}
+
+	if m.ForecastUsage.CPU != nil && m.ForecastUsage.CPU.Cmp(usage.CPU) > 0 {
+		usage.CPU = m.ForecastUsage.CPU
+	}
+
+	if m.ForecastUsage.Memory != nil && m.ForecastUsage.Memory.Cmp(usage.Memory) > 0 {
+		usage.Memory = m.ForecastUsage.Memory
+	}
+
+	return usage
+}
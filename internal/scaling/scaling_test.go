@@ -289,6 +289,131 @@ func TestHybrid(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "stay at zero replicas",
+			state: &strategy.State{
+				Replicas: 0,
+				ContainerResources: strategy.ContainerResources{
+					"container": {
+						Requests: strategy.ResourcesList{
+							CPU:    inf.NewDec(100, 0),
+							Memory: inf.NewDec(100, 0),
+						},
+						Limits: strategy.ResourcesList{
+							CPU:    inf.NewDec(200, 0),
+							Memory: inf.NewDec(200, 0),
+						},
+					},
+				},
+				Constraints: strategy.Constraints{
+					MinReplicas: 0,
+					MaxReplicas: 10,
+				},
+			},
+			cpuLimitsToRequestsRatio:    inf.NewDec(2, 0),
+			memoryLimitsToRequestsRatio: inf.NewDec(2, 0),
+			want: &strategy.ScalingDecision{
+				Replicas: 0,
+				ContainerResources: strategy.ContainerResources{
+					"container": {
+						Requests: strategy.ResourcesList{
+							CPU:    inf.NewDec(100, 0),
+							Memory: inf.NewDec(100, 0),
+						},
+						Limits: strategy.ResourcesList{
+							CPU:    inf.NewDec(200, 0),
+							Memory: inf.NewDec(200, 0),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "scale from zero to min replicas keeping resources",
+			state: &strategy.State{
+				Replicas: 0,
+				ContainerResources: strategy.ContainerResources{
+					"container": {
+						Requests: strategy.ResourcesList{
+							CPU:    inf.NewDec(100, 0),
+							Memory: inf.NewDec(100, 0),
+						},
+						Limits: strategy.ResourcesList{
+							CPU:    inf.NewDec(200, 0),
+							Memory: inf.NewDec(200, 0),
+						},
+					},
+				},
+				Constraints: strategy.Constraints{
+					MinReplicas: 2,
+					MaxReplicas: 10,
+				},
+			},
+			cpuLimitsToRequestsRatio:    inf.NewDec(2, 0),
+			memoryLimitsToRequestsRatio: inf.NewDec(2, 0),
+			want: &strategy.ScalingDecision{
+				Replicas: 2,
+				ContainerResources: strategy.ContainerResources{
+					"container": {
+						Requests: strategy.ResourcesList{
+							CPU:    inf.NewDec(100, 0),
+							Memory: inf.NewDec(100, 0),
+						},
+						Limits: strategy.ResourcesList{
+							CPU:    inf.NewDec(200, 0),
+							Memory: inf.NewDec(200, 0),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "recommendations are spread over the new replicas",
+			state: &strategy.State{
+				Replicas: 1,
+				ContainerResources: strategy.ContainerResources{
+					"container": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(100, 0), Memory: inf.NewDec(100, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(200, 0), Memory: inf.NewDec(200, 0)},
+					},
+				},
+				Constraints: strategy.Constraints{
+					MinReplicas:  1,
+					MaxReplicas:  10,
+					MinResources: strategy.ResourcesList{CPU: inf.NewDec(50, 0), Memory: inf.NewDec(50, 0)},
+					MaxResources: strategy.ResourcesList{CPU: inf.NewDec(500, 0), Memory: inf.NewDec(500, 0)},
+				},
+				PodMetrics: strategy.PodMetrics{
+					ResourceUsage: strategy.ResourcesList{CPU: inf.NewDec(150, 0), Memory: inf.NewDec(150, 0)},
+					Resources: strategy.Resources{
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(100, 0), Memory: inf.NewDec(100, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(200, 0), Memory: inf.NewDec(200, 0)},
+					},
+				},
+				TargetUtilization: strategy.ResourcesList{CPU: inf.NewDec(50, 2), Memory: inf.NewDec(50, 2)},
+				UsageRecommendations: map[string]strategy.UsageRecommendation{
+					"container": {
+						Target:     strategy.ResourcesList{CPU: inf.NewDec(200, 0), Memory: inf.NewDec(200, 0)},
+						LowerBound: strategy.ResourcesList{CPU: inf.NewDec(180, 0), Memory: inf.NewDec(180, 0)},
+						UpperBound: strategy.ResourcesList{CPU: inf.NewDec(220, 0), Memory: inf.NewDec(220, 0)},
+					},
+				},
+			},
+			cpuLimitsToRequestsRatio:    inf.NewDec(2, 0),
+			memoryLimitsToRequestsRatio: inf.NewDec(2, 0),
+			want: &strategy.ScalingDecision{
+				Replicas: 2,
+				ContainerResources: strategy.ContainerResources{
+					"container": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(200, 0), Memory: inf.NewDec(200, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(400, 0), Memory: inf.NewDec(400, 0)},
+					},
+				},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -316,11 +441,37 @@ func TestVertical(t *testing.T) {
 			name: "replicas 0",
 			state: &strategy.State{
 				Replicas: 0,
+				ContainerResources: strategy.ContainerResources{
+					"container": {
+						Requests: strategy.ResourcesList{
+							CPU:    inf.NewDec(100, 0),
+							Memory: inf.NewDec(100, 0),
+						},
+						Limits: strategy.ResourcesList{
+							CPU:    inf.NewDec(200, 0),
+							Memory: inf.NewDec(200, 0),
+						},
+					},
+				},
 			},
 			cpuLimitsToRequestsRatio:    inf.NewDec(2, 0),
 			memoryLimitsToRequestsRatio: inf.NewDec(2, 0),
-			want:                        nil,
-			wantErr:                     true,
+			want: &strategy.ScalingDecision{
+				Replicas: 0,
+				ContainerResources: strategy.ContainerResources{
+					"container": {
+						Requests: strategy.ResourcesList{
+							CPU:    inf.NewDec(100, 0),
+							Memory: inf.NewDec(100, 0),
+						},
+						Limits: strategy.ResourcesList{
+							CPU:    inf.NewDec(200, 0),
+							Memory: inf.NewDec(200, 0),
+						},
+					},
+				},
+			},
+			wantErr: false,
 		},
 		{
 			name:                        "no scaling",
@@ -388,6 +539,48 @@ func TestVertical(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:                        "apply headroom and rounding",
+			cpuLimitsToRequestsRatio:    inf.NewDec(2, 0),
+			memoryLimitsToRequestsRatio: inf.NewDec(2, 0),
+			state: &strategy.State{
+				Replicas: 1,
+				ContainerResources: strategy.ContainerResources{
+					"container": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(110, 0), Memory: inf.NewDec(110, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(220, 0), Memory: inf.NewDec(220, 0)},
+					},
+				},
+				Constraints: strategy.Constraints{
+					MinResources: strategy.ResourcesList{CPU: inf.NewDec(50, 0), Memory: inf.NewDec(50, 0)},
+					MaxResources: strategy.ResourcesList{CPU: inf.NewDec(250, 0), Memory: inf.NewDec(250, 0)},
+				},
+				PodMetrics: strategy.PodMetrics{
+					ResourceUsage: strategy.ResourcesList{CPU: inf.NewDec(50, 0), Memory: inf.NewDec(50, 0)},
+					Resources: strategy.Resources{
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(110, 0), Memory: inf.NewDec(110, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(220, 0), Memory: inf.NewDec(220, 0)},
+					},
+				},
+				TargetUtilization: strategy.ResourcesList{CPU: inf.NewDec(50, 2), Memory: inf.NewDec(50, 2)},
+				VerticalPolicy: strategy.VerticalPolicy{
+					Headroom: strategy.ResourcesList{CPU: inf.NewDec(12, 1), Memory: inf.NewDec(1, 0)},
+					Rounding: strategy.RoundingPolicies{
+						CPU: strategy.Rounding{Granularity: inf.NewDec(50, 0), Direction: strategy.RoundingDirectionNearest},
+					},
+				},
+			},
+			want: &strategy.ScalingDecision{
+				Replicas: 1,
+				ContainerResources: strategy.ContainerResources{
+					"container": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(100, 0), Memory: inf.NewDec(100, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(250, 0), Memory: inf.NewDec(200, 0)},
+					},
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name:                        "scale both up",
 			cpuLimitsToRequestsRatio:    inf.NewDec(2, 0),
@@ -863,8 +1056,8 @@ func Test_calculateDesiredReplicas(t *testing.T) {
 		{
 			name:    "replicas 0",
 			state:   &strategy.State{},
-			want:    nil,
-			wantErr: true,
+			want:    inf.NewDec(0, 0),
+			wantErr: false,
 		},
 		{
 			name: "scale up",
@@ -914,6 +1107,62 @@ func Test_calculateDesiredReplicas(t *testing.T) {
 			want:    inf.NewDec(5, 0),
 			wantErr: false,
 		},
+		{
+			name: "scale up ahead of forecast peak",
+			state: &strategy.State{
+				Replicas: 2,
+				PodMetrics: strategy.PodMetrics{
+					ResourceUsage: strategy.ResourcesList{
+						CPU:    inf.NewDec(50, 0),
+						Memory: inf.NewDec(50, 0),
+					},
+					ForecastUsage: &strategy.ResourcesList{
+						CPU:    inf.NewDec(100, 0),
+						Memory: inf.NewDec(25, 0),
+					},
+					Resources: strategy.Resources{
+						Requests: strategy.ResourcesList{
+							CPU:    inf.NewDec(100, 0),
+							Memory: inf.NewDec(100, 0),
+						},
+					},
+				},
+				TargetUtilization: strategy.ResourcesList{
+					CPU:    inf.NewDec(50, 2),
+					Memory: inf.NewDec(50, 2),
+				},
+			},
+			want:    inf.NewDec(4, 0),
+			wantErr: false,
+		},
+		{
+			name: "keep replicas despite forecast drop",
+			state: &strategy.State{
+				Replicas: 2,
+				PodMetrics: strategy.PodMetrics{
+					ResourceUsage: strategy.ResourcesList{
+						CPU:    inf.NewDec(50, 0),
+						Memory: inf.NewDec(50, 0),
+					},
+					ForecastUsage: &strategy.ResourcesList{
+						CPU:    inf.NewDec(10, 0),
+						Memory: inf.NewDec(10, 0),
+					},
+					Resources: strategy.Resources{
+						Requests: strategy.ResourcesList{
+							CPU:    inf.NewDec(100, 0),
+							Memory: inf.NewDec(100, 0),
+						},
+					},
+				},
+				TargetUtilization: strategy.ResourcesList{
+					CPU:    inf.NewDec(50, 2),
+					Memory: inf.NewDec(50, 2),
+				},
+			},
+			want:    inf.NewDec(2, 0),
+			wantErr: false,
+		},
 		{
 			name: "scale up based on cpu",
 			state: &strategy.State{
@@ -1150,3 +1399,431 @@ func decComparer(a, b *inf.Dec) bool {
 
 	return a.Cmp(b) == 0
 }
+
+func TestExpectedUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics strategy.PodMetrics
+		want    strategy.ResourcesList
+	}{
+		{
+			name: "no forecast",
+			metrics: strategy.PodMetrics{
+				ResourceUsage: strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(2, 0)},
+			},
+			want: strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(2, 0)},
+		},
+		{
+			name: "maximum of current and forecast usage",
+			metrics: strategy.PodMetrics{
+				ResourceUsage: strategy.ResourcesList{CPU: inf.NewDec(1, 0), Memory: inf.NewDec(2, 0)},
+				ForecastUsage: &strategy.ResourcesList{CPU: inf.NewDec(3, 0), Memory: inf.NewDec(1, 0)},
+			},
+			want: strategy.ResourcesList{CPU: inf.NewDec(3, 0), Memory: inf.NewDec(2, 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExpectedUsage(tt.metrics)
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("ExpectedUsage() %v", diff)
+			}
+		})
+	}
+}
+
+func TestVertical_recommendations(t *testing.T) {
+	containerResources := strategy.ContainerResources{
+		"a": {
+			Requests: strategy.ResourcesList{CPU: inf.NewDec(100, 0), Memory: inf.NewDec(200, 0)},
+			Limits:   strategy.ResourcesList{CPU: inf.NewDec(200, 0), Memory: inf.NewDec(400, 0)},
+		},
+		"b": {
+			Requests: strategy.ResourcesList{CPU: inf.NewDec(50, 0), Memory: inf.NewDec(100, 0)},
+			Limits:   strategy.ResourcesList{CPU: inf.NewDec(100, 0), Memory: inf.NewDec(200, 0)},
+		},
+	}
+	recommendations := map[string]strategy.UsageRecommendation{
+		"a": {
+			Target:     strategy.ResourcesList{CPU: inf.NewDec(100, 0), Memory: inf.NewDec(100, 0)},
+			LowerBound: strategy.ResourcesList{CPU: inf.NewDec(90, 0), Memory: inf.NewDec(90, 0)},
+			UpperBound: strategy.ResourcesList{CPU: inf.NewDec(120, 0), Memory: inf.NewDec(120, 0)},
+		},
+		"b": {
+			Target:     strategy.ResourcesList{CPU: inf.NewDec(25, 0), Memory: inf.NewDec(100, 0)},
+			LowerBound: strategy.ResourcesList{CPU: inf.NewDec(20, 0), Memory: inf.NewDec(90, 0)},
+			UpperBound: strategy.ResourcesList{CPU: inf.NewDec(30, 0), Memory: inf.NewDec(110, 0)},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		state *strategy.State
+		want  *strategy.ScalingDecision
+	}{
+		{
+			name: "keep requests within bounds and size the others for the target",
+			state: &strategy.State{
+				Replicas:           2,
+				ContainerResources: containerResources,
+				Constraints: strategy.Constraints{
+					MinResources: strategy.ResourcesList{CPU: inf.NewDec(50, 0), Memory: inf.NewDec(50, 0)},
+					MaxResources: strategy.ResourcesList{CPU: inf.NewDec(1000, 0), Memory: inf.NewDec(1000, 0)},
+				},
+				TargetUtilization:    strategy.ResourcesList{CPU: inf.NewDec(50, 2), Memory: inf.NewDec(50, 2)},
+				UsageRecommendations: recommendations,
+			},
+			want: &strategy.ScalingDecision{
+				Replicas: 2,
+				ContainerResources: strategy.ContainerResources{
+					"a": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(200, 0), Memory: inf.NewDec(200, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(400, 0), Memory: inf.NewDec(400, 0)},
+					},
+					"b": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(50, 0), Memory: inf.NewDec(200, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(100, 0), Memory: inf.NewDec(400, 0)},
+					},
+				},
+			},
+		},
+		{
+			name: "scale pod resources down to max",
+			state: &strategy.State{
+				Replicas:           2,
+				ContainerResources: containerResources,
+				Constraints: strategy.Constraints{
+					MinResources: strategy.ResourcesList{CPU: inf.NewDec(50, 0), Memory: inf.NewDec(50, 0)},
+					MaxResources: strategy.ResourcesList{CPU: inf.NewDec(200, 0), Memory: inf.NewDec(1000, 0)},
+				},
+				TargetUtilization:    strategy.ResourcesList{CPU: inf.NewDec(50, 2), Memory: inf.NewDec(50, 2)},
+				UsageRecommendations: recommendations,
+			},
+			want: &strategy.ScalingDecision{
+				Replicas: 2,
+				ContainerResources: strategy.ContainerResources{
+					"a": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(160, 0), Memory: inf.NewDec(200, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(160, 0), Memory: inf.NewDec(400, 0)},
+					},
+					"b": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(40, 0), Memory: inf.NewDec(200, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(40, 0), Memory: inf.NewDec(400, 0)},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Vertical(tt.state, inf.NewDec(2, 0), inf.NewDec(2, 0))
+			if err != nil {
+				t.Errorf("Vertical() error = %v", err)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("Vertical() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_relievePressure(t *testing.T) {
+	current := strategy.ContainerResources{
+		"a": {
+			Requests: strategy.ResourcesList{CPU: inf.NewDec(100, 3), Memory: inf.NewDec(100, 0)},
+			Limits:   strategy.ResourcesList{CPU: inf.NewDec(200, 3), Memory: inf.NewDec(200, 0)},
+		},
+	}
+	decision := &strategy.ScalingDecision{
+		Replicas: 2,
+		ContainerResources: strategy.ContainerResources{
+			"a": {
+				Requests: strategy.ResourcesList{CPU: inf.NewDec(100, 3), Memory: inf.NewDec(90, 0)},
+				Limits:   strategy.ResourcesList{CPU: inf.NewDec(200, 3), Memory: inf.NewDec(180, 0)},
+			},
+		},
+	}
+	policy := strategy.VerticalPolicy{
+		OOMBumpFactor:        inf.NewDec(15, 1),
+		ThrottlingThreshold:  inf.NewDec(1, 1),
+		ThrottlingBumpFactor: inf.NewDec(12, 1),
+	}
+
+	tests := []struct {
+		name  string
+		state *strategy.State
+		want  *strategy.ScalingDecision
+	}{
+		{
+			name: "no pressure",
+			state: &strategy.State{
+				ContainerResources: current,
+				VerticalPolicy:     policy,
+			},
+			want: decision,
+		},
+		{
+			name: "bump memory after out-of-memory kills",
+			state: &strategy.State{
+				ContainerResources: current,
+				VerticalPolicy:     policy,
+				Pressure:           map[string]strategy.Pressure{"a": {OOMKills: 1}},
+			},
+			want: &strategy.ScalingDecision{
+				Replicas: 2,
+				ContainerResources: strategy.ContainerResources{
+					"a": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(100, 3), Memory: inf.NewDec(150, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(200, 3), Memory: inf.NewDec(300, 0)},
+					},
+				},
+			},
+		},
+		{
+			name: "bump cpu above throttling threshold but limit to max",
+			state: &strategy.State{
+				ContainerResources: current,
+				VerticalPolicy:     policy,
+				Pressure:           map[string]strategy.Pressure{"a": {ThrottlingRatio: inf.NewDec(3, 1)}},
+				Constraints: strategy.Constraints{
+					MaxResources: strategy.ResourcesList{CPU: inf.NewDec(220, 3)},
+				},
+			},
+			want: &strategy.ScalingDecision{
+				Replicas: 2,
+				ContainerResources: strategy.ContainerResources{
+					"a": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(120, 3), Memory: inf.NewDec(90, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(220, 3), Memory: inf.NewDec(180, 0)},
+					},
+				},
+			},
+		},
+		{
+			name: "ignore throttling below threshold",
+			state: &strategy.State{
+				ContainerResources: current,
+				VerticalPolicy:     policy,
+				Pressure:           map[string]strategy.Pressure{"a": {ThrottlingRatio: inf.NewDec(5, 2)}},
+			},
+			want: decision,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := relievePressure(decision, tt.state)
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("relievePressure() %v", diff)
+			}
+		})
+	}
+}
+
+func Test_round(t *testing.T) {
+	tests := []struct {
+		name               string
+		value              *inf.Dec
+		rounding           strategy.Rounding
+		defaultGranularity *inf.Dec
+		want               *inf.Dec
+	}{
+		{
+			name:               "round to nearest default step",
+			value:              inf.NewDec(1234567, 7),
+			defaultGranularity: defaultCpuGranularity,
+			want:               inf.NewDec(123, 3),
+		},
+		{
+			name:               "round up to granularity",
+			value:              inf.NewDec(104857601, 0),
+			rounding:           strategy.Rounding{Granularity: inf.NewDec(16777216, 0), Direction: strategy.RoundingDirectionUp},
+			defaultGranularity: defaultMemoryGranularity,
+			want:               inf.NewDec(117440512, 0),
+		},
+		{
+			name:               "round down to granularity",
+			value:              inf.NewDec(129, 3),
+			rounding:           strategy.Rounding{Granularity: inf.NewDec(10, 3), Direction: strategy.RoundingDirectionDown},
+			defaultGranularity: defaultCpuGranularity,
+			want:               inf.NewDec(120, 3),
+		},
+		{
+			name:               "never round down to zero",
+			value:              inf.NewDec(5, 3),
+			rounding:           strategy.Rounding{Granularity: inf.NewDec(10, 3), Direction: strategy.RoundingDirectionDown},
+			defaultGranularity: defaultCpuGranularity,
+			want:               inf.NewDec(10, 3),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := round(tt.value, tt.rounding, tt.defaultGranularity)
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("round() %v", diff)
+			}
+		})
+	}
+}
+
+func TestFitToNode(t *testing.T) {
+	decision := func(replicas int32, cpu, memory int64) *strategy.ScalingDecision {
+		return &strategy.ScalingDecision{
+			Replicas: replicas,
+			ContainerResources: strategy.ContainerResources{
+				"a": {
+					Requests: strategy.ResourcesList{CPU: inf.NewDec(cpu, 0), Memory: inf.NewDec(memory, 0)},
+					Limits:   strategy.ResourcesList{CPU: inf.NewDec(2*cpu, 0), Memory: inf.NewDec(2*memory, 0)},
+				},
+			},
+		}
+	}
+	nodes := []strategy.ResourcesList{
+		{CPU: inf.NewDec(2, 0), Memory: inf.NewDec(8, 0)},
+		{CPU: inf.NewDec(4, 0), Memory: inf.NewDec(4, 0)},
+	}
+
+	tests := []struct {
+		name        string
+		decision    *strategy.ScalingDecision
+		state       *strategy.State
+		want        *strategy.ScalingDecision
+		wantChanged bool
+	}{
+		{
+			name:     "unknown node capacities",
+			decision: decision(2, 8, 8),
+			state:    &strategy.State{},
+			want:     decision(2, 8, 8),
+		},
+		{
+			name:     "fits on a node",
+			decision: decision(2, 4, 4),
+			state:    &strategy.State{Constraints: strategy.Constraints{NodeCapacities: nodes}},
+			want:     decision(2, 4, 4),
+		},
+		{
+			name:     "scale down to the best fitting node and compensate horizontally",
+			decision: decision(2, 8, 4),
+			state:    &strategy.State{Constraints: strategy.Constraints{NodeCapacities: nodes}},
+			want: &strategy.ScalingDecision{
+				Replicas: 4,
+				ContainerResources: strategy.ContainerResources{
+					"a": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(4, 0), Memory: inf.NewDec(4, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(8, 0), Memory: inf.NewDec(8, 0)},
+					},
+				},
+			},
+			wantChanged: true,
+		},
+		{
+			name:     "respect max replicas",
+			decision: decision(2, 8, 4),
+			state:    &strategy.State{Constraints: strategy.Constraints{NodeCapacities: nodes, MaxReplicas: 3}},
+			want: &strategy.ScalingDecision{
+				Replicas: 3,
+				ContainerResources: strategy.ContainerResources{
+					"a": {
+						Requests: strategy.ResourcesList{CPU: inf.NewDec(4, 0), Memory: inf.NewDec(4, 0)},
+						Limits:   strategy.ResourcesList{CPU: inf.NewDec(8, 0), Memory: inf.NewDec(8, 0)},
+					},
+				},
+			},
+			wantChanged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotChanged := FitToNode(tt.decision, tt.state)
+			if gotChanged != tt.wantChanged {
+				t.Errorf("FitToNode() = %v, want %v", gotChanged, tt.wantChanged)
+			}
+			if diff := cmp.Diff(tt.want, tt.decision, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("FitToNode() %v", diff)
+			}
+		})
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	state := func(replicas int32, targetUtilization *inf.Dec) *strategy.State {
+		return &strategy.State{
+			Replicas: replicas,
+			PodMetrics: strategy.PodMetrics{
+				ResourceUsage: strategy.ResourcesList{
+					CPU:    inf.NewDec(100, 0),
+					Memory: inf.NewDec(100, 0),
+				},
+			},
+			TargetUtilization: strategy.ResourcesList{
+				CPU:    targetUtilization,
+				Memory: inf.NewDec(5, 1),
+			},
+		}
+	}
+	decision := func(replicas int32) *strategy.ScalingDecision {
+		return &strategy.ScalingDecision{
+			Replicas: replicas,
+			ContainerResources: strategy.ContainerResources{
+				"container": {
+					Requests: strategy.ResourcesList{
+						CPU:    inf.NewDec(200, 0),
+						Memory: inf.NewDec(200, 0),
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		state    *strategy.State
+		decision *strategy.ScalingDecision
+		want     *inf.Dec
+		wantErr  bool
+	}{
+		{
+			name:     "usage at the target utilization",
+			state:    state(2, inf.NewDec(5, 1)),
+			decision: decision(2),
+			want:     inf.NewDec(800, 0),
+		},
+		{
+			name:     "penalize underprovisioning of fewer replicas",
+			state:    state(2, inf.NewDec(5, 1)),
+			decision: decision(1),
+			want:     inf.NewDec(4400, 0),
+		},
+		{
+			name:     "scale to zero",
+			state:    state(2, inf.NewDec(5, 1)),
+			decision: decision(0),
+			want:     inf.NewDec(0, 0),
+		},
+		{
+			name:     "scale from zero",
+			state:    state(0, inf.NewDec(5, 1)),
+			decision: decision(1),
+			want:     inf.NewDec(400, 0),
+		},
+		{
+			name:     "zero target utilization",
+			state:    state(2, inf.NewDec(0, 0)),
+			decision: decision(2),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EstimateCost(tt.state, tt.decision, inf.NewDec(1, 0), inf.NewDec(1, 0), inf.NewDec(10, 0))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EstimateCost() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(decComparer)); diff != "" {
+				t.Errorf("EstimateCost() %v", diff)
+			}
+		})
+	}
+}
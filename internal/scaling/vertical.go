@@ -7,20 +7,53 @@ import (
 	"gopkg.in/inf.v0"
 )
 
+// overprovisioningFactor is the default headroom by which requests sized from the average usage are raised
 var overprovisioningFactor = inf.NewDec(110, 2)
 
-// Recommends new resource requests and limits keeping ratios between both and each container's share of the pod's resources
+// Recommends new resource requests and limits keeping ratios between both and each container's share of the pod's resources,
+// if there are usage recommendations for all containers they are sized individually from those instead,
+// the resources are rounded according to the vertical policy
 func Vertical(s *strategy.State, cpuLimitsToRequestsRatio, memoryLimitsToRequestsRatio *inf.Dec) (*strategy.ScalingDecision, error) {
 	if cpuLimitsToRequestsRatio == nil || memoryLimitsToRequestsRatio == nil {
 		return nil, fmt.Errorf("no limits to requests ratios provided")
 	}
 
+	var decision *strategy.ScalingDecision
+	var err error
+
+	if hasRecommendations(s) {
+		decision, err = verticalFromRecommendations(s, cpuLimitsToRequestsRatio, memoryLimitsToRequestsRatio)
+	} else {
+		decision, err = verticalFromAverageUsage(s, cpuLimitsToRequestsRatio, memoryLimitsToRequestsRatio)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	decision = relievePressure(decision, s)
+
+	containerResources := make(strategy.ContainerResources)
+	for name, resources := range decision.ContainerResources {
+		containerResources[name] = RoundResources(resources, s.VerticalPolicy.Rounding)
+	}
+	decision.ContainerResources = containerResources
+
+	return decision, nil
+}
+
+// verticalFromAverageUsage sizes the pod's resources from its average usage and distributes them by each container's current share
+func verticalFromAverageUsage(s *strategy.State, cpuLimitsToRequestsRatio, memoryLimitsToRequestsRatio *inf.Dec) (*strategy.ScalingDecision, error) {
 	containerResources := make(strategy.ContainerResources)
 	currentReplicas := inf.NewDec(int64(s.Replicas), 0)
 	zero := inf.NewDec(0, 0)
 
+	// without any pods there is no usage to base new resources on, so the current resources are kept
 	if currentReplicas.Cmp(zero) == 0 {
-		return nil, fmt.Errorf("unable to calculate new pod resources, current number of replicas is zero")
+		return &strategy.ScalingDecision{
+			Replicas:           s.Replicas,
+			ContainerResources: s.ContainerResources,
+		}, nil
 	}
 
 	podCpuRequests := s.PodMetrics.Requests.CPU
@@ -35,7 +68,7 @@ func Vertical(s *strategy.State, cpuLimitsToRequestsRatio, memoryLimitsToRequest
 	}
 
 	desiredPodCpuRequests := new(inf.Dec).Mul(podCpuRequests, cpuCurrentToTargetRatio)
-	desiredPodCpuRequests.Mul(desiredPodCpuRequests, overprovisioningFactor)
+	desiredPodCpuRequests.Mul(desiredPodCpuRequests, headroom(s.VerticalPolicy.Headroom.CPU))
 	minCpu := s.Constraints.MinResources.CPU
 	maxCpu := s.Constraints.MaxResources.CPU
 	desiredPodCpuRequests = limitValue(desiredPodCpuRequests, minCpu, maxCpu)
@@ -49,7 +82,7 @@ func Vertical(s *strategy.State, cpuLimitsToRequestsRatio, memoryLimitsToRequest
 	}
 
 	desiredPodMemoryRequests := new(inf.Dec).Mul(podMemoryRequests, memoryCurrentToTargetRatio)
-	desiredPodMemoryRequests.Mul(desiredPodMemoryRequests, overprovisioningFactor)
+	desiredPodMemoryRequests.Mul(desiredPodMemoryRequests, headroom(s.VerticalPolicy.Headroom.Memory))
 	minMemory := s.Constraints.MinResources.Memory
 	maxMemory := s.Constraints.MaxResources.Memory
 	desiredPodMemoryRequests = limitValue(desiredPodMemoryRequests, minMemory, maxMemory)
@@ -73,11 +106,6 @@ func Vertical(s *strategy.State, cpuLimitsToRequestsRatio, memoryLimitsToRequest
 		desiredMemoryRequests := new(inf.Dec).Mul(memoryRequests, new(inf.Dec).QuoRound(desiredPodMemoryRequests, podMemoryRequests, 8, memoryRounder))
 		desiredMemoryLimits := new(inf.Dec).Mul(memoryLimits, new(inf.Dec).QuoRound(desiredPodMemoryLimits, podMemoryLimits, 8, memoryRounder))
 
-		desiredCpuRequests.Round(desiredCpuRequests, 3, cpuRounder)
-		desiredCpuLimits.Round(desiredCpuLimits, 3, cpuRounder)
-		desiredMemoryRequests.Round(desiredMemoryRequests, 0, memoryRounder)
-		desiredMemoryLimits.Round(desiredMemoryLimits, 0, memoryRounder)
-
 		containerResources[name] = strategy.Resources{
 			Requests: strategy.ResourcesList{
 				CPU:    desiredCpuRequests,
@@ -95,3 +123,208 @@ func Vertical(s *strategy.State, cpuLimitsToRequestsRatio, memoryLimitsToRequest
 		ContainerResources: containerResources,
 	}, nil
 }
+
+// headroom returns the configured headroom or the default if none is set
+func headroom(configured *inf.Dec) *inf.Dec {
+	if configured == nil {
+		return overprovisioningFactor
+	}
+
+	return configured
+}
+
+// hasRecommendations reports whether there is a usage recommendation for every container
+func hasRecommendations(s *strategy.State) bool {
+	if len(s.UsageRecommendations) == 0 {
+		return false
+	}
+
+	for name := range s.ContainerResources {
+		if _, ok := s.UsageRecommendations[name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// verticalFromRecommendations sizes each container's requests so that its recommended usage meets the target utilization,
+// requests within the recommendation's bounds are kept to avoid needless restarts,
+// the pod's total resources are scaled proportionally to stay within the constraints
+func verticalFromRecommendations(s *strategy.State, cpuLimitsToRequestsRatio, memoryLimitsToRequestsRatio *inf.Dec) (*strategy.ScalingDecision, error) {
+	zero := inf.NewDec(0, 0)
+	if s.TargetUtilization.CPU.Cmp(zero) == 0 || s.TargetUtilization.Memory.Cmp(zero) == 0 {
+		return nil, fmt.Errorf("target utilization cannot be zero")
+	}
+
+	cpuRequests := make(map[string]*inf.Dec)
+	memoryRequests := make(map[string]*inf.Dec)
+	podCpuRequests := inf.NewDec(0, 0)
+	podMemoryRequests := inf.NewDec(0, 0)
+
+	for name, resources := range s.ContainerResources {
+		recommendation := s.UsageRecommendations[name]
+
+		cpuRequests[name] = recommendedRequests(resources.Requests.CPU, recommendation.Target.CPU, recommendation.LowerBound.CPU, recommendation.UpperBound.CPU, s.TargetUtilization.CPU)
+		memoryRequests[name] = recommendedRequests(resources.Requests.Memory, recommendation.Target.Memory, recommendation.LowerBound.Memory, recommendation.UpperBound.Memory, s.TargetUtilization.Memory)
+
+		podCpuRequests.Add(podCpuRequests, cpuRequests[name])
+		podMemoryRequests.Add(podMemoryRequests, memoryRequests[name])
+	}
+
+	cpuRequestsFactor := limitFactor(podCpuRequests, s.Constraints.MinResources.CPU, s.Constraints.MaxResources.CPU)
+	memoryRequestsFactor := limitFactor(podMemoryRequests, s.Constraints.MinResources.Memory, s.Constraints.MaxResources.Memory)
+
+	cpuLimits := make(map[string]*inf.Dec)
+	memoryLimits := make(map[string]*inf.Dec)
+	podCpuLimits := inf.NewDec(0, 0)
+	podMemoryLimits := inf.NewDec(0, 0)
+
+	for name := range s.ContainerResources {
+		cpuRequests[name].Mul(cpuRequests[name], cpuRequestsFactor)
+		memoryRequests[name].Mul(memoryRequests[name], memoryRequestsFactor)
+
+		cpuLimits[name] = new(inf.Dec).Mul(cpuRequests[name], cpuLimitsToRequestsRatio)
+		memoryLimits[name] = new(inf.Dec).Mul(memoryRequests[name], memoryLimitsToRequestsRatio)
+
+		podCpuLimits.Add(podCpuLimits, cpuLimits[name])
+		podMemoryLimits.Add(podMemoryLimits, memoryLimits[name])
+	}
+
+	cpuLimitsFactor := limitFactor(podCpuLimits, zero, s.Constraints.MaxResources.CPU)
+	memoryLimitsFactor := limitFactor(podMemoryLimits, zero, s.Constraints.MaxResources.Memory)
+
+	containerResources := make(strategy.ContainerResources)
+
+	for name := range s.ContainerResources {
+		// limits are reduced to fit the maximum resources, but never below the requests
+		cpuLimits[name].Mul(cpuLimits[name], cpuLimitsFactor)
+		if cpuLimits[name].Cmp(cpuRequests[name]) < 0 {
+			cpuLimits[name] = cpuRequests[name]
+		}
+
+		memoryLimits[name].Mul(memoryLimits[name], memoryLimitsFactor)
+		if memoryLimits[name].Cmp(memoryRequests[name]) < 0 {
+			memoryLimits[name] = memoryRequests[name]
+		}
+
+		containerResources[name] = strategy.Resources{
+			Requests: strategy.ResourcesList{
+				CPU:    cpuRequests[name],
+				Memory: memoryRequests[name],
+			},
+			Limits: strategy.ResourcesList{
+				CPU:    cpuLimits[name],
+				Memory: memoryLimits[name],
+			},
+		}
+	}
+
+	return &strategy.ScalingDecision{
+		Replicas:           s.Replicas,
+		ContainerResources: containerResources,
+	}, nil
+}
+
+// recommendedRequests keeps the current requests if they are within the bounds scaled by the target utilization,
+// otherwise it returns the requests at which the target usage meets the target utilization
+func recommendedRequests(current, target, lowerBound, upperBound, targetUtilization *inf.Dec) *inf.Dec {
+	lower := new(inf.Dec).QuoRound(lowerBound, targetUtilization, 8, inf.RoundHalfUp)
+	upper := new(inf.Dec).QuoRound(upperBound, targetUtilization, 8, inf.RoundHalfUp)
+
+	if current.Cmp(lower) >= 0 && current.Cmp(upper) <= 0 {
+		return new(inf.Dec).Set(current)
+	}
+
+	return new(inf.Dec).QuoRound(target, targetUtilization, 8, inf.RoundHalfUp)
+}
+
+// limitFactor returns the factor which brings `value` into the range [min, max]
+func limitFactor(value, min, max *inf.Dec) *inf.Dec {
+	if value.Cmp(inf.NewDec(0, 0)) == 0 {
+		return inf.NewDec(1, 0)
+	}
+
+	limited := limitValue(value, min, max)
+
+	return new(inf.Dec).QuoRound(limited, value, 8, inf.RoundHalfUp)
+}
+
+// relievePressure raises the memory of containers which were killed for running out of memory and the cpu of throttled containers
+// to at least their current resources times the bump factor, limited to the maximum resources
+func relievePressure(decision *strategy.ScalingDecision, s *strategy.State) *strategy.ScalingDecision {
+	if len(s.Pressure) == 0 {
+		return decision
+	}
+
+	policy := s.VerticalPolicy
+	containerResources := make(strategy.ContainerResources)
+
+	for name, resources := range decision.ContainerResources {
+		current, ok := s.ContainerResources[name]
+		pressure, underPressure := s.Pressure[name]
+
+		if !ok || !underPressure {
+			containerResources[name] = resources
+			continue
+		}
+
+		if pressure.OOMKills > 0 && policy.OOMBumpFactor != nil {
+			resources.Requests.Memory, resources.Limits.Memory = bumpResources(
+				resources.Requests.Memory, resources.Limits.Memory,
+				current.Requests.Memory, current.Limits.Memory,
+				policy.OOMBumpFactor, s.MaxResources.Memory, 0,
+			)
+		}
+
+		throttled := pressure.ThrottlingRatio != nil && policy.ThrottlingThreshold != nil && pressure.ThrottlingRatio.Cmp(policy.ThrottlingThreshold) > 0
+		if throttled && policy.ThrottlingBumpFactor != nil {
+			resources.Requests.CPU, resources.Limits.CPU = bumpResources(
+				resources.Requests.CPU, resources.Limits.CPU,
+				current.Requests.CPU, current.Limits.CPU,
+				policy.ThrottlingBumpFactor, s.MaxResources.CPU, 3,
+			)
+		}
+
+		containerResources[name] = resources
+	}
+
+	return &strategy.ScalingDecision{
+		Description:        decision.Description,
+		Replicas:           decision.Replicas,
+		ContainerResources: containerResources,
+	}
+}
+
+// bumpResources returns the desired requests and limits raised to at least the current ones times the factor,
+// the bumped values are limited to `max` if set and rounded to the given scale
+func bumpResources(desiredRequests, desiredLimits, currentRequests, currentLimits, factor, max *inf.Dec, scale inf.Scale) (requests, limits *inf.Dec) {
+	requests = desiredRequests
+	limits = desiredLimits
+
+	bumpedRequests := new(inf.Dec).Mul(currentRequests, factor)
+	bumpedRequests.Round(bumpedRequests, scale, inf.RoundUp)
+	if max != nil && bumpedRequests.Cmp(max) > 0 {
+		bumpedRequests = max
+	}
+
+	if bumpedRequests.Cmp(requests) > 0 {
+		requests = bumpedRequests
+	}
+
+	bumpedLimits := new(inf.Dec).Mul(currentLimits, factor)
+	bumpedLimits.Round(bumpedLimits, scale, inf.RoundUp)
+	if max != nil && bumpedLimits.Cmp(max) > 0 {
+		bumpedLimits = max
+	}
+
+	if bumpedLimits.Cmp(limits) > 0 {
+		limits = bumpedLimits
+	}
+
+	if limits.Cmp(requests) < 0 {
+		limits = requests
+	}
+
+	return requests, limits
+}
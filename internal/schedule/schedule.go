@@ -0,0 +1,196 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression of the form `<minute> <hour> <day-of-month> <month> <day-of-week>`,
+// each field supports `*`, lists, ranges and steps, days of week range from 0 (Sunday) to 7 (Sunday)
+type Schedule struct {
+	minutes, hours, daysOfMonth, months, daysOfWeek uint64
+	// restricted days are combined with OR if both are set, like cron does
+	daysOfMonthRestricted, daysOfWeekRestricted bool
+}
+
+type bounds struct {
+	min, max int
+}
+
+var (
+	minuteBounds     = bounds{0, 59}
+	hourBounds       = bounds{0, 23}
+	dayOfMonthBounds = bounds{1, 31}
+	monthBounds      = bounds{1, 12}
+	dayOfWeekBounds  = bounds{0, 7}
+)
+
+// Parse parses a standard five-field cron expression
+func Parse(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields in cron expression %q, got %d", expr, len(fields))
+	}
+
+	minutes, err := parseField(fields[0], minuteBounds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minute field, %w", err)
+	}
+
+	hours, err := parseField(fields[1], hourBounds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hour field, %w", err)
+	}
+
+	daysOfMonth, err := parseField(fields[2], dayOfMonthBounds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day of month field, %w", err)
+	}
+
+	months, err := parseField(fields[3], monthBounds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month field, %w", err)
+	}
+
+	daysOfWeek, err := parseField(fields[4], dayOfWeekBounds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day of week field, %w", err)
+	}
+
+	// 7 is an alias for Sunday
+	if daysOfWeek&(1<<7) != 0 {
+		daysOfWeek |= 1
+	}
+
+	return &Schedule{
+		minutes:               minutes,
+		hours:                 hours,
+		daysOfMonth:           daysOfMonth,
+		months:                months,
+		daysOfWeek:            daysOfWeek,
+		daysOfMonthRestricted: fields[2] != "*",
+		daysOfWeekRestricted:  fields[4] != "*",
+	}, nil
+}
+
+// Matches reports whether the minute containing `t` is matched by the schedule
+func (s *Schedule) Matches(t time.Time) bool {
+	return s.matchesDay(t) && has(s.hours, t.Hour()) && has(s.minutes, t.Minute())
+}
+
+// ActiveAt reports whether `t` lies within a window of the given duration starting at any time matched by the schedule,
+// the schedule is evaluated in the location of `t`
+func (s *Schedule) ActiveAt(t time.Time, duration time.Duration) bool {
+	// days and hours which cannot match are skipped as a whole, so long windows only cost a few steps per day
+	for candidate := t.Truncate(time.Minute); t.Sub(candidate) < duration; {
+		switch {
+		case !s.matchesDay(candidate):
+			candidate = time.Date(candidate.Year(), candidate.Month(), candidate.Day(), 0, 0, 0, 0, candidate.Location()).Add(-time.Minute)
+		case !has(s.hours, candidate.Hour()):
+			candidate = time.Date(candidate.Year(), candidate.Month(), candidate.Day(), candidate.Hour(), 0, 0, 0, candidate.Location()).Add(-time.Minute)
+		case has(s.minutes, candidate.Minute()):
+			return true
+		default:
+			candidate = candidate.Add(-time.Minute)
+		}
+	}
+
+	return false
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	if !has(s.months, int(t.Month())) {
+		return false
+	}
+
+	dayOfMonth := has(s.daysOfMonth, t.Day())
+	dayOfWeek := has(s.daysOfWeek, int(t.Weekday()))
+
+	if s.daysOfMonthRestricted && s.daysOfWeekRestricted {
+		return dayOfMonth || dayOfWeek
+	}
+
+	return dayOfMonth && dayOfWeek
+}
+
+func parseField(field string, b bounds) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(field, ",") {
+		values, err := parseRange(part, b)
+		if err != nil {
+			return 0, err
+		}
+
+		set |= values
+	}
+
+	return set, nil
+}
+
+// parseRange parses one element of a list, which is either `*`, a value or a range, optionally followed by a step
+func parseRange(part string, b bounds) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepPart)
+		if err != nil || step < 1 {
+			return 0, fmt.Errorf("invalid step %q", stepPart)
+		}
+	}
+
+	start, end := b.min, b.max
+
+	if rangePart != "*" {
+		startPart, endPart, isRange := strings.Cut(rangePart, "-")
+
+		var err error
+		start, err = parseValue(startPart, b)
+		if err != nil {
+			return 0, err
+		}
+
+		end = start
+		switch {
+		case isRange:
+			end, err = parseValue(endPart, b)
+			if err != nil {
+				return 0, err
+			}
+		case hasStep:
+			end = b.max
+		}
+
+		if start > end {
+			return 0, fmt.Errorf("invalid range %q", rangePart)
+		}
+	}
+
+	var set uint64
+	for value := start; value <= end; value += step {
+		set |= 1 << value
+	}
+
+	return set, nil
+}
+
+func parseValue(value string, b bounds) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, b.min, b.max)
+	}
+
+	return v, nil
+}
+
+func has(set uint64, value int) bool {
+	return set&(1<<value) != 0
+}
@@ -0,0 +1,214 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{
+			name: "every minute",
+			expr: "* * * * *",
+		},
+		{
+			name: "lists, ranges and steps",
+			expr: "0,30 8-20/2 1-15 */3 1-5",
+		},
+		{
+			name: "sunday as seven",
+			expr: "0 0 * * 7",
+		},
+		{
+			name:    "too few fields",
+			expr:    "0 8 * *",
+			wantErr: true,
+		},
+		{
+			name:    "value out of range",
+			expr:    "60 * * * *",
+			wantErr: true,
+		},
+		{
+			name:    "inverted range",
+			expr:    "* 20-8 * * *",
+			wantErr: true,
+		},
+		{
+			name:    "invalid step",
+			expr:    "*/0 * * * *",
+			wantErr: true,
+		},
+		{
+			name:    "names are not supported",
+			expr:    "* * * * MON",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchedule_Matches(t *testing.T) {
+	// 2024-01-01 is a Monday
+	monday := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{
+			name: "weekday morning",
+			expr: "0 8 * * 1-5",
+			t:    monday,
+			want: true,
+		},
+		{
+			name: "weekend morning",
+			expr: "0 8 * * 1-5",
+			t:    monday.AddDate(0, 0, 5),
+			want: false,
+		},
+		{
+			name: "sunday as seven",
+			expr: "0 8 * * 7",
+			t:    monday.AddDate(0, 0, 6),
+			want: true,
+		},
+		{
+			name: "step",
+			expr: "*/15 * * * *",
+			t:    monday.Add(45 * time.Minute),
+			want: true,
+		},
+		{
+			name: "wrong minute",
+			expr: "*/15 * * * *",
+			t:    monday.Add(50 * time.Minute),
+			want: false,
+		},
+		{
+			name: "day of month or day of week",
+			expr: "0 8 15 * 0",
+			t:    monday.AddDate(0, 0, 6),
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+
+			if got := s.Matches(tt.t); got != tt.want {
+				t.Errorf("Schedule.Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_ActiveAt(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone data available, %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		duration time.Duration
+		t        time.Time
+		want     bool
+	}{
+		{
+			name:     "at start of window",
+			expr:     "0 8 * * 1-5",
+			duration: 12 * time.Hour,
+			t:        time.Date(2024, 1, 1, 8, 0, 0, 0, berlin),
+			want:     true,
+		},
+		{
+			name:     "within window",
+			expr:     "0 8 * * 1-5",
+			duration: 12 * time.Hour,
+			t:        time.Date(2024, 1, 1, 19, 59, 30, 0, berlin),
+			want:     true,
+		},
+		{
+			name:     "after window",
+			expr:     "0 8 * * 1-5",
+			duration: 12 * time.Hour,
+			t:        time.Date(2024, 1, 1, 20, 0, 0, 0, berlin),
+			want:     false,
+		},
+		{
+			name:     "before window",
+			expr:     "0 8 * * 1-5",
+			duration: 12 * time.Hour,
+			t:        time.Date(2024, 1, 1, 7, 59, 0, 0, berlin),
+			want:     false,
+		},
+		{
+			name:     "window evaluated in location of time",
+			expr:     "0 8 * * 1-5",
+			duration: 12 * time.Hour,
+			t:        time.Date(2024, 1, 1, 7, 30, 0, 0, time.UTC).In(berlin),
+			want:     true,
+		},
+		{
+			name:     "window spanning midnight",
+			expr:     "0 22 * * *",
+			duration: 4 * time.Hour,
+			t:        time.Date(2024, 1, 2, 1, 0, 0, 0, berlin),
+			want:     true,
+		},
+		{
+			name:     "within window spanning several days",
+			expr:     "30 18 * * 5",
+			duration: 60 * time.Hour,
+			t:        time.Date(2024, 1, 8, 6, 29, 0, 0, berlin),
+			want:     true,
+		},
+		{
+			name:     "after window spanning several days",
+			expr:     "30 18 * * 5",
+			duration: 60 * time.Hour,
+			t:        time.Date(2024, 1, 8, 6, 30, 0, 0, berlin),
+			want:     false,
+		},
+		{
+			name:     "within window spanning a year",
+			expr:     "0 0 1 1 *",
+			duration: 366 * 24 * time.Hour,
+			t:        time.Date(2024, 12, 31, 23, 59, 0, 0, berlin),
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+
+			if got := s.ActiveAt(tt.t, tt.duration); got != tt.want {
+				t.Errorf("Schedule.ActiveAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
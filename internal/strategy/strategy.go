@@ -1,6 +1,10 @@
 package strategy
 
-import "gopkg.in/inf.v0"
+import (
+	"time"
+
+	"gopkg.in/inf.v0"
+)
 
 type ScalingStrategy interface {
 	MakeDecision(state *State, learningState []byte) (*ScalingDecision, []byte, error)
@@ -13,6 +17,64 @@ type State struct {
 	Constraints
 	PodMetrics        PodMetrics
 	TargetUtilization ResourcesList
+	// Time is the time at which the state was observed
+	Time time.Time
+	// UsageRecommendations maps container names to their usage estimates, vertical scaling uses them instead of the average usage if set
+	UsageRecommendations map[string]UsageRecommendation
+	// Pressure maps container names to the signs of resource shortage observed since the last decision
+	Pressure       map[string]Pressure
+	VerticalPolicy VerticalPolicy
+	// UnschedulablePods is the number of pods which could not be scheduled since the last decision
+	UnschedulablePods int32
+}
+
+// Pressure describes signs of a container running short of resources
+type Pressure struct {
+	// OOMKills is the number of the container's out-of-memory kills
+	OOMKills int32
+	// ThrottlingRatio is the share of cpu periods in which the container was throttled, nil if unknown
+	ThrottlingRatio *inf.Dec
+}
+
+// VerticalPolicy defines how vertical scaling sizes resources and reacts to resource pressure
+type VerticalPolicy struct {
+	// Headroom is the factor by which requests sized from the average usage are raised, a default is used if unset
+	Headroom ResourcesList
+	// Rounding defines the steps to which recommended resources are rounded
+	Rounding RoundingPolicies
+	// OOMBumpFactor is the factor by which the memory of out-of-memory killed containers is raised at least
+	OOMBumpFactor *inf.Dec
+	// ThrottlingThreshold is the throttling ratio above which a container's cpu is raised
+	ThrottlingThreshold *inf.Dec
+	// ThrottlingBumpFactor is the factor by which the cpu of throttled containers is raised at least
+	ThrottlingBumpFactor *inf.Dec
+}
+
+// RoundingPolicies define the rounding of each resource
+type RoundingPolicies struct {
+	CPU    Rounding
+	Memory Rounding
+}
+
+// Rounding rounds to multiples of the granularity in the given direction
+type Rounding struct {
+	Granularity *inf.Dec
+	Direction   RoundingDirection
+}
+
+type RoundingDirection string
+
+var (
+	RoundingDirectionUp      RoundingDirection = "Up"
+	RoundingDirectionDown    RoundingDirection = "Down"
+	RoundingDirectionNearest RoundingDirection = "Nearest"
+)
+
+// UsageRecommendation is a container's recommended usage together with the bounds within which current resources are kept
+type UsageRecommendation struct {
+	Target     ResourcesList
+	LowerBound ResourcesList
+	UpperBound ResourcesList
 }
 
 // ScalingDecision represents the next desired state
@@ -25,6 +87,8 @@ type ScalingDecision struct {
 // PodMetrics stores a pod's allocated and average used resources
 type PodMetrics struct {
 	ResourceUsage ResourcesList
+	// ForecastUsage is the average usage predicted for the near future, nil if no forecast is available
+	ForecastUsage *ResourcesList
 	Resources
 }
 
@@ -51,4 +115,10 @@ type Constraints struct {
 	MaxResources                ResourcesList
 	LimitsToRequestsRatioCPU    *inf.Dec
 	LimitsToRequestsRatioMemory *inf.Dec
+	// FixedReplicas is set if the number of replicas is owned by another autoscaler
+	FixedReplicas bool
+	// FixedResources is set if the container resources are owned by another autoscaler
+	FixedResources bool
+	// NodeCapacities are the allocatable resources of the nodes the pods can be scheduled on, unknown if empty
+	NodeCapacities []ResourcesList
 }